@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"github.com/brendan-sadlier/docura/internal/logging"
+	"io"
+	"path/filepath"
+)
+
+// coverageFile is written to the output directory by --coverage.
+type coverageFile struct {
+	analyser.DocCoverage
+	Packages []analyser.DocCoverage `json:"packages"`
+}
+
+// coverageReport writes each package's documentation coverage and the total
+// to w, and saves them to doc-coverage.json in outputDir.
+func coverageReport(w io.Writer, analyserInstance *analyser.Analyser, out docWriter, projectDir string, packageName string, outputDir string) error {
+	dirs, err := packageDirs(projectDir, packageName, analyserInstance.Ignore)
+	if err != nil {
+		return err
+	}
+
+	var packages []analyser.DocCoverage
+	for _, dir := range dirs {
+		pkg, err := analyserInstance.AnalysePackage(dir)
+		if errors.Is(err, analyser.ErrTestOnlyPackage) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("analyzing package %s: %w", dir, err)
+		}
+		packages = append(packages, analyser.Coverage(pkg))
+	}
+
+	total := analyser.TotalCoverage(packages)
+	for _, c := range packages {
+		fmt.Fprintf(w, "%s: %.1f%% (%d/%d)\n", c.Package, c.Percent, c.Documented, c.Total)
+	}
+	fmt.Fprintf(w, "total: %.1f%% (%d/%d)\n", total.Percent, total.Documented, total.Total)
+
+	data, err := json.MarshalIndent(coverageFile{DocCoverage: total, Packages: packages}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding coverage: %w", err)
+	}
+
+	path := filepath.Join(outputDir, "doc-coverage.json")
+	if err := out.Write(path, string(data)+"\n"); err != nil {
+		return fmt.Errorf("writing coverage: %w", err)
+	}
+	logging.Infof("Generated coverage report: %s", path)
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCoverageReport(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"store/store.go": `package store
+
+// Put stores a value.
+func Put(v string) {}
+
+// Get fetches a value.
+func Get() string { return "" }
+
+func Delete() {}
+
+// Store holds values.
+type Store struct{}
+
+func helper() {}
+`,
+		"empty/empty.go": "package empty\n\nfunc Undocumented() {}\n",
+	})
+	out := &memoryWriter{files: make(map[string]string)}
+	var report bytes.Buffer
+
+	if err := coverageReport(&report, analyser.NewAnalyser(), out, dir, "", "docs"); err != nil {
+		t.Fatal(err)
+	}
+
+	var coverage coverageFile
+	if err := json.Unmarshal([]byte(out.files["docs/doc-coverage.json"]), &coverage); err != nil {
+		t.Fatalf("reading doc-coverage.json: %v\n%v", err, out.files)
+	}
+	if coverage.Documented != 3 || coverage.Total != 5 || coverage.Percent != 60 {
+		t.Errorf("got total %+v, want 3 of 5 documented", coverage.DocCoverage)
+	}
+	percents := make(map[string]float64)
+	for _, c := range coverage.Packages {
+		percents[filepath.Base(c.Package)] = c.Percent
+	}
+	if percents["store"] != 75 || percents["empty"] != 0 {
+		t.Errorf("got package coverage %v, want store 75%% and empty 0%%", percents)
+	}
+	if !strings.Contains(report.String(), "store: 75.0% (3/4)") || !strings.Contains(report.String(), "total: 60.0% (3/5)") {
+		t.Errorf("unexpected summary:\n%s", report.String())
+	}
+}
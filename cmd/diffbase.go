@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"github.com/brendan-sadlier/docura/internal/logging"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// gitRunner runs git commands, so --diff-base can be exercised without a
+// repository.
+type gitRunner interface {
+	// Run runs git with args in dir, returning its standard output
+	Run(dir string, args ...string) ([]byte, error)
+}
+
+// execGit runs the git binary.
+type execGit struct{}
+
+func (execGit) Run(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// changedPackages returns the packages in pkgs with a file that git reports
+// changed since base, including uncommitted changes and new files not yet
+// added.
+func changedPackages(git gitRunner, projectDir string, base string, pkgs []*analyser.PackageInfo) ([]*analyser.PackageInfo, error) {
+	diff, err := git.Run(projectDir, "diff", "--name-only", "--relative", base, "--")
+	if err != nil {
+		return nil, fmt.Errorf("listing files changed since %s: %w", base, err)
+	}
+	untracked, err := git.Run(projectDir, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, fmt.Errorf("listing untracked files: %w", err)
+	}
+
+	changed := make(map[string]bool)
+	for _, file := range strings.Split(string(diff)+"\n"+string(untracked), "\n") {
+		if file = strings.TrimSpace(file); file != "" {
+			changed[filepath.Join(projectDir, filepath.Dir(filepath.FromSlash(file)))] = true
+		}
+	}
+
+	var kept []*analyser.PackageInfo
+	for _, pkg := range pkgs {
+		if changed[filepath.Clean(pkg.Path)] {
+			kept = append(kept, pkg)
+		} else {
+			logging.Debugf("Skipping %s: unchanged since %s", pkg.Path, base)
+		}
+	}
+	return kept, nil
+}
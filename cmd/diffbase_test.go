@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"errors"
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakeGit answers git commands from outputs, keyed by their arguments.
+type fakeGit struct {
+	outputs map[string]string
+	dirs    []string
+}
+
+func (g *fakeGit) Run(dir string, args ...string) ([]byte, error) {
+	g.dirs = append(g.dirs, dir)
+	out, ok := g.outputs[strings.Join(args, " ")]
+	if !ok {
+		return nil, errors.New("unexpected git " + strings.Join(args, " "))
+	}
+	return []byte(out), nil
+}
+
+func TestChangedPackages(t *testing.T) {
+	project := t.TempDir()
+	var pkgs []*analyser.PackageInfo
+	for _, dir := range []string{"store", "cache", "api/v1", "cmd/tool"} {
+		pkgs = append(pkgs, &analyser.PackageInfo{Name: filepath.Base(dir), Path: filepath.Join(project, filepath.FromSlash(dir))})
+	}
+
+	git := &fakeGit{outputs: map[string]string{
+		"diff --name-only --relative main --":  "store/store.go\napi/v1/README.md\n",
+		"ls-files --others --exclude-standard": "cmd/tool/new.go\n",
+	}}
+	changed, err := changedPackages(git, project, "main", pkgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, pkg := range changed {
+		names = append(names, pkg.Name)
+	}
+	if want := []string{"store", "v1", "tool"}; !slices.Equal(names, want) {
+		t.Errorf("got changed packages %v, want %v", names, want)
+	}
+	for _, dir := range git.dirs {
+		if dir != project {
+			t.Errorf("git run in %s, want the project directory", dir)
+		}
+	}
+
+	delete(git.outputs, "diff --name-only --relative main --")
+	if _, err := changedPackages(git, project, "main", pkgs); err == nil || !strings.Contains(err.Error(), "since main") {
+		t.Errorf("got error %v for a failing git diff", err)
+	}
+}
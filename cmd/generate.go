@@ -2,13 +2,18 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/brendan-sadlier/docura/internal/analyser"
 	"github.com/brendan-sadlier/docura/internal/generator"
+	"github.com/brendan-sadlier/docura/internal/logging"
 	"github.com/spf13/cobra"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 	"time"
 )
@@ -16,16 +21,53 @@ import (
 var (
 	projectDir    string
 	docsOutputDir string
-	configFile    string
+	configFiles   []string
 	watch         bool
 	packageName   string
+	mirror        bool
+	splitTypes    bool
+	skipEmpty     bool
+	onlyPublic    bool
+	internalDocs  bool
+	writeBack     bool
+	offline       bool
+	check         bool
+	clean         bool
+	maxDepth      int
+	examplesDir   string
+	diffBase      string
+
+	markdownExamples bool
+	mergeReadme      bool
+	httpRoutes       bool
+	implements       bool
+	typeCheck        bool
+	testingSection   bool
+	signatureWidth   int
+	wrapWidth        int
+	symbols          []string
+
+	failOnMissingDocs bool
+	minDocLength      int
+	coverage          bool
+
+	llmTimeout     int
+	llmRetries     int
+	fallbackModels []string
+	llmConcurrency int
+	llmBatchSize   int
+	llmRate        int
+	llmTemperature float64
+	llmSeed        int
+	stream         bool
 )
 var generateCmd = &cobra.Command{
-	Use:   "generate",
+	Use:   "generate [-]",
 	Short: "generate documentation",
-	Long:  `generate Markdown documentation for Golang packages`,
+	Long:  `generate Markdown documentation for Golang packages, or with "-" for Go source read from standard input, printing it to standard output`,
+	Args:  cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := runGenerate(); err != nil {
+		if err := runGenerate(cmd, args); err != nil {
 			log.Fatalf("generate failed: %v", err)
 		}
 	},
@@ -34,148 +76,602 @@ var generateCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(generateCmd)
 	generateCmd.Flags().StringVarP(&projectDir, "directory", "d", "", "Project directory to generate documentation")
-	generateCmd.Flags().StringVarP(&docsOutputDir, "output", "o", "./docs", "Output directory for generated documentation [default ./docs]")
-	generateCmd.Flags().StringVarP(&configFile, "config", "c", "", "Configuration file in JSON format")
+	generateCmd.Flags().StringVarP(&docsOutputDir, "output", "o", "./docs", "Output directory for generated documentation, or - to print it to standard output [default ./docs]")
+	generateCmd.Flags().StringArrayVarP(&configFiles, "config", "c", nil, "Configuration file in JSON format. Repeat to layer files, later ones overriding the settings they give")
 	generateCmd.Flags().BoolVarP(&watch, "watch", "w", false, "Watch for changes to the documentation")
-	generateCmd.Flags().StringVarP(&packageName, "package", "p", "", "Specific package to analyse")
+	generateCmd.Flags().StringVarP(&packageName, "package", "p", "", "Specific package to analyse, or dir/... for every package below dir, including vendor/...")
+	generateCmd.Flags().BoolVar(&offline, "offline", false, "Generate documentation from source comments only, without calling the LLM")
+	generateCmd.Flags().BoolVar(&check, "check", false, "Compare generated documentation with the output directory and fail if they differ, without writing")
+	generateCmd.Flags().BoolVar(&clean, "clean", false, "Remove documentation written by an earlier run for packages that are no longer documented")
+	generateCmd.Flags().BoolVar(&splitTypes, "split-types", false, "Write each exported type to its own file under a directory named after its package")
+	generateCmd.Flags().BoolVar(&skipEmpty, "skip-empty", false, "Skip packages with no exported API instead of writing a page saying so")
+	generateCmd.Flags().BoolVar(&writeBack, "write-back", false, "Experimental: write generated descriptions into the source as doc comments for functions and types with missing or brief ones, instead of generating documentation")
+	generateCmd.Flags().BoolVar(&onlyPublic, "only-public", false, "Leave unexported struct fields out of the docs, even if a config file sets include_private")
+	generateCmd.Flags().BoolVar(&internalDocs, "internal-docs", false, "Also write each package's unexported symbols to <package>.internal.md")
+	generateCmd.Flags().BoolVar(&testingSection, "testing-section", false, "Document test helpers and testable examples in a Testing section")
+	generateCmd.Flags().IntVar(&signatureWidth, "signature-width", 0, "Put each parameter on its own line in signatures longer than this, 0 to never wrap")
+	generateCmd.Flags().IntVar(&wrapWidth, "wrap-width", 0, "Reflow Markdown paragraphs to at most this many columns, 0 to never wrap")
+	generateCmd.Flags().BoolVar(&markdownExamples, "markdown-examples", false, "Use Go code blocks from each package's README.md or examples.md as its examples")
+	generateCmd.Flags().BoolVar(&mergeReadme, "merge-readme", false, "Add each package's README.md to its description")
+	generateCmd.Flags().StringSliceVar(&symbols, "symbols", nil, "Only document these symbols, e.g. Foo,Bar.Method, and the types they use")
+	generateCmd.Flags().BoolVar(&httpRoutes, "http-routes", false, "Document HTTP handlers and the routes they are registered on")
+	generateCmd.Flags().StringVar(&diffBase, "diff-base", "", "Only document packages with files changed since this git ref")
+	generateCmd.Flags().StringVar(&examplesDir, "examples-dir", "", "Directory of example main programs to show as usage examples of the packages they import, rather than documenting them")
+	generateCmd.Flags().IntVar(&maxDepth, "max-depth", -1, "Maximum directory depth below the project directory to look for packages, 0 for only the top directory")
+	generateCmd.Flags().BoolVar(&implements, "implements", false, "List the package interfaces each type implements")
+	generateCmd.Flags().BoolVar(&typeCheck, "type-check", false, "Resolve constant and variable types with the Go type checker, which is slower and needs the packages to compile")
+	generateCmd.Flags().BoolVar(&mirror, "mirror", false, "Mirror the source package directory structure in the output directory")
+	generateCmd.Flags().IntVar(&llmTimeout, "llm-timeout", 0, "Timeout in seconds for each LLM request, 0 for no timeout")
+	generateCmd.Flags().IntVar(&llmRetries, "llm-retries", 0, "Number of times to retry a failed LLM request")
+	generateCmd.Flags().StringSliceVar(&fallbackModels, "fallback-models", nil, "Models to try, in order, when a request to the primary model keeps failing")
+	generateCmd.Flags().IntVar(&llmConcurrency, "llm-concurrency", 1, "Maximum number of concurrent LLM requests")
+	generateCmd.Flags().IntVar(&llmBatchSize, "llm-batch-size", 0, "Maximum number of symbols to describe in a single LLM request, 0 or 1 to describe each separately")
+	generateCmd.Flags().IntVar(&llmRate, "llm-rate", 0, "Maximum number of LLM requests per minute, 0 for no limit")
+	generateCmd.Flags().Float64Var(&llmTemperature, "llm-temperature", 0.2, "Sampling temperature for LLM requests, lower for more stable output")
+	generateCmd.Flags().IntVar(&llmSeed, "llm-seed", 0, "Seed for reproducible LLM output where the model supports it, 0 for none")
+	generateCmd.Flags().BoolVar(&stream, "stream", false, "Print model output as it is generated")
+	generateCmd.Flags().BoolVar(&failOnMissingDocs, "fail-on-missing-docs", false, "Report undocumented exported symbols and exit non-zero if any are found, without generating output")
+	generateCmd.Flags().BoolVar(&coverage, "coverage", false, "Report the percentage of exported symbols with doc comments and write doc-coverage.json to the output directory, without generating documentation")
+	generateCmd.Flags().IntVar(&minDocLength, "min-doc-length", 0, "Minimum doc comment length for --fail-on-missing-docs")
 }
 
-func runGenerate() error {
+func runGenerate(cmd *cobra.Command, args []string) error {
 	// Default config values
-	config := generator.DocConfig{
-		OutputDir:        docsOutputDir,
-		IncludePrivate:   false,
-		GenerateExamples: true,
-		Style:            "markdown",
+	config := generator.DefaultConfig()
+	config.OutputDir = docsOutputDir
+
+	// Load config files if specified
+	loadConfigs(configFiles, &config)
+
+	applyFlags(cmd, &config)
+
+	analyserInstance, err := newAnalyser(projectDir, config)
+	if err != nil {
+		return err
 	}
 
-	// Load config file if specified
-	if configFile != "" {
-		if err := loadConfig(configFile, &config); err != nil {
-			log.Printf("Could not load config file %s, proceding with default: %v ", configFile, err)
-		}
+	if failOnMissingDocs {
+		return lintDocs(os.Stdout, analyserInstance, projectDir, packageName, minDocLength)
+	}
+	if coverage {
+		return coverageReport(os.Stdout, analyserInstance, fileWriter{}, projectDir, packageName, config.OutputDir)
 	}
 
-	analyserInstance := analyser.NewAnalyser()
-	docGenerator, err := generator.NewDocGenerator()
+	var docGenerator *generator.DocGenerator
+	if config.Offline {
+		docGenerator, err = generator.NewDocGeneratorWithModel(nil)
+	} else {
+		docGenerator, err = generator.NewDocGenerator()
+	}
 	if err != nil {
 		log.Fatalf("Could not create document generator: %v", err)
 	}
+	docGenerator.SetStreamOutput(streamOutput(config, args))
+
+	if config.WriteBack {
+		return writeBackDocs(analyserInstance, docGenerator, projectDir, packageName, config)
+	}
+
+	if len(args) == 1 {
+		if args[0] != "-" {
+			return fmt.Errorf("unexpected argument %q, use - to read source from standard input", args[0])
+		}
+		return generateFromStdin(os.Stdin, os.Stdout, analyserInstance, docGenerator, config)
+	}
+
+	if config.OutputDir == stdoutDir {
+		logging.SetOutput(os.Stderr, os.Stderr)
+		return generateDocs(analyserInstance, docGenerator, &streamWriter{w: os.Stdout}, projectDir, config, packageName)
+	}
+
+	if check {
+		out := &checkWriter{w: os.Stdout}
+		if err := generateDocs(analyserInstance, docGenerator, out, projectDir, config, packageName); err != nil {
+			return err
+		}
+		return out.Err()
+	}
 
 	if watch {
 		return watchAndGenerate(analyserInstance, docGenerator, projectDir, config)
 	}
 
-	return generateDocs(analyserInstance, docGenerator, projectDir, config, packageName)
+	partial := packageName != "" || len(symbols) > 0 || diffBase != ""
+	if clean && partial {
+		logging.Warnf("Ignoring --clean as only some packages are documented")
+	}
+
+	out, err := newManifestWriter(fileWriter{}, config.OutputDir)
+	if err != nil {
+		return err
+	}
+	if err := generateDocs(analyserInstance, docGenerator, out, projectDir, config, packageName); err != nil {
+		return err
+	}
+	return out.writeManifest(clean, partial)
 }
 
-func generateDocs(analyser *analyser.Analyser, generator *generator.DocGenerator, projectDir string, config generator.DocConfig, packageName string) error {
-	if packageName != "" {
-		// Document specific package
-		return generatePackageDocs(analyser, generator, filepath.Join(projectDir, packageName), config)
+// streamOutput returns where model output is streamed to. When the
+// documentation itself is printed to standard output, streamed chunks go to
+// standard error so they don't end up mixed into it.
+func streamOutput(config generator.DocConfig, args []string) io.Writer {
+	if config.OutputDir == stdoutDir || len(args) == 1 {
+		return os.Stderr
 	}
+	return os.Stdout
+}
 
-	// Document all packages
-	return filepath.Walk(projectDir, func(path string, info os.FileInfo, err error) error {
+// newAnalyser returns an analyser configured from config that honours the
+// project's .docuraignore.
+func newAnalyser(projectDir string, config generator.DocConfig) (*analyser.Analyser, error) {
+	ignore, err := analyser.LoadIgnoreRules(projectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	analyserInstance := analyser.NewAnalyser()
+	analyserInstance.MarkdownExamples = config.MarkdownExamples
+	analyserInstance.MergeReadme = config.MergeReadme
+	analyserInstance.HTTPRoutes = config.HTTPRoutes
+	analyserInstance.Implements = config.Implements
+	analyserInstance.TypeCheck = config.TypeCheck
+	analyserInstance.PrivateFields = config.IncludePrivate
+	analyserInstance.Unexported = config.WritesInternalDocs()
+	analyserInstance.Ignore = ignore
+
+	return analyserInstance, nil
+}
+
+func generateDocs(analyserInstance *analyser.Analyser, docGenerator *generator.DocGenerator, out docWriter, projectDir string, config generator.DocConfig, packageName string) error {
+	root, isPattern := packageRoot(projectDir, packageName)
+	if packageName != "" && !isPattern {
+		// Document specific package
+		pkg, err := analysePackage(analyserInstance, filepath.Join(projectDir, packageName))
 		if err != nil {
 			return err
 		}
-
-		if !info.IsDir() {
+		if len(symbols) > 0 {
+			if _, err := filterSymbols([]*analyser.PackageInfo{pkg}, symbols); err != nil {
+				return err
+			}
+		}
+		if _, err := attachProgramExamples(analyserInstance, projectDir, config, []*analyser.PackageInfo{pkg}); err != nil {
+			return err
+		}
+		if len(withoutEmpty([]*analyser.PackageInfo{pkg}, config)) == 0 {
 			return nil
 		}
-
-		// Skip vendor, .git, and test directories
-		if shouldSkipDir(path) {
-			return filepath.SkipDir
+		if diffBase != "" {
+			changed, err := changedPackages(execGit{}, projectDir, diffBase, []*analyser.PackageInfo{pkg})
+			if err != nil || len(changed) == 0 {
+				return err
+			}
 		}
+		config.Packages = []string{pkg.Name}
+		_, err = generatePackageDocs(docGenerator, out, pkg, projectDir, config)
+		return err
+	}
+
+	// Document all packages
+	dirs, err := analyser.FindPackageDirs(root, maxDepth, analyserInstance.Ignore)
+	if err != nil {
+		return err
+	}
+	if config.ExamplesDir != "" {
+		examplesRoot := filepath.Join(projectDir, config.ExamplesDir)
+		dirs = slices.DeleteFunc(dirs, func(dir string) bool { return withinDir(dir, examplesRoot) })
+	}
 
-		// Check if directory contains Go files
-		hasGoFiles, err := hasGoSourceFiles(path)
+	// Analyse everything up front so each page can link to the others
+	var pkgs []*analyser.PackageInfo
+	for _, dir := range dirs {
+		pkg, err := analysePackage(analyserInstance, dir)
+		if errors.Is(err, analyser.ErrTestOnlyPackage) {
+			logging.Infof("Skipping %s: contains only test code", dir)
+			continue
+		}
 		if err != nil {
+			logging.Errorf("Error documenting package %s: %v", dir, err)
+			recordFailure(out, projectDir, dir)
+			continue
+		}
+		pkgs = append(pkgs, pkg)
+	}
+
+	if len(symbols) > 0 {
+		if pkgs, err = filterSymbols(pkgs, symbols); err != nil {
 			return err
 		}
+	}
+	unattached, err := attachProgramExamples(analyserInstance, projectDir, config, pkgs)
+	if err != nil {
+		return err
+	}
+	for _, ex := range unattached {
+		logging.Warnf("Example program %s imports none of the documented packages", ex.Name)
+	}
+	pkgs = withoutEmpty(pkgs, config)
+	for _, pkg := range pkgs {
+		config.Packages = append(config.Packages, pkg.Name)
+	}
+	// Unchanged packages are still analysed above, so links to them are kept
+	if diffBase != "" {
+		if pkgs, err = changedPackages(execGit{}, projectDir, diffBase, pkgs); err != nil {
+			return err
+		}
+	}
 
-		if hasGoFiles {
-			if err := generatePackageDocs(analyser, generator, path, config); err != nil {
-				log.Printf("Error documenting package %s: %v", path, err)
-			}
+	var entries []generator.IndexEntry
+	written := make(map[string]string)
+	for _, pkg := range pkgs {
+		outputPath, err := generatePackageDocs(docGenerator, out, pkg, projectDir, config)
+		if err != nil {
+			logging.Errorf("Error documenting package %s: %v", pkg.Path, err)
+			recordFailure(out, projectDir, pkg.Path)
+			continue
 		}
 
+		if previous, ok := written[outputPath]; ok {
+			logging.Warnf("Documentation for %s overwrote %s at %s, set output_name_template to keep them apart", pkg.Path, previous, outputPath)
+		}
+		written[outputPath] = pkg.Path
+
+		link, err := filepath.Rel(config.OutputDir, outputPath)
+		if err != nil {
+			link = outputPath
+		}
+		entries = append(entries, generator.NewIndexEntry(pkg, link))
+	}
+
+	// An index of links is no use when everything is printed together, and
+	// would leave out the unchanged packages with --diff-base
+	if len(entries) == 0 || config.OutputDir == stdoutDir || diffBase != "" {
 		return nil
+	}
+
+	return writeIndex(docGenerator, out, entries, config)
+}
+
+// attachProgramExamples adds the example programs in config.ExamplesDir to
+// the packages in pkgs they import, returning those that import none.
+func attachProgramExamples(analyserInstance *analyser.Analyser, projectDir string, config generator.DocConfig, pkgs []*analyser.PackageInfo) ([]analyser.ProgramExample, error) {
+	if config.ExamplesDir == "" {
+		return nil, nil
+	}
+
+	examples, err := analyserInstance.ProgramExamples(filepath.Join(projectDir, config.ExamplesDir))
+	if err != nil {
+		return nil, err
+	}
+	return analyser.AttachProgramExamples(pkgs, examples), nil
+}
+
+// withoutEmpty drops the packages with no exported API when config.SkipEmpty
+// is set.
+func withoutEmpty(pkgs []*analyser.PackageInfo, config generator.DocConfig) []*analyser.PackageInfo {
+	if !config.SkipEmpty {
+		return pkgs
+	}
+	return slices.DeleteFunc(pkgs, func(pkg *analyser.PackageInfo) bool {
+		if generator.HasExportedAPI(pkg) {
+			return false
+		}
+		logging.Infof("Skipping %s: no exported API", pkg.Path)
+		return true
 	})
 }
 
+// withinDir reports whether path is root or a directory below it.
+func withinDir(path string, root string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && filepath.IsLocal(rel)
+}
+
+// packageRoot resolves the --package flag to a directory, reporting whether it
+// was a dir/... pattern naming every package below that directory.
+func packageRoot(projectDir string, packageName string) (string, bool) {
+	if prefix, ok := strings.CutSuffix(packageName, "..."); ok {
+		return filepath.Join(projectDir, prefix), true
+	}
+	return filepath.Join(projectDir, packageName), false
+}
+
+// filterSymbols restricts pkgs to the requested symbols, dropping packages that
+// contain none of them, and fails if any symbol isn't found in any package.
+func filterSymbols(pkgs []*analyser.PackageInfo, symbols []string) ([]*analyser.PackageInfo, error) {
+	found := make(map[string]bool)
+	var kept []*analyser.PackageInfo
+	for _, pkg := range pkgs {
+		matched := analyser.FilterSymbols(pkg, symbols)
+		for _, name := range matched {
+			found[name] = true
+		}
+		if len(matched) > 0 {
+			kept = append(kept, pkg)
+		}
+	}
+
+	var unknown []string
+	for _, name := range symbols {
+		if !found[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown symbols: %s", strings.Join(unknown, ", "))
+	}
+
+	return kept, nil
+}
+
+func writeIndex(docGenerator *generator.DocGenerator, out docWriter, entries []generator.IndexEntry, config generator.DocConfig) error {
+	index, err := docGenerator.GenerateIndex(entries, config)
+	if err != nil {
+		return fmt.Errorf("generating index: %w", err)
+	}
+
+	indexPath := filepath.Join(config.OutputDir, generator.IndexPath(config))
+	if err := out.Write(indexPath, index); err != nil {
+		return fmt.Errorf("writing index: %w", err)
+	}
+
+	logging.Infof("Generated index: %s", indexPath)
+	return nil
+}
+
+// applyFlags overrides config with any flags explicitly set on the command line,
+// so values from the config file are kept unless the user asks otherwise.
+func applyFlags(cmd *cobra.Command, config *generator.DocConfig) {
+	flags := cmd.Flags()
+
+	if mirror {
+		config.Mirror = true
+	}
+	if offline {
+		config.Offline = true
+	}
+	if splitTypes {
+		config.SplitTypes = true
+	}
+	if skipEmpty {
+		config.SkipEmpty = true
+	}
+	if onlyPublic {
+		config.IncludePrivate = false
+	}
+	if internalDocs {
+		config.InternalDocs = true
+	}
+	if writeBack {
+		config.WriteBack = true
+	}
+	if testingSection {
+		config.TestingSection = true
+	}
+	if flags.Changed("signature-width") {
+		config.SignatureWidth = signatureWidth
+	}
+	if flags.Changed("wrap-width") {
+		config.WrapWidth = wrapWidth
+	}
+	if markdownExamples {
+		config.MarkdownExamples = true
+	}
+	if mergeReadme {
+		config.MergeReadme = true
+	}
+	if stream {
+		config.Stream = true
+	}
+	if httpRoutes {
+		config.HTTPRoutes = true
+	}
+	if implements {
+		config.Implements = true
+	}
+	if typeCheck {
+		config.TypeCheck = true
+	}
+	if flags.Changed("llm-timeout") {
+		config.LLMTimeout = llmTimeout
+	}
+	if flags.Changed("llm-retries") {
+		config.LLMRetries = llmRetries
+	}
+	if flags.Changed("fallback-models") {
+		config.FallbackModels = fallbackModels
+	}
+	if flags.Changed("llm-concurrency") {
+		config.LLMConcurrency = llmConcurrency
+	}
+	if flags.Changed("llm-rate") {
+		config.RequestsPerMinute = llmRate
+	}
+	if flags.Changed("llm-temperature") {
+		config.Temperature = llmTemperature
+	}
+	if flags.Changed("llm-seed") {
+		config.Seed = llmSeed
+	}
+	if flags.Changed("llm-batch-size") {
+		config.BatchSize = llmBatchSize
+	}
+	if flags.Changed("examples-dir") {
+		config.ExamplesDir = examplesDir
+	}
+}
+
+// packageDirs lists the package directories selected by the --package flag.
+func packageDirs(projectDir string, packageName string, ignore *analyser.IgnoreRules) ([]string, error) {
+	root, isPattern := packageRoot(projectDir, packageName)
+	if packageName != "" && !isPattern {
+		return []string{root}, nil
+	}
+	return analyser.FindPackageDirs(root, maxDepth, ignore)
+}
+
+// lintDocs writes the exported symbols missing documentation to w, failing if
+// there are any.
+func lintDocs(w io.Writer, analyserInstance *analyser.Analyser, projectDir string, packageName string, minLength int) error {
+	dirs, err := packageDirs(projectDir, packageName, analyserInstance.Ignore)
+	if err != nil {
+		return err
+	}
+
+	var missing []analyser.MissingDoc
+	for _, dir := range dirs {
+		pkg, err := analyserInstance.AnalysePackage(dir)
+		if errors.Is(err, analyser.ErrTestOnlyPackage) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("analyzing package %s: %w", dir, err)
+		}
+		missing = append(missing, analyser.FindMissingDocs(pkg, minLength)...)
+	}
+
+	for _, m := range missing {
+		fmt.Fprintf(w, "%s: %s %s is missing documentation\n", m.Package, m.Kind, m.Name)
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%d exported symbols are missing documentation", len(missing))
+	}
+
+	return nil
+}
+
 func watchAndGenerate(analyser *analyser.Analyser, generator *generator.DocGenerator, projectDir string, config generator.DocConfig) error {
 	// Simplified file watching - you'd want to use fsnotify for production
-	fmt.Printf("Watching %s for changes...\n", projectDir)
+	logging.Infof("Watching %s for changes...", projectDir)
 
 	for {
-		if err := generateDocs(analyser, generator, projectDir, config, ""); err != nil {
-			log.Printf("Error generating docs: %v", err)
+		if err := generateDocs(analyser, generator, fileWriter{}, projectDir, config, ""); err != nil {
+			logging.Errorf("Error generating docs: %v", err)
 		}
 		time.Sleep(30 * time.Second)
 	}
 }
 
-func generatePackageDocs(analyser *analyser.Analyser, generator *generator.DocGenerator, packageDir string, config generator.DocConfig) error {
-	fmt.Printf("Analyzing package: %s\n", packageDir)
+func analysePackage(analyserInstance *analyser.Analyser, packageDir string) (*analyser.PackageInfo, error) {
+	logging.Infof("Analyzing package: %s", packageDir)
 
-	// Analyze package
-	pkg, err := analyser.AnalysePackage(packageDir)
+	pkg, err := analyserInstance.AnalysePackage(packageDir)
 	if err != nil {
-		return fmt.Errorf("analyzing package: %w", err)
+		return nil, fmt.Errorf("analyzing package: %w", err)
 	}
 
-	// Generate documentation
-	doc, err := generator.GeneratePackageDoc(pkg, config)
+	for _, warning := range pkg.Warnings {
+		logging.Warnf("%s: %s", packageDir, warning)
+	}
+
+	return pkg, nil
+}
+
+func generatePackageDocs(docGenerator *generator.DocGenerator, out docWriter, pkg *analyser.PackageInfo, projectDir string, config generator.DocConfig) (string, error) {
+	relDir, err := filepath.Rel(projectDir, pkg.Path)
 	if err != nil {
-		return fmt.Errorf("generating documentation: %w", err)
+		return "", fmt.Errorf("resolving package directory: %w", err)
 	}
 
-	// Write to file
-	outputPath := filepath.Join(config.OutputDir, pkg.Name+".md")
-	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-		return fmt.Errorf("creating output directory: %w", err)
+	outputName, err := generator.OutputPath(config, pkg, relDir)
+	if err != nil {
+		return "", err
 	}
 
-	if err := os.WriteFile(outputPath, []byte(doc), 0644); err != nil {
-		return fmt.Errorf("writing documentation: %w", err)
+	// Generate documentation
+	doc, err := docGenerator.GeneratePackageDoc(pkg, config)
+	if err != nil {
+		return "", fmt.Errorf("generating documentation: %w", err)
 	}
 
-	fmt.Printf("Generated documentation: %s\n", outputPath)
-	return nil
-}
+	// Write to file
+	outputPath := filepath.Join(config.OutputDir, outputName)
+	if err := out.Write(outputPath, doc); err != nil {
+		return "", err
+	}
+	recordSource(out, outputPath, relDir)
+	logging.Infof("Generated documentation: %s", outputPath)
 
-func shouldSkipDir(path string) bool {
-	base := filepath.Base(path)
-	return base == "vendor" ||
-		base == ".git" ||
-		base == "testdata" ||
-		strings.HasSuffix(base, "_test")
-}
+	if config.SplitsTypes() {
+		for _, typ := range pkg.Types {
+			if !typ.IsExported {
+				continue
+			}
 
-func hasGoSourceFiles(dir string) (bool, error) {
-	files, err := os.ReadDir(dir)
-	if err != nil {
-		return false, err
+			typeDoc, err := docGenerator.GenerateTypeDoc(pkg, typ, config)
+			if err != nil {
+				return "", fmt.Errorf("generating documentation for type %s: %w", typ.Name, err)
+			}
+
+			typePath := filepath.Join(filepath.Dir(outputPath), generator.TypeDocPath(pkg, typ, config))
+			if err := out.Write(typePath, typeDoc); err != nil {
+				return "", err
+			}
+			recordSource(out, typePath, relDir)
+			logging.Debugf("Generated type documentation: %s", typePath)
+		}
 	}
 
-	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".go") &&
-			!strings.HasSuffix(file.Name(), "_test.go") {
-			return true, nil
+	if config.WritesInternalDocs() {
+		internalDoc, err := docGenerator.GenerateInternalDoc(pkg, config)
+		if err != nil {
+			return "", fmt.Errorf("generating internal documentation: %w", err)
+		}
+
+		internalPath := filepath.Join(filepath.Dir(outputPath), generator.InternalDocPath(pkg, config))
+		if err := out.Write(internalPath, internalDoc); err != nil {
+			return "", err
 		}
+		recordSource(out, internalPath, relDir)
+		logging.Debugf("Generated internal documentation: %s", internalPath)
 	}
 
-	return false, nil
+	return outputPath, nil
+}
+
+// loadConfigs loads each of filenames over config in turn, so a later file
+// overrides the settings it gives and keeps the rest. Files that can't be
+// loaded are skipped with a warning.
+func loadConfigs(filenames []string, config *generator.DocConfig) {
+	for _, filename := range filenames {
+		if err := loadConfig(filename, config); err != nil {
+			logging.Warnf("Could not load config file %s, proceding without it: %v", filename, err)
+		}
+	}
 }
 
+// loadConfig reads filename over config, changing only the settings the file
+// names. A later file so overrides an earlier one setting by setting, and a
+// map such as labels is replaced rather than merged.
 func loadConfig(filename string, config *generator.DocConfig) error {
 	data, err := os.ReadFile(filename)
 	if err != nil {
 		return err
 	}
 
-	return json.Unmarshal(data, config)
+	var set map[string]json.RawMessage
+	if err := json.Unmarshal(data, &set); err != nil {
+		return err
+	}
+	var layer generator.DocConfig
+	if err := json.Unmarshal(data, &layer); err != nil {
+		return err
+	}
+
+	mergeConfig(config, layer, set)
+	return nil
+}
+
+// mergeConfig copies the fields of layer whose JSON names are in set over
+// config.
+func mergeConfig(config *generator.DocConfig, layer generator.DocConfig, set map[string]json.RawMessage) {
+	dst := reflect.ValueOf(config).Elem()
+	src := reflect.ValueOf(layer)
+	for i := 0; i < dst.NumField(); i++ {
+		name, _, _ := strings.Cut(dst.Type().Field(i).Tag.Get("json"), ",")
+		if _, ok := set[name]; ok && name != "-" {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
 }
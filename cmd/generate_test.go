@@ -0,0 +1,623 @@
+package cmd
+
+import (
+	"bytes"
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"github.com/brendan-sadlier/docura/internal/generator"
+	"github.com/brendan-sadlier/docura/internal/logging"
+	"github.com/spf13/pflag"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	logging.SetLevel(logging.LevelError)
+	os.Exit(m.Run())
+}
+
+// writeProject writes files, keyed by slash-separated path, to a temporary
+// directory and returns it.
+func writeProject(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// resetFlags puts the generate command's flags back to their defaults.
+func resetFlags() {
+	generateCmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			slice.Replace(nil)
+		} else {
+			f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	})
+}
+
+// parseFlags parses args as the generate command's flags, resetting them
+// once the test is done.
+func parseFlags(t *testing.T, args ...string) {
+	t.Helper()
+	resetFlags()
+	t.Cleanup(resetFlags)
+	if err := generateCmd.Flags().Parse(args); err != nil {
+		t.Fatalf("parsing flags %q: %v", args, err)
+	}
+}
+
+// runGenerateArgs runs the generate command with args.
+func runGenerateArgs(t *testing.T, args ...string) error {
+	t.Helper()
+	parseFlags(t, args...)
+	return runGenerate(generateCmd, nil)
+}
+
+// writeConfig writes a JSON config file and returns its path.
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "docura.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// assertFiles fails unless each of paths, relative to dir, exists.
+func assertFiles(t *testing.T, dir string, paths ...string) {
+	t.Helper()
+	for _, path := range paths {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(path))); err != nil {
+			t.Errorf("expected %s: %v", path, err)
+		}
+	}
+}
+
+func TestLintDocs(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"p/p.go": `package p
+
+// Documented does something useful.
+func Documented() {}
+
+func Undocumented() {}
+
+// T is brief.
+type T struct{}
+
+const Limit = 10
+`,
+	})
+
+	var out bytes.Buffer
+	err := lintDocs(&out, analyser.NewAnalyser(), dir, "", 0)
+	if err == nil {
+		t.Fatal("lintDocs succeeded with undocumented symbols")
+	}
+	for _, want := range []string{"function Undocumented", "constant Limit"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report missing %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "Documented is") || strings.Contains(out.String(), "type T") {
+		t.Errorf("report lists documented symbols:\n%s", out.String())
+	}
+
+	out.Reset()
+	lintDocs(&out, analyser.NewAnalyser(), dir, "", 15)
+	if !strings.Contains(out.String(), "type T") {
+		t.Errorf("report with --min-doc-length 15 missing type T:\n%s", out.String())
+	}
+
+	documented := writeProject(t, map[string]string{"p/p.go": "package p\n\n// Documented does something useful.\nfunc Documented() {}\n"})
+	out.Reset()
+	if err := lintDocs(&out, analyser.NewAnalyser(), documented, "", 0); err != nil || out.Len() > 0 {
+		t.Errorf("lintDocs on documented package: %v\n%s", err, out.String())
+	}
+}
+
+func TestOutputNameTemplate(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"go.mod":              "module example.com/api\n\ngo 1.22\n",
+		"client/client.go":    "package client\n\n// Get gets.\nfunc Get() {}\n",
+		"v2/client/client.go": "package client\n\n// Fetch fetches.\nfunc Fetch() {}\n",
+	})
+	out := t.TempDir()
+	config := writeConfig(t, `{"output_name_template": "{{.ImportPath}}{{.Ext}}"}`)
+
+	if err := runGenerateArgs(t, "-d", dir, "-o", out, "-c", config, "--offline"); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	assertFiles(t, out, "example.com/api/client.md", "example.com/api/v2/client.md")
+}
+
+func TestMirror(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"go.mod":                        "module example.com/project\n\ngo 1.22\n",
+		"internal/analyser/analyser.go": "package analyser\n\n// Analyse analyses.\nfunc Analyse() {}\n",
+		"cmd/cmd.go":                    "package cmd\n\n// Run runs.\nfunc Run() {}\n",
+	})
+	out := t.TempDir()
+
+	if err := runGenerateArgs(t, "-d", dir, "-o", out, "--mirror", "--offline"); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	assertFiles(t, out, "internal/analyser/analyser.md", "cmd/cmd.md")
+
+	index, err := os.ReadFile(filepath.Join(out, "index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), "internal/analyser/analyser.md") {
+		t.Errorf("index doesn't link to the nested page:\n%s", index)
+	}
+}
+
+func TestLLMFlags(t *testing.T) {
+	parseFlags(t)
+	config := generator.DefaultConfig()
+	applyFlags(generateCmd, &config)
+	if config.LLMTimeout != 0 || config.LLMRetries != 0 || config.LLMConcurrency != 1 {
+		t.Errorf("defaults changed: timeout %d retries %d concurrency %d", config.LLMTimeout, config.LLMRetries, config.LLMConcurrency)
+	}
+
+	parseFlags(t, "--llm-timeout", "30", "--llm-retries", "3", "--llm-concurrency", "4")
+	applyFlags(generateCmd, &config)
+	if config.LLMTimeout != 30 || config.LLMRetries != 3 || config.LLMConcurrency != 4 {
+		t.Errorf("got timeout %d retries %d concurrency %d, want 30 3 4", config.LLMTimeout, config.LLMRetries, config.LLMConcurrency)
+	}
+}
+
+func TestLLMFlagsKeepConfigFile(t *testing.T) {
+	parseFlags(t, "--llm-retries", "5")
+	config := generator.DefaultConfig()
+	config.LLMTimeout = 60
+	config.LLMConcurrency = 8
+	applyFlags(generateCmd, &config)
+	if config.LLMTimeout != 60 || config.LLMConcurrency != 8 || config.LLMRetries != 5 {
+		t.Errorf("flags not given should keep the config file's values, got timeout %d retries %d concurrency %d", config.LLMTimeout, config.LLMRetries, config.LLMConcurrency)
+	}
+}
+
+func TestSkipsTestOnlyPackages(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"store/store.go":          "package store\n\n// Put puts.\nfunc Put() {}\n",
+		"storetest/store_test.go": "package store_test\n\nimport \"testing\"\n\nfunc TestPut(t *testing.T) {}\n",
+	})
+	out := t.TempDir()
+
+	if err := runGenerateArgs(t, "-d", dir, "-o", out, "--offline"); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	entries, err := os.ReadDir(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pages []string
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) == ".md" {
+			pages = append(pages, entry.Name())
+		}
+	}
+	if strings.Join(pages, ",") != "index.md,store.md" {
+		t.Errorf("got pages %v, want only index.md and store.md", pages)
+	}
+}
+
+func TestSplitTypes(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"store/store.go": `package store
+
+// Store holds values.
+type Store struct {
+	// Size is the number of values.
+	Size int
+}
+
+// Put stores v.
+func (s *Store) Put(v string) {}
+
+// Entry is a stored value.
+type Entry string
+
+type hidden struct{}
+`,
+	})
+	out := t.TempDir()
+
+	if err := runGenerateArgs(t, "-d", dir, "-o", out, "--split-types", "--offline"); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	assertFiles(t, out, "store.md", "store/Store.md", "store/Entry.md")
+	if _, err := os.Stat(filepath.Join(out, "store", "hidden.md")); err == nil {
+		t.Error("unexported type got its own page")
+	}
+
+	index, err := os.ReadFile(filepath.Join(out, "store.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"[Store](store/Store.md)", "[Entry](store/Entry.md)"} {
+		if !strings.Contains(string(index), want) {
+			t.Errorf("package page missing link %s:\n%s", want, index)
+		}
+	}
+
+	typePage, err := os.ReadFile(filepath.Join(out, "store", "Store.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Size", "Put"} {
+		if !strings.Contains(string(typePage), want) {
+			t.Errorf("type page missing %s:\n%s", want, typePage)
+		}
+	}
+}
+
+func TestSymbols(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"store/store.go": `package store
+
+// Store holds values.
+type Store struct {
+	last Entry
+}
+
+// Entry is a stored value.
+type Entry string
+
+// Put stores v.
+func (s *Store) Put(v Entry) {}
+
+// Delete removes v.
+func (s *Store) Delete(v Entry) {}
+
+// Open opens a store.
+func Open() *Store { return nil }
+
+// Close closes everything.
+func Close() {}
+`,
+	})
+	out := t.TempDir()
+
+	if err := runGenerateArgs(t, "-d", dir, "-o", out, "--symbols", "Open,Store.Put", "--offline"); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	page, err := os.ReadFile(filepath.Join(out, "store.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Open", "Store.Put", "Entry"} {
+		if !strings.Contains(string(page), "#### "+want+"\n") {
+			t.Errorf("page missing %s:\n%s", want, page)
+		}
+	}
+	for _, unwanted := range []string{"Delete", "Close"} {
+		if strings.Contains(string(page), unwanted) {
+			t.Errorf("page documents %s, which wasn't requested:\n%s", unwanted, page)
+		}
+	}
+
+	err = runGenerateArgs(t, "-d", dir, "-o", t.TempDir(), "--symbols", "Open,Missing", "--offline")
+	if err == nil || !strings.Contains(err.Error(), "Missing") {
+		t.Errorf("got %v, want an error naming the unknown symbol", err)
+	}
+}
+
+func TestVendorOnRequest(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"go.mod":                            "module example.com/app\n\ngo 1.22\n",
+		"app.go":                            "package app\n\n// Run runs.\nfunc Run() {}\n",
+		"vendor/example.com/lib/lib.go":     "package lib\n\n// Help helps.\nfunc Help() {}\n",
+		"vendor/example.com/lib/sub/sub.go": "package sub\n\n// Sub subs.\nfunc Sub() {}\n",
+	})
+
+	out := t.TempDir()
+	if err := runGenerateArgs(t, "-d", dir, "-o", out, "--offline"); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	assertFiles(t, out, "app.md")
+	if _, err := os.Stat(filepath.Join(out, "lib.md")); err == nil {
+		t.Error("full-tree run documented a vendored package")
+	}
+
+	out = t.TempDir()
+	if err := runGenerateArgs(t, "-d", dir, "-o", out, "-p", "vendor/example.com/lib", "--offline"); err != nil {
+		t.Fatalf("generate vendored package: %v", err)
+	}
+	assertFiles(t, out, "lib.md")
+
+	out = t.TempDir()
+	if err := runGenerateArgs(t, "-d", dir, "-o", out, "-p", "vendor/...", "--offline"); err != nil {
+		t.Fatalf("generate vendor/...: %v", err)
+	}
+	assertFiles(t, out, "lib.md", "sub.md")
+}
+
+func TestMaxDepth(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"top/top.go":           "package top\n\n// Top is on top.\nfunc Top() {}\n",
+		"top/mid/mid.go":       "package mid\n\n// Mid is below.\nfunc Mid() {}\n",
+		"top/mid/deep/deep.go": "package deep\n\n// Deep is far below.\nfunc Deep() {}\n",
+	})
+	out := t.TempDir()
+
+	if err := runGenerateArgs(t, "-d", dir, "-o", out, "--max-depth", "2", "--offline"); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	assertFiles(t, out, "top.md", "mid.md")
+	if _, err := os.Stat(filepath.Join(out, "deep.md")); err == nil {
+		t.Error("package below --max-depth was documented")
+	}
+}
+
+func TestOutputExtPerStyle(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"store/store.go": "package store\n\n// Put puts.\nfunc Put() {}\n",
+		"cache/cache.go": "package cache\n\n// Get gets.\nfunc Get() {}\n",
+	})
+
+	tests := []struct {
+		config string
+		ext    string
+		link   string
+	}{
+		{`{"style": "markdown"}`, ".md", "(store.md)"},
+		{`{"style": "godoc"}`, ".txt", "(store.txt)"},
+		{`{"style": "html"}`, ".html", `href="store.html"`},
+		{`{"style": "asciidoc"}`, ".adoc", "link:store.adoc[store]"},
+		{`{"style": "html", "output_ext": "htm"}`, ".htm", `href="store.htm"`},
+	}
+	for _, test := range tests {
+		out := t.TempDir()
+		if err := runGenerateArgs(t, "-d", dir, "-o", out, "-c", writeConfig(t, test.config), "--offline"); err != nil {
+			t.Fatalf("%s: generate: %v", test.config, err)
+		}
+
+		entries, err := os.ReadDir(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var files []string
+		for _, entry := range entries {
+			if !entry.IsDir() && entry.Name() != manifestName {
+				files = append(files, entry.Name())
+			}
+		}
+		want := []string{"cache" + test.ext, "index" + test.ext, "store" + test.ext}
+		if strings.Join(files, ",") != strings.Join(want, ",") {
+			t.Errorf("%s: got files %v, want %v", test.config, files, want)
+		}
+
+		index, err := os.ReadFile(filepath.Join(out, "index"+test.ext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(index), test.link) {
+			t.Errorf("%s: index doesn't link to %s:\n%s", test.config, test.link, index)
+		}
+	}
+}
+
+func TestExamplesDir(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"go.mod":                 "module example.com/greet\n\ngo 1.22\n",
+		"greet.go":               "package greet\n\n// Hello returns a greeting.\nfunc Hello(name string) string { return \"hello \" + name }\n",
+		"examples/basic/main.go": "package main\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/greet\"\n)\n\nfunc main() {\n\tfmt.Println(greet.Hello(\"gopher\"))\n}\n",
+	})
+	out := t.TempDir()
+	config := writeConfig(t, `{"examples_dir": "examples"}`)
+
+	if err := runGenerateArgs(t, "-d", dir, "-o", out, "-c", config, "--offline"); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "main.md")); err == nil {
+		t.Error("example program was documented as a package of its own")
+	}
+
+	page, err := os.ReadFile(filepath.Join(out, "greet.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"func main() {", `fmt.Println(greet.Hello("gopher"))`} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("package page missing example %q:\n%s", want, page)
+		}
+	}
+}
+
+func TestSkipEmpty(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"store/store.go":     "package store\n\n// Put puts.\nfunc Put() {}\n",
+		"private/private.go": "package private\n\nfunc helper() {}\n\ntype state struct{}\n",
+	})
+
+	out := t.TempDir()
+	if err := runGenerateArgs(t, "-d", dir, "-o", out, "--offline"); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	page, err := os.ReadFile(filepath.Join(out, "private.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(page), "_No exported API._") || strings.Contains(string(page), "### Functions") {
+		t.Errorf("all-unexported package should only note it has no exported API:\n%s", page)
+	}
+
+	out = t.TempDir()
+	if err := runGenerateArgs(t, "-d", dir, "-o", out, "-c", writeConfig(t, `{"skip_empty": true}`), "--offline"); err != nil {
+		t.Fatalf("generate with skip_empty: %v", err)
+	}
+	assertFiles(t, out, "store.md")
+	if _, err := os.Stat(filepath.Join(out, "private.md")); err == nil {
+		t.Error("skip_empty wrote a page for a package with no exported API")
+	}
+	index, err := os.ReadFile(filepath.Join(out, "index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(index), "private") {
+		t.Errorf("index links to the skipped package:\n%s", index)
+	}
+}
+
+func TestInternalDocs(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"store/store.go": "package store\n\n// Store holds values.\ntype Store struct{}\n\n// Put stores v.\nfunc (s *Store) Put(v string) { s.grow() }\n\n// grow makes room for more values.\nfunc (s *Store) grow() {}\n\n// cache is the shared store.\ntype cache struct{}\n\n// newCache returns an empty cache.\nfunc newCache() *cache { return nil }\n",
+	})
+	out := t.TempDir()
+
+	if err := runGenerateArgs(t, "-d", dir, "-o", out, "--offline", "--internal-docs"); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	assertFiles(t, out, "store.md", "store.internal.md")
+
+	public, err := os.ReadFile(filepath.Join(out, "store.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	internal, err := os.ReadFile(filepath.Join(out, "store.internal.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, symbol := range []string{"Store", "Put"} {
+		if !strings.Contains(string(public), symbol) {
+			t.Errorf("store.md missing %s:\n%s", symbol, public)
+		}
+	}
+	for _, symbol := range []string{"grow", "cache", "newCache"} {
+		if strings.Contains(string(public), symbol) {
+			t.Errorf("store.md documents unexported %s:\n%s", symbol, public)
+		}
+		if !strings.Contains(string(internal), symbol) {
+			t.Errorf("store.internal.md missing %s:\n%s", symbol, internal)
+		}
+	}
+	if strings.Contains(string(internal), "Put stores v.") {
+		t.Errorf("store.internal.md documents exported Put:\n%s", internal)
+	}
+}
+
+func TestLayeredConfigs(t *testing.T) {
+	base := writeConfig(t, `{"style": "html", "enhance_types": false, "wrap_width": 80, "labels": {"Types": "Typen", "Functions": "Funktionen"}}`)
+	override := writeConfig(t, `{"style": "asciidoc", "wrap_width": 0, "labels": {"Types": "Kinds"}}`)
+
+	config := generator.DefaultConfig()
+	loadConfigs([]string{base, override}, &config)
+
+	if config.Style != "asciidoc" {
+		t.Errorf("style: got %q, want the override's asciidoc", config.Style)
+	}
+	if config.WrapWidth != 0 {
+		t.Errorf("wrap_width: got %d, want the override's explicit 0", config.WrapWidth)
+	}
+	if config.EnhanceTypes {
+		t.Error("enhance_types: the base's false was lost to a file that doesn't set it")
+	}
+	if !config.EnhanceFunctions || config.FieldStyle != "list" {
+		t.Errorf("settings neither file names changed: enhance_functions %t, field_style %q", config.EnhanceFunctions, config.FieldStyle)
+	}
+	if want := map[string]string{"Types": "Kinds"}; !maps.Equal(config.Labels, want) {
+		t.Errorf("labels: got %v, want the override's %v", config.Labels, want)
+	}
+}
+
+func TestGenerateOutsideModule(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"scratch/scratch.go": "// Package scratch is a throwaway package.\npackage scratch\n\n// Try tries.\nfunc Try() {}\n",
+	})
+	out := t.TempDir()
+
+	if err := runGenerateArgs(t, "-d", dir, "-o", out, "--offline"); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	doc, err := os.ReadFile(filepath.Join(out, "scratch.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(doc), "go get") {
+		t.Errorf("package outside a module has an install snippet:\n%s", doc)
+	}
+	if !strings.Contains(string(doc), "# scratch") || !strings.Contains(string(doc), "#### Try") {
+		t.Errorf("package outside a module not documented:\n%s", doc)
+	}
+}
+
+func TestOnlyPublic(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"store/store.go": "package store\n\n// Store holds values.\ntype Store struct {\n\t// Name names the store.\n\tName string\n\t// secret must not be documented.\n\tsecret string\n}\n",
+	})
+	private := writeConfig(t, `{"include_private": true}`)
+
+	tests := []struct {
+		args   []string
+		hidden bool
+	}{
+		{nil, true},
+		{[]string{"-c", private}, false},
+		{[]string{"-c", private, "--only-public"}, true},
+	}
+	for _, test := range tests {
+		out := t.TempDir()
+		args := append([]string{"-d", dir, "-o", out, "--offline"}, test.args...)
+		if err := runGenerateArgs(t, args...); err != nil {
+			t.Fatalf("%v: generate: %v", test.args, err)
+		}
+		doc, err := os.ReadFile(filepath.Join(out, "store.md"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(doc), "'Name'") {
+			t.Errorf("%v: exported field missing:\n%s", test.args, doc)
+		}
+		if hidden := !strings.Contains(string(doc), "secret"); hidden != test.hidden {
+			t.Errorf("%v: unexported field hidden = %t, want %t:\n%s", test.args, hidden, test.hidden, doc)
+		}
+	}
+}
+
+func TestStreamOutput(t *testing.T) {
+	streamConfig := writeConfig(t, `{"stream": true}`)
+
+	tests := []struct {
+		args  []string
+		stdin bool
+		want  *os.File
+	}{
+		{[]string{"--output", "-", "--stream"}, false, os.Stderr},
+		{[]string{"--output", "-", "-c", streamConfig}, false, os.Stderr},
+		{[]string{"--stream"}, true, os.Stderr},
+		{[]string{"--output", t.TempDir(), "--stream"}, false, os.Stdout},
+	}
+	for _, test := range tests {
+		parseFlags(t, test.args...)
+		config := generator.DefaultConfig()
+		config.OutputDir = docsOutputDir
+		loadConfigs(configFiles, &config)
+		applyFlags(generateCmd, &config)
+		if !config.Stream {
+			t.Fatalf("%v: streaming not enabled", test.args)
+		}
+
+		var args []string
+		if test.stdin {
+			args = []string{"-"}
+		}
+		if got, ok := streamOutput(config, args).(*os.File); !ok || got != test.want {
+			t.Errorf("%v: streamed to %v, want %s", test.args, got.Name(), test.want.Name())
+		}
+	}
+}
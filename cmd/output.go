@@ -0,0 +1,326 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/brendan-sadlier/docura/internal/diff"
+	"github.com/brendan-sadlier/docura/internal/logging"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// docWriter receives every generated documentation file.
+type docWriter interface {
+	Write(path string, content string) error
+}
+
+// fileWriter writes documentation to disk. Each file is written to a temporary
+// file and renamed into place, so an interrupted run never leaves a partial
+// file and concurrent writes to the same path can't interleave.
+type fileWriter struct{}
+
+func (fileWriter) Write(path string, content string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("writing documentation: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing documentation: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing documentation: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("writing documentation: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("writing documentation: %w", err)
+	}
+
+	return nil
+}
+
+// stdoutDir is the output directory that prints documentation to standard
+// output instead of writing files.
+const stdoutDir = "-"
+
+// streamWriter prints every document to w, one after another, separated by a
+// horizontal rule.
+type streamWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	started bool
+}
+
+func (s *streamWriter) Write(path string, content string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		if _, err := io.WriteString(s.w, "\n---\n\n"); err != nil {
+			return fmt.Errorf("writing documentation: %w", err)
+		}
+	}
+	s.started = true
+
+	if _, err := io.WriteString(s.w, content); err != nil {
+		return fmt.Errorf("writing documentation: %w", err)
+	}
+	return nil
+}
+
+// checkWriter compares generated documentation with the files already on
+// disk, printing to w a unified diff for each that is missing or out of date.
+type checkWriter struct {
+	mu    sync.Mutex
+	w     io.Writer
+	stale []string
+}
+
+func (w *checkWriter) Write(path string, content string) error {
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("reading existing documentation: %w", err)
+	}
+
+	if patch := diff.Unified(path, path+" (generated)", string(existing), content); patch != "" {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		fmt.Fprint(w.w, patch)
+		w.stale = append(w.stale, path)
+	}
+
+	return nil
+}
+
+func (w *checkWriter) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.stale) > 0 {
+		return fmt.Errorf("documentation is out of date: %d files differ from the source", len(w.stale))
+	}
+	return nil
+}
+
+// manifestName is the file in the output directory listing the files docura
+// wrote there, so --clean only ever removes generated documentation.
+const manifestName = ".docura-manifest.json"
+
+// manifest records every file docura generated in an output directory.
+type manifest struct {
+	Files []manifestEntry `json:"files"`
+}
+
+type manifestEntry struct {
+	File    string `json:"file"`              // relative to the output directory, slash separated
+	Package string `json:"package,omitempty"` // documented package directory relative to the project
+	Hash    string `json:"hash"`              // SHA-256 of the generated content
+}
+
+// sourceRecorder is implemented by writers that note which package each
+// file documents, and which packages couldn't be documented.
+type sourceRecorder interface {
+	recordSource(path string, pkgDir string)
+	recordFailure(pkgDir string)
+}
+
+// recordSource tells out, if it keeps a manifest, that path documents the
+// package in pkgDir.
+func recordSource(out docWriter, path string, pkgDir string) {
+	if r, ok := out.(sourceRecorder); ok {
+		r.recordSource(path, pkgDir)
+	}
+}
+
+// recordFailure tells out, if it keeps a manifest, that the package in dir
+// couldn't be documented, so its earlier documentation is kept.
+func recordFailure(out docWriter, projectDir string, dir string) {
+	r, ok := out.(sourceRecorder)
+	if !ok {
+		return
+	}
+	if relDir, err := filepath.Rel(projectDir, dir); err == nil {
+		r.recordFailure(relDir)
+	}
+}
+
+// manifestWriter records every file written through it in the output
+// directory's manifest, warning about generated files edited since the
+// previous run.
+type manifestWriter struct {
+	docWriter
+	outputDir string
+	previous  map[string]manifestEntry
+
+	mu      sync.Mutex
+	written map[string]manifestEntry
+	failed  map[string]bool // package directories that couldn't be documented
+}
+
+func newManifestWriter(out docWriter, outputDir string) (*manifestWriter, error) {
+	previous, err := readManifest(filepath.Join(outputDir, manifestName))
+	if err != nil {
+		return nil, err
+	}
+
+	w := &manifestWriter{
+		docWriter: out,
+		outputDir: outputDir,
+		previous:  make(map[string]manifestEntry),
+		written:   make(map[string]manifestEntry),
+		failed:    make(map[string]bool),
+	}
+	for _, entry := range previous.Files {
+		w.previous[entry.File] = entry
+	}
+	return w, nil
+}
+
+func (w *manifestWriter) Write(path string, content string) error {
+	rel, ok := w.rel(path)
+	if entry, tracked := w.previous[rel]; ok && tracked {
+		if existing, err := os.ReadFile(path); err == nil && hashContent(string(existing)) != entry.Hash {
+			logging.Warnf("%s was edited since it was generated, overwriting it", path)
+		}
+	}
+
+	if err := w.docWriter.Write(path, content); err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	entry := w.written[rel]
+	entry.File = rel
+	entry.Hash = hashContent(content)
+	w.written[rel] = entry
+	return nil
+}
+
+func (w *manifestWriter) recordSource(path string, pkgDir string) {
+	rel, ok := w.rel(path)
+	if !ok {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	entry := w.written[rel]
+	entry.File = rel
+	entry.Package = filepath.ToSlash(pkgDir)
+	w.written[rel] = entry
+}
+
+func (w *manifestWriter) recordFailure(pkgDir string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.failed[filepath.ToSlash(pkgDir)] = true
+}
+
+// rel returns path relative to the output directory, slash separated.
+func (w *manifestWriter) rel(path string) (string, bool) {
+	rel, err := filepath.Rel(w.outputDir, path)
+	if err != nil || !filepath.IsLocal(rel) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// writeManifest saves the manifest for this run. Files generated by an
+// earlier run but not this one are removed when clean is set, and otherwise
+// kept in the manifest so a later --clean can find them. A partial run,
+// documenting only some packages, never removes anything, and neither does
+// a package that failed to document this run lose its earlier files.
+func (w *manifestWriter) writeManifest(clean bool, partial bool) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var m manifest
+	for _, entry := range w.written {
+		m.Files = append(m.Files, entry)
+	}
+
+	for rel, entry := range w.previous {
+		if _, ok := w.written[rel]; ok || !filepath.IsLocal(filepath.FromSlash(rel)) {
+			continue
+		}
+		if entry.Package != "" && w.failed[entry.Package] {
+			m.Files = append(m.Files, entry)
+			continue
+		}
+		if clean && !partial {
+			if err := removeStale(w.outputDir, filepath.FromSlash(rel)); err != nil {
+				return err
+			}
+			continue
+		}
+		if !partial {
+			logging.Warnf("%s is no longer generated, run with --clean to remove it", filepath.Join(w.outputDir, rel))
+		}
+		m.Files = append(m.Files, entry)
+	}
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].File < m.Files[j].File })
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding manifest: %w", err)
+	}
+	return w.docWriter.Write(filepath.Join(w.outputDir, manifestName), string(data)+"\n")
+}
+
+func readManifest(path string) (manifest, error) {
+	var m manifest
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return m, fmt.Errorf("reading manifest: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("parsing manifest %s: %w", path, err)
+	}
+	return m, nil
+}
+
+func hashContent(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// removeStale deletes a file left over from an earlier run, along with any
+// directories below outputDir that it leaves empty.
+func removeStale(outputDir string, rel string) error {
+	path := filepath.Join(outputDir, rel)
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing stale documentation: %w", err)
+	}
+	logging.Infof("Removed stale documentation: %s", path)
+
+	for dir := filepath.Dir(rel); dir != "."; dir = filepath.Dir(dir) {
+		if os.Remove(filepath.Join(outputDir, dir)) != nil {
+			break
+		}
+	}
+	return nil
+}
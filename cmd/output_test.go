@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"github.com/brendan-sadlier/docura/internal/generator"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCheckReportsDrift(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"store/store.go": "package store\n\n// Put stores a value.\nfunc Put(v string) {}\n",
+	})
+	out := t.TempDir()
+	if err := runGenerateArgs(t, "-d", dir, "-o", out, "--offline"); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	config := generator.DefaultConfig()
+	config.Offline = true
+	config.OutputDir = out
+	docGenerator, err := generator.NewDocGeneratorWithModel(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := func() (string, error) {
+		var report bytes.Buffer
+		w := &checkWriter{w: &report}
+		if err := generateDocs(analyser.NewAnalyser(), docGenerator, w, dir, config, ""); err != nil {
+			t.Fatalf("generateDocs: %v", err)
+		}
+		return report.String(), w.Err()
+	}
+
+	if report, err := check(); err != nil || report != "" {
+		t.Fatalf("fresh docs reported as out of date: %v\n%s", err, report)
+	}
+
+	source := filepath.Join(dir, "store", "store.go")
+	if err := os.WriteFile(source, []byte("package store\n\n// Put stores a value durably.\nfunc Put(v string) {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	report, err := check()
+	if err == nil {
+		t.Fatal("--check passed after a doc comment changed")
+	}
+	if !strings.Contains(report, "-Put stores a value.") || !strings.Contains(report, "+Put stores a value durably.") {
+		t.Errorf("diff doesn't show the changed comment:\n%s", report)
+	}
+
+	written, err := os.ReadFile(filepath.Join(out, "store.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(written), "durably") {
+		t.Error("--check overwrote the docs")
+	}
+}
+
+func TestFileWriterLeavesNoPartialFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	// A directory in the way of the rename fails the write after the
+	// content is written, as an interruption would
+	blocked := filepath.Join(dir, "blocked.md")
+	if err := os.MkdirAll(filepath.Join(blocked, "child"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := (fileWriter{}).Write(blocked, "new docs\n"); err == nil {
+		t.Fatal("write over a directory succeeded")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			t.Errorf("temporary file %s left behind", entry.Name())
+		}
+	}
+}
+
+func TestFileWriterConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "docs", "store.md")
+	contents := make([]string, 8)
+	for i := range contents {
+		contents[i] = strings.Repeat(string(rune('a'+i)), 64*1024)
+	}
+
+	var wg sync.WaitGroup
+	for _, content := range contents {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := (fileWriter{}).Write(path, content); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(contents, string(written)) {
+		t.Error("concurrent writes interleaved")
+	}
+}
+
+func TestCleanRemovesStaleFiles(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"store/store.go": "package store\n\n// Put puts.\nfunc Put() {}\n",
+		"cache/cache.go": "package cache\n\n// Get gets.\nfunc Get() {}\n",
+		"queue/queue.go": "package queue\n\n// Push pushes.\nfunc Push() {}\n",
+	})
+	out := t.TempDir()
+	if err := runGenerateArgs(t, "-d", dir, "-o", out, "--offline"); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	handWritten := filepath.Join(out, "notes.md")
+	if err := os.WriteFile(handWritten, []byte("# Notes\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// cache is deleted and queue no longer parses
+	if err := os.RemoveAll(filepath.Join(dir, "cache")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "queue", "queue.go"), []byte("package queue\n\nfunc Push( {\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runGenerateArgs(t, "-d", dir, "-o", out, "--clean", "--offline"); err != nil {
+		t.Fatalf("generate --clean: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "cache.md")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("stale cache.md not removed: %v", err)
+	}
+	assertFiles(t, out, "store.md", "index.md", "notes.md", "queue.md")
+
+	m, err := readManifest(filepath.Join(out, manifestName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []string
+	for _, entry := range m.Files {
+		files = append(files, entry.File)
+	}
+	if strings.Join(files, ",") != "index.md,queue.md,store.md" {
+		t.Errorf("got manifest files %v, want the failed package's docs kept", files)
+	}
+}
+
+func TestManifest(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"store/store.go": "package store\n\n// Store holds values.\ntype Store struct{}\n",
+		"cache/cache.go": "package cache\n\n// Get gets.\nfunc Get() {}\n",
+	})
+	out := t.TempDir()
+	generate := func() map[string]manifestEntry {
+		t.Helper()
+		if err := runGenerateArgs(t, "-d", dir, "-o", out, "--split-types", "--offline"); err != nil {
+			t.Fatalf("generate: %v", err)
+		}
+		m, err := readManifest(filepath.Join(out, manifestName))
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries := make(map[string]manifestEntry)
+		for _, entry := range m.Files {
+			entries[entry.File] = entry
+		}
+		return entries
+	}
+
+	first := generate()
+	want := map[string]string{"index.md": "", "store.md": "store", "store/Store.md": "store", "cache.md": "cache"}
+	if len(first) != len(want) {
+		t.Errorf("got manifest %+v, want %v", first, want)
+	}
+	for file, pkg := range want {
+		entry, ok := first[file]
+		if !ok || entry.Package != pkg || len(entry.Hash) != 64 {
+			t.Errorf("manifest entry for %s: got %+v, want package %q with a hash", file, entry, pkg)
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(out, filepath.FromSlash(file)))
+		if err != nil || hashContent(string(data)) != entry.Hash {
+			t.Errorf("%s hash doesn't match its content: %v", file, err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "cache", "cache.go"), []byte("package cache\n\n// Get fetches a value.\nfunc Get() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	second := generate()
+	if second["cache.md"].Hash == first["cache.md"].Hash {
+		t.Error("cache.md hash unchanged after its source changed")
+	}
+	if second["store.md"].Hash != first["store.md"].Hash {
+		t.Error("store.md hash changed though its source didn't")
+	}
+}
+
+func TestStreamWriter(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"store/store.go": "package store\n\n// Put puts.\nfunc Put() {}\n",
+		"cache/cache.go": "package cache\n\n// Get gets.\nfunc Get() {}\n",
+	})
+	dg, err := generator.NewDocGeneratorWithModel(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := generator.DefaultConfig()
+	config.Offline = true
+	config.OutputDir = stdoutDir
+
+	var buf bytes.Buffer
+	if err := generateDocs(analyser.NewAnalyser(), dg, &streamWriter{w: &buf}, dir, config, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	pages := strings.Split(buf.String(), "\n---\n\n")
+	if len(pages) != 2 || !strings.HasPrefix(pages[0], "# cache\n") || !strings.HasPrefix(pages[1], "# store\n") {
+		t.Errorf("want the cache and store pages separated by ---, without an index:\n%s", buf.String())
+	}
+	if _, err := os.Stat(stdoutDir); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("a %q file or directory was created: %v", stdoutDir, err)
+	}
+}
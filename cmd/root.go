@@ -1,14 +1,34 @@
 package cmd
 
 import (
+	"github.com/brendan-sadlier/docura/internal/logging"
 	"github.com/spf13/cobra"
 	"log"
 )
 
+var (
+	quiet   bool
+	verbose bool
+)
+
 var rootCmd = &cobra.Command{
 	Use:   "docura",
 	Short: "Docura is an AI powered documentation generator",
 	Long:  `Docura is an AI powered documentation generator.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		switch {
+		case quiet:
+			logging.SetLevel(logging.LevelError)
+		case verbose:
+			logging.SetLevel(logging.LevelDebug)
+		}
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Only report errors")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Report debug output")
+	rootCmd.MarkFlagsMutuallyExclusive("quiet", "verbose")
 }
 
 func Execute() {
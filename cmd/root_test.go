@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"bytes"
+	"github.com/brendan-sadlier/docura/internal/logging"
+	"os"
+	"testing"
+)
+
+func TestQuiet(t *testing.T) {
+	var out, errOut bytes.Buffer
+	logging.SetOutput(&out, &errOut)
+	logging.SetLevel(logging.LevelInfo)
+	t.Cleanup(func() {
+		logging.SetOutput(os.Stdout, os.Stderr)
+		logging.SetLevel(logging.LevelError)
+		quiet = false
+	})
+
+	if err := rootCmd.PersistentFlags().Parse([]string{"--quiet"}); err != nil {
+		t.Fatal(err)
+	}
+	rootCmd.PersistentPreRun(rootCmd, nil)
+
+	logging.Infof("Analyzing package: store")
+	logging.Errorf("Error documenting package store")
+	if out.Len() > 0 {
+		t.Errorf("--quiet kept info lines: %q", out.String())
+	}
+	if errOut.Len() == 0 {
+		t.Error("--quiet suppressed errors")
+	}
+}
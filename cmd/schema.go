@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/brendan-sadlier/docura/internal/generator"
+	"github.com/spf13/cobra"
+	"log"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "print the config file JSON schema",
+	Long:  `print a JSON Schema describing the configuration file, for editor autocomplete and validation`,
+	Run: func(cmd *cobra.Command, args []string) {
+		schema, err := generator.ConfigSchema()
+		if err != nil {
+			log.Fatalf("generating schema failed: %v", err)
+		}
+		fmt.Println(string(schema))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"github.com/brendan-sadlier/docura/internal/generator"
+	"github.com/brendan-sadlier/docura/internal/logging"
+	"github.com/spf13/cobra"
+	"html/template"
+	"log"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var servePort int
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "serve documentation over HTTP",
+	Long:  `analyse the project and serve its HTML documentation locally, regenerating pages on every request`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runServe(cmd); err != nil {
+			log.Fatalf("serve failed: %v", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVarP(&projectDir, "directory", "d", "", "Project directory to generate documentation")
+	serveCmd.Flags().StringArrayVarP(&configFiles, "config", "c", nil, "Configuration file in JSON format. Repeat to layer files, later ones overriding the settings they give")
+	serveCmd.Flags().BoolVar(&offline, "offline", false, "Generate documentation from source comments only, without calling the LLM")
+	serveCmd.Flags().IntVar(&servePort, "port", 8080, "Port to serve documentation on")
+}
+
+func runServe(cmd *cobra.Command) error {
+	config := generator.DefaultConfig()
+	loadConfigs(configFiles, &config)
+	applyFlags(cmd, &config)
+	config.Style = "html"
+	config.OutputExt = ""
+	config.OutputDir = ""
+
+	analyserInstance, err := newAnalyser(projectDir, config)
+	if err != nil {
+		return err
+	}
+
+	var docGenerator *generator.DocGenerator
+	if config.Offline {
+		docGenerator, err = generator.NewDocGeneratorWithModel(nil)
+	} else {
+		docGenerator, err = generator.NewDocGenerator()
+	}
+	if err != nil {
+		return fmt.Errorf("creating document generator: %w", err)
+	}
+
+	addr := fmt.Sprintf("localhost:%d", servePort)
+	logging.Infof("Serving documentation for %s on http://%s", projectDir, addr)
+
+	return http.ListenAndServe(addr, docsHandler(analyserInstance, docGenerator, projectDir, config))
+}
+
+// docsHandler regenerates the project's documentation for every request and
+// serves the requested page, or a list of pages at the root.
+func docsHandler(analyserInstance *analyser.Analyser, docGenerator *generator.DocGenerator, projectDir string, config generator.DocConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		out := &memoryWriter{files: make(map[string]string)}
+		if err := generateDocs(analyserInstance, docGenerator, out, projectDir, config, ""); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		page := strings.TrimPrefix(r.URL.Path, "/")
+		if page == "" {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			if err := pageListTemplate.Execute(w, out.pages()); err != nil {
+				logging.Errorf("Rendering page list: %v", err)
+			}
+			return
+		}
+
+		doc, ok := out.files[page]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, doc)
+	})
+}
+
+var pageListTemplate = template.Must(template.New("pages").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>Documentation</title></head>
+<body>
+<h1>Packages</h1>
+<ul>
+{{range .}}<li><a href="/{{.}}">{{.}}</a></li>
+{{end}}</ul>
+</body>
+</html>
+`))
+
+// memoryWriter keeps generated documentation in memory, keyed by its
+// slash-separated path.
+type memoryWriter struct {
+	mu    sync.Mutex
+	files map[string]string
+}
+
+func (w *memoryWriter) Write(path string, content string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.files[filepath.ToSlash(path)] = content
+	return nil
+}
+
+// pages returns the paths of the generated HTML pages, sorted.
+func (w *memoryWriter) pages() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var pages []string
+	for path := range w.files {
+		if strings.HasSuffix(path, ".html") {
+			pages = append(pages, path)
+		}
+	}
+	sort.Strings(pages)
+	return pages
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"github.com/brendan-sadlier/docura/internal/generator"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDocsHandler(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"store/store.go": "package store\n\n// Put stores a value.\nfunc Put(v string) {}\n",
+	})
+	dg, err := generator.NewDocGeneratorWithModel(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := generator.DefaultConfig()
+	config.Offline = true
+	config.Style = "html"
+	config.OutputDir = ""
+
+	server := httptest.NewServer(docsHandler(analyser.NewAnalyser(), dg, dir, config))
+	defer server.Close()
+
+	get := func(path string) (int, string) {
+		t.Helper()
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, string(body)
+	}
+
+	if status, body := get("/"); status != http.StatusOK || !strings.Contains(body, `href="/store.html"`) {
+		t.Errorf("page list: status %d\n%s", status, body)
+	}
+	if status, body := get("/store.html"); status != http.StatusOK || !strings.Contains(body, "Put stores a value.") {
+		t.Errorf("package page: status %d\n%s", status, body)
+	}
+	if status, _ := get("/missing.html"); status != http.StatusNotFound {
+		t.Errorf("missing page: got status %d, want 404", status)
+	}
+
+	// Pages are regenerated on every request
+	if err := os.WriteFile(filepath.Join(dir, "store", "store.go"), []byte("package store\n\n// Get fetches a value.\nfunc Get() string { return \"\" }\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, body := get("/store.html"); !strings.Contains(body, "Get fetches a value.") {
+		t.Errorf("page not regenerated after a change:\n%s", body)
+	}
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"github.com/brendan-sadlier/docura/internal/generator"
+	"github.com/brendan-sadlier/docura/internal/logging"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// stdinFile is the name given to source read from standard input.
+const stdinFile = "stdin.go"
+
+// generateFromStdin documents the Go source read from in as a single-file
+// package, writing its documentation to out. Log messages go to stderr so
+// they don't mix with the documentation.
+func generateFromStdin(in io.Reader, out io.Writer, analyserInstance *analyser.Analyser, docGenerator *generator.DocGenerator, config generator.DocConfig) error {
+	logging.SetOutput(os.Stderr, os.Stderr)
+
+	src, err := io.ReadAll(in)
+	if err != nil {
+		return fmt.Errorf("reading standard input: %w", err)
+	}
+
+	pkg, err := analyserInstance.AnalysePackageFS(stdinFS(src), ".")
+	if err != nil {
+		return fmt.Errorf("analyzing standard input: %w", err)
+	}
+
+	// There's no directory to show, so the package goes by its name
+	pkg.Path = pkg.Name
+
+	// Everything goes in one document, so types can't be split out
+	config.SplitTypes = false
+	config.Packages = []string{pkg.Name}
+
+	doc, err := docGenerator.GeneratePackageDoc(pkg, config)
+	if err != nil {
+		return fmt.Errorf("generating documentation: %w", err)
+	}
+
+	if _, err := io.WriteString(out, doc); err != nil {
+		return fmt.Errorf("writing documentation: %w", err)
+	}
+	return nil
+}
+
+// stdinFS is a file system holding only the source read from standard input,
+// as stdinFile in its root.
+type stdinFS []byte
+
+func (f stdinFS) Open(name string) (fs.File, error) {
+	switch name {
+	case ".":
+		file := stdinInfo{name: stdinFile, size: int64(len(f))}
+		return &stdinDir{entries: []fs.DirEntry{fs.FileInfoToDirEntry(file)}}, nil
+	case stdinFile:
+		return &stdinSource{Reader: bytes.NewReader(f)}, nil
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// stdinSource is stdinFile opened for reading.
+type stdinSource struct {
+	*bytes.Reader
+}
+
+func (s *stdinSource) Stat() (fs.FileInfo, error) {
+	return stdinInfo{name: stdinFile, size: s.Size()}, nil
+}
+
+func (s *stdinSource) Close() error { return nil }
+
+// stdinDir is the root of stdinFS opened for listing.
+type stdinDir struct {
+	entries []fs.DirEntry
+}
+
+func (d *stdinDir) Stat() (fs.FileInfo, error) {
+	return stdinInfo{name: ".", dir: true}, nil
+}
+
+func (d *stdinDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: ".", Err: fs.ErrInvalid}
+}
+
+func (d *stdinDir) Close() error { return nil }
+
+func (d *stdinDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(d.entries))
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
+
+type stdinInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i stdinInfo) Name() string       { return i.name }
+func (i stdinInfo) Size() int64        { return i.size }
+func (i stdinInfo) ModTime() time.Time { return time.Time{} }
+func (i stdinInfo) IsDir() bool        { return i.dir }
+func (i stdinInfo) Sys() any           { return nil }
+
+func (i stdinInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
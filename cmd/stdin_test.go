@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"bytes"
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"github.com/brendan-sadlier/docura/internal/generator"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestGenerateFromStdin(t *testing.T) {
+	dg, err := generator.NewDocGeneratorWithModel(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := generator.DefaultConfig()
+	config.Offline = true
+
+	src := "// Package greet says hello.\npackage greet\n\n// Hello returns a greeting.\nfunc Hello(name string) string { return \"hello \" + name }\n"
+	var out bytes.Buffer
+	if err := generateFromStdin(strings.NewReader(src), &out, analyser.NewAnalyser(), dg, config); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# greet", "Hello returns a greeting.", "func Hello(name string) string"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	if err := generateFromStdin(strings.NewReader("not go"), &out, analyser.NewAnalyser(), dg, config); err == nil {
+		t.Error("invalid source from standard input succeeded")
+	}
+}
+
+func TestStdinFS(t *testing.T) {
+	src := []byte("package p\n")
+	if err := fstest.TestFS(stdinFS(src), stdinFile); err != nil {
+		t.Fatal(err)
+	}
+	data, err := fs.ReadFile(stdinFS(src), stdinFile)
+	if err != nil || !bytes.Equal(data, src) {
+		t.Errorf("got %q, %v", data, err)
+	}
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"github.com/brendan-sadlier/docura/internal/generator"
+	"github.com/brendan-sadlier/docura/internal/logging"
+)
+
+// writeBackDocs enhances the descriptions of the selected packages and
+// writes them into their source as doc comments, in place of generating
+// documentation.
+func writeBackDocs(analyserInstance *analyser.Analyser, docGenerator *generator.DocGenerator, projectDir string, packageName string, config generator.DocConfig) error {
+	if config.Offline {
+		logging.Warnf("Nothing to write back with --offline, as no descriptions are generated")
+		return nil
+	}
+
+	dirs, err := packageDirs(projectDir, packageName, analyserInstance.Ignore)
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range dirs {
+		pkg, err := analysePackage(analyserInstance, dir)
+		if errors.Is(err, analyser.ErrTestOnlyPackage) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := docGenerator.EnhanceDescriptions(context.Background(), pkg, config); err != nil {
+			logging.Warnf("Could not enhance descriptions for %s: %v", pkg.Name, err)
+		}
+
+		files, err := analyser.WriteBack(pkg, generator.BriefDescription)
+		if err != nil {
+			return fmt.Errorf("writing back docs for %s: %w", dir, err)
+		}
+		for _, file := range files {
+			logging.Infof("Wrote doc comments: %s", file)
+		}
+	}
+
+	return nil
+}
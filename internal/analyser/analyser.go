@@ -1,28 +1,88 @@
 package analyser
 
 import (
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/doc"
 	"go/parser"
+	"go/printer"
 	"go/token"
+	"go/types"
+	"io/fs"
+	"path/filepath"
+	"slices"
 	"strings"
 )
 
+// Analyser extracts documentation from Go packages. It is safe for
+// concurrent use: each package is parsed into its own FileSet.
 type Analyser struct {
+	// fset holds the files of the package being analysed, set by forPackage
 	fset *token.FileSet
+
+	// cache holds analysed packages by directory, so unchanged packages
+	// aren't parsed again in watch mode
+	cache *packageCache
+
+	// MarkdownExamples attaches the Go code blocks from a package's README.md
+	// or examples.md to its examples
+	MarkdownExamples bool
+	// MergeReadme adds the body of a package's README.md to its description
+	MergeReadme bool
+	// HTTPRoutes looks for HTTP handlers and the routes they are registered on
+	HTTPRoutes bool
+	// Implements lists the package's interfaces each type satisfies
+	Implements bool
+	// TypeCheck resolves the types of constants and variables with go/types,
+	// and with Implements the interfaces each type satisfies. Dependencies
+	// are loaded from the export data go list builds, so it is slower and
+	// needs the package and its dependencies to compile. Everything else is
+	// still read from the syntax
+	TypeCheck bool
+	// PrivateFields keeps the unexported fields of structs, which are
+	// otherwise left out
+	PrivateFields bool
+	// Unexported also analyses the package's unexported functions, methods
+	// and types, kept apart from its API in UnexportedFunctions and
+	// UnexportedTypes
+	Unexported bool
+	// Ignore leaves out the files matched by a project's .docuraignore
+	Ignore *IgnoreRules
+
+	// fsys, when set, is read by AnalysePackage instead of the OS file system
+	fsys fs.FS
+
+	// tests, buildTags and mode are set by the Options passed to NewAnalyser
+	tests     bool
+	buildTags []string
+	mode      parser.Mode
 }
 
 type PackageInfo struct {
 	Name        string         `json:"name"`
 	Path        string         `json:"path"`
+	ImportPath  string         `json:"import_path,omitempty"`
+	Module      string         `json:"module,omitempty"`  // path of the enclosing module, from its go.mod
+	IsInternal  bool           `json:"is_internal"`       // importable only from within its module
+	IsCommand   bool           `json:"is_command"`        // package main, built as a command rather than imported
+	Command     string         `json:"command,omitempty"` // name of the built command, for main packages
+	Flags       []FlagInfo     `json:"flags,omitempty"`   // command line flags, for main packages
+	Routes      []RouteInfo    `json:"routes,omitempty"`  // HTTP routes, when HTTPRoutes is set
 	Description string         `json:"description"`
+	Summary     string         `json:"summary,omitempty"`  // one sentence summary, the first sentence of the package doc
+	Overview    string         `json:"overview,omitempty"` // package doc with its paragraphs kept, when it has several
 	Functions   []FunctionInfo `json:"functions"`
 	Types       []TypeInfo     `json:"types"`
 	Constants   []ConstantInfo `json:"constants"`
 	Variables   []VariableInfo `json:"variables"`
 	Examples    []ExampleInfo  `json:"examples"`
+	Readme      string         `json:"readme,omitempty"` // README.md body not already in the package doc, when MergeReadme is set
 	Imports     []string       `json:"imports"`
+	Warnings    []string       `json:"warnings,omitempty"` // non-fatal problems found during analysis
+
+	UnexportedFunctions []FunctionInfo `json:"unexported_functions,omitempty"` // when Unexported is set
+	UnexportedTypes     []TypeInfo     `json:"unexported_types,omitempty"`
 }
 
 type FunctionInfo struct {
@@ -35,15 +95,52 @@ type FunctionInfo struct {
 	IsExported  bool         `json:"is_exported"`
 	IsMethod    bool         `json:"is_method"`
 	Receiver    string       `json:"receiver,omitempty"`
+	// PointerReceiver is set for methods declared on *T rather than T
+	PointerReceiver bool   `json:"pointer_receiver,omitempty"`
+	Since           string `json:"since,omitempty"` // version the function was added, from a "Since:" doc line
+	File            string `json:"file,omitempty"`  // file the function is declared in, within its package directory
+	Line            int    `json:"line,omitempty"`
+	// IsTestHelper is set for functions taking a *testing.T, *testing.B,
+	// *testing.F or testing.TB, meant for use in tests
+	IsTestHelper bool `json:"is_test_helper,omitempty"`
+	// TypeParams holds a generic function's type parameters, with union
+	// constraints such as ~int | ~string as written
+	TypeParams []TypeParam `json:"type_params,omitempty"`
 }
 
 type TypeInfo struct {
 	Name        string      `json:"name"`
-	Kind        string      `json:"kind"` // e.g. struct, interface, alias, etc
+	Kind        string      `json:"kind"` // e.g. struct, interface, alias, defined, etc
 	Description string      `json:"description"`
+	Underlying  string      `json:"underlying,omitempty"` // aliased type for aliases, underlying type for other non-struct, non-interface types
 	Fields      []FieldInfo `json:"fields,omitempty"`
 	Methods     []string    `json:"methods,omitempty"`
-	IsExported  bool        `json:"is_exported"`
+	// Constructors names the package's New and Make functions returning the type
+	Constructors []string `json:"constructors,omitempty"`
+	// Options names the functional options, such as WithTimeout, that its
+	// constructors take
+	Options []string `json:"options,omitempty"`
+	// Implements names the package's interfaces the type or a pointer to it implements
+	Implements []string    `json:"implements,omitempty"`
+	TypeParams []TypeParam `json:"type_params,omitempty"`
+	TypeSet    []string    `json:"type_set,omitempty"` // embedded elements of an interface, e.g. ~int | ~string
+	// Embeds names the interfaces an interface embeds, e.g. Reader or io.Closer
+	Embeds []string `json:"embeds,omitempty"`
+	// MethodSet lists an interface's methods, including those of the
+	// package's interfaces it embeds
+	MethodSet  []string `json:"method_set,omitempty"`
+	Examples   []string `json:"examples,omitempty"`
+	IsExported bool     `json:"is_exported"`
+	IsAlias    bool     `json:"is_alias"`
+	Since      string   `json:"since,omitempty"` // version the type was added, from a "Since:" doc line
+	File       string   `json:"file,omitempty"`  // file the type is declared in, within its package directory
+	Line       int      `json:"line,omitempty"`
+}
+
+// TypeParam is a type parameter of a generic type and its constraint.
+type TypeParam struct {
+	Name       string `json:"name"`
+	Constraint string `json:"constraint"`
 }
 
 type FieldInfo struct {
@@ -51,6 +148,10 @@ type FieldInfo struct {
 	Type        string `json:"type"`
 	Tag         string `json:"tag,omitempty"`
 	Description string `json:"description"`
+	IsExported  bool   `json:"is_exported"`
+	// Default is the field's default, from a "default:" line in its comment
+	// or else the value the type's constructor gives it
+	Default string `json:"default,omitempty"`
 }
 
 type ParamInfo struct {
@@ -59,6 +160,7 @@ type ParamInfo struct {
 }
 
 type ReturnInfo struct {
+	Name        string `json:"name,omitempty"`
 	Type        string `json:"type"`
 	Description string `json:"description"`
 }
@@ -76,24 +178,138 @@ type VariableInfo struct {
 	Type        string `json:"type"`
 	Description string `json:"description"`
 	IsExported  bool   `json:"is_exported"`
+	Value       string `json:"value,omitempty"`
+	IsError     bool   `json:"is_error"` // a sentinel error such as ErrNotFound
+}
+
+type FlagInfo struct {
+	Name      string `json:"name"`
+	Shorthand string `json:"shorthand,omitempty"`
+	Type      string `json:"type"`
+	Default   string `json:"default"`
+	Usage     string `json:"usage"`
+}
+
+// RouteInfo is an HTTP route. Method and Path are empty for handlers that
+// aren't registered in the package.
+type RouteInfo struct {
+	Method  string `json:"method,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Handler string `json:"handler"`
 }
 
 type ExampleInfo struct {
-	Name string `json:"name"`
-	Code string `json:"code"`
-	Doc  string `json:"doc"`
+	Name   string `json:"name"`
+	Code   string `json:"code"`
+	Doc    string `json:"doc"`
+	Output string `json:"output,omitempty"` // expected output of a testable example
+	Symbol string `json:"symbol,omitempty"` // function, type or Type.Method a testable example is for
+	// Testable is set for Example functions from the package's tests
+	Testable bool `json:"testable,omitempty"`
 }
 
-func NewAnalyser() *Analyser {
-	return &Analyser{
-		fset: token.NewFileSet(),
+// NewAnalyser returns an Analyser that parses packages as opts configure it
+// to, by default reading every non-test file.
+func NewAnalyser(opts ...Option) *Analyser {
+	a := &Analyser{
+		cache: &packageCache{entries: make(map[string]cacheEntry)},
 	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
 }
 
+var (
+	// ErrTestOnlyPackage is returned by AnalysePackage for directories that
+	// only contain test code, which has no API worth documenting.
+	ErrTestOnlyPackage = errors.New("only test code found")
+	// ErrNoPackage is returned for directories that don't exist or have no
+	// Go files.
+	ErrNoPackage = errors.New("no Golang package found")
+	// ErrParse is returned when a package's directory can't be read or none
+	// of its files could be parsed.
+	ErrParse = errors.New("parsing package")
+	// ErrTypeCheck is returned when TypeCheck is set and the package doesn't
+	// type check.
+	ErrTypeCheck = errors.New("type checking package")
+)
+
 func (a *Analyser) AnalysePackage(dir string) (*PackageInfo, error) {
-	pkgs, err := parser.ParseDir(a.fset, dir, nil, parser.ParseComments)
+	src := source{fsys: a.fsys, dir: dir}
+	options := fmt.Sprintf("markdown=%t routes=%t implements=%t typecheck=%t private=%t readme=%t unexported=%t tests=%t tags=%v mode=%d", a.MarkdownExamples, a.HTTPRoutes, a.Implements, a.TypeCheck, a.PrivateFields, a.MergeReadme, a.Unexported, a.tests, a.buildTags, a.mode)
+	fingerprint, fingerprintErr := src.fingerprint(options, a.Ignore)
+	if fingerprintErr == nil {
+		if info, ok := a.cached(dir, fingerprint); ok {
+			return info, nil
+		}
+	}
+
+	info, err := a.analyse(src)
 	if err != nil {
-		return nil, fmt.Errorf("parsing package: %w", err)
+		return nil, err
+	}
+
+	if importPath, modulePath, err := resolveImportPath(dir); err == nil {
+		info.ImportPath = importPath
+		info.Module = modulePath
+		info.IsInternal = isInternalPath(importPath)
+	} else {
+		info.IsInternal = isInternalPath(filepath.ToSlash(dir))
+	}
+
+	if info.IsCommand {
+		info.Command = commandName(info.ImportPath, dir)
+	}
+
+	if fingerprintErr == nil {
+		a.store(dir, fingerprint, info)
+	}
+
+	return info, nil
+}
+
+// AnalysePackageFS analyses the package in dir, a slash-separated path within
+// fsys, so generated or virtual source can be documented without writing it
+// to disk. The import path is resolved from a go.mod within fsys.
+func (a *Analyser) AnalysePackageFS(fsys fs.FS, dir string) (*PackageInfo, error) {
+	info, err := a.analyse(source{fsys: fsys, dir: dir})
+	if err != nil {
+		return nil, err
+	}
+
+	if importPath, modulePath, err := resolveImportPathFS(fsys, dir); err == nil {
+		info.ImportPath = importPath
+		info.Module = modulePath
+		info.IsInternal = isInternalPath(importPath)
+	} else {
+		info.IsInternal = isInternalPath(dir)
+	}
+
+	if info.IsCommand {
+		info.Command = commandName(info.ImportPath, dir)
+	}
+
+	return info, nil
+}
+
+// forPackage returns a copy of a with a FileSet of its own, for analysing one
+// package without sharing positions with concurrent calls.
+func (a *Analyser) forPackage() *Analyser {
+	c := *a
+	c.fset = token.NewFileSet()
+	return &c
+}
+
+func (a *Analyser) analyse(src source) (*PackageInfo, error) {
+	a = a.forPackage()
+	dir := src.dir
+	pkgs, fileErrs, err := a.parsePackages(src)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w in %s: %w", ErrNoPackage, dir, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrParse, err)
 	}
 
 	var pkg *ast.Package
@@ -105,7 +321,49 @@ func (a *Analyser) AnalysePackage(dir string) (*PackageInfo, error) {
 	}
 
 	if pkg == nil {
-		return nil, fmt.Errorf("no Golang package found in %s", dir)
+		if len(fileErrs) > 0 {
+			return nil, fmt.Errorf("%w: %w", ErrParse, errors.Join(fileErrs...))
+		}
+		if len(pkgs) > 0 || src.hasTestFiles() {
+			return nil, fmt.Errorf("%w in %s", ErrTestOnlyPackage, dir)
+		}
+		return nil, fmt.Errorf("%w in %s", ErrNoPackage, dir)
+	}
+
+	// Collect function result types, directives and flags before doc.New filters the AST
+	scope := a.collectValueTypes(pkg)
+	dirs := collectDirectives(pkg)
+	interfaces := collectInterfaces(pkg)
+	defaults := a.collectConstructorDefaults(pkg)
+	var structFields map[string][]FieldInfo
+	if a.PrivateFields {
+		structFields = a.collectStructFields(pkg)
+	}
+
+	var flags []FlagInfo
+	if pkg.Name == "main" {
+		flags = a.extractFlags(pkg)
+	}
+	var routes []RouteInfo
+	if a.HTTPRoutes {
+		routes = a.extractRoutes(pkg)
+	}
+	var implements map[string][]string
+	if a.Implements && !a.TypeCheck {
+		implements = a.extractImplements(pkg)
+	}
+	var checked *types.Package
+	if a.TypeCheck && src.fsys == nil {
+		if checked, err = a.typeCheck(pkg, dir); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrTypeCheck, err)
+		}
+	}
+
+	// Analyse the unexported symbols first, as filtering the exports edits the AST
+	var unexported PackageInfo
+	if a.Unexported {
+		allDecls := doc.New(pkg, "./", doc.AllDecls|doc.PreserveAST)
+		unexported.Functions, unexported.Types = a.analyseUnexported(allDecls, dirs, interfaces, src, &unexported)
 	}
 
 	// Create Documentation
@@ -115,63 +373,160 @@ func (a *Analyser) AnalysePackage(dir string) (*PackageInfo, error) {
 		Path:        dir,
 		Description: cleanDoc(docPkg.Doc),
 		Imports:     a.extractImports(pkg),
+		IsCommand:   docPkg.Name == "main",
+		Flags:       flags,
+		Routes:      routes,
 	}
+	info.Summary = docPkg.Synopsis(docPkg.Doc)
+	info.Overview = packageOverview(docPkg.Doc)
+
+	// Files that failed to parse are left out rather than hiding the whole package
+	for _, fileErr := range fileErrs {
+		info.Warnings = append(info.Warnings, fmt.Sprintf("skipped file: %v", fileErr))
+	}
+
+	if a.MarkdownExamples {
+		info.Examples = extractMarkdownExamples(src)
+	}
+	if a.MergeReadme {
+		info.Readme = extractReadme(src, docPkg.Doc)
+	}
+	info.Examples = append(info.Examples, a.extractTestExamples(src)...)
 
 	// Analyse functions
 	for _, fn := range docPkg.Funcs {
-		fnInfo := a.analyseFunctionDecl(fn)
-		info.Functions = append(info.Functions, fnInfo)
+		fnInfo, ok := a.analyseFunctionDecl(fn, dirs[fn.Name], src, info)
+		if ok {
+			info.Functions = append(info.Functions, fnInfo)
+		}
 	}
 
 	// Analyse types
 	for _, typ := range docPkg.Types {
-		typeInfo := a.analyseTypeDecl(typ)
+		// go/doc groups functions returning the type, such as constructors, with it
+		for _, fn := range typ.Funcs {
+			fnInfo, ok := a.analyseFunctionDecl(fn, dirs[fn.Name], src, info)
+			if ok {
+				info.Functions = append(info.Functions, fnInfo)
+			}
+		}
+
+		typeInfo, ok := a.analyseTypeDecl(typ, dirs, interfaces, src, info)
+		if !ok {
+			continue
+		}
+		typeInfo.Implements = implements[typ.Name]
+		if fields, ok := structFields[typ.Name]; ok {
+			typeInfo.Fields = fields
+		}
 		info.Types = append(info.Types, typeInfo)
 
 		// Add methods to functions list
 		for _, method := range typ.Methods {
-			methodInfo := a.analyseFunctionDecl(method)
+			methodInfo, ok := a.analyseFunctionDecl(method, dirs[typ.Name+"."+method.Name], src, info)
+			if !ok {
+				continue
+			}
 			methodInfo.IsMethod = true
 			methodInfo.Receiver = typ.Name
+			methodInfo.PointerReceiver = isPointerReceiver(method.Decl.Recv)
 			info.Functions = append(info.Functions, methodInfo)
 		}
 	}
 
 	// Analyse constants and variables
 	for _, c := range docPkg.Consts {
-		constInfo := a.analyseConstantDecl(c)
+		constInfo := a.analyseConstantDecl(c, scope)
 		info.Constants = append(info.Constants, constInfo...)
 	}
 
 	for _, v := range docPkg.Vars {
-		varInfo := a.analyseVariableDecl(v)
+		varInfo := a.analyseVariableDecl(v, scope)
 		info.Variables = append(info.Variables, varInfo...)
 	}
 
+	linkConstructors(info)
+	linkOptions(info)
+	applyDefaults(info, defaults)
+	info.UnexportedFunctions, info.UnexportedTypes = unexported.Functions, unexported.Types
+	info.Warnings = append(info.Warnings, unexported.Warnings...)
+	if checked != nil {
+		a.applyTypes(info, checked)
+	}
+
 	return info, nil
 }
 
-func (a *Analyser) analyseFunctionDecl(fn *doc.Func) FunctionInfo {
+// takesTesting reports whether any of params is a test, benchmark or fuzz
+// handle from the testing package.
+func takesTesting(params []ParamInfo) bool {
+	for _, p := range params {
+		switch p.Type {
+		case "*testing.T", "*testing.B", "*testing.F", "testing.TB":
+			return true
+		}
+	}
+	return false
+}
+
+// position returns the base name of the file node is in and its line.
+func (a *Analyser) position(node ast.Node) (string, int) {
+	pos := a.fset.Position(node.Pos())
+	if !pos.IsValid() {
+		return "", 0
+	}
+	return filepath.Base(pos.Filename), pos.Line
+}
+
+// analyseFunctionDecl returns false if the function is hidden by a directive.
+func (a *Analyser) analyseFunctionDecl(fn *doc.Func, d directives, src source, pkg *PackageInfo) (FunctionInfo, bool) {
+	if d.hidden {
+		return FunctionInfo{}, false
+	}
+
 	info := FunctionInfo{
 		Name:        fn.Name,
-		Description: cleanDoc(fn.Doc),
+		Description: cleanDoc(stripSince(fn.Doc)),
+		Since:       extractSince(fn.Doc),
 		IsExported:  ast.IsExported(fn.Name),
 		Examples:    a.extractExamples(fn.Doc),
 	}
 
+	if fn.Decl != nil {
+		info.File, info.Line = a.position(fn.Decl.Name)
+	}
+
 	if fn.Decl != nil && fn.Decl.Type != nil {
 		info.Signature = a.getFunctionSignature(fn.Decl)
 		info.Parameters = a.extractParameters(fn.Decl.Type.Params)
+		info.TypeParams = a.extractTypeParams(fn.Decl.Type.TypeParams)
+		info.IsTestHelper = takesTesting(info.Parameters)
 		info.Returns = a.extractReturns(fn.Decl.Type.Results)
 	}
 
-	return info
+	if d.example != "" {
+		code, err := src.readExample(d.example)
+		if err != nil {
+			pkg.Warnings = append(pkg.Warnings, fmt.Sprintf("%s: %v", fn.Name, err))
+		} else {
+			info.Examples = append([]string{code}, info.Examples...)
+		}
+	}
+
+	return info, true
 }
 
-func (a *Analyser) analyseTypeDecl(typ *doc.Type) TypeInfo {
+// analyseTypeDecl returns false if the type is hidden by a directive.
+func (a *Analyser) analyseTypeDecl(typ *doc.Type, dirs map[string]directives, interfaces map[string]*ast.InterfaceType, src source, pkg *PackageInfo) (TypeInfo, bool) {
+	d := dirs[typ.Name]
+	if d.hidden {
+		return TypeInfo{}, false
+	}
+
 	info := TypeInfo{
 		Name:        typ.Name,
-		Description: cleanDoc(typ.Doc),
+		Description: cleanDoc(stripSince(typ.Doc)),
+		Since:       extractSince(typ.Doc),
 		IsExported:  ast.IsExported(typ.Name),
 	}
 
@@ -179,26 +534,69 @@ func (a *Analyser) analyseTypeDecl(typ *doc.Type) TypeInfo {
 		for _, spec := range typ.Decl.Specs {
 			if ts, ok := spec.(*ast.TypeSpec); ok {
 				info.Kind = a.getTypeKind(ts.Type)
+				info.File, info.Line = a.position(ts.Name)
+
+				// type A = B is a true alias, type A B is a new defined type
+				if ts.Assign.IsValid() {
+					info.Kind = "alias"
+					info.IsAlias = true
+				}
+				switch ts.Type.(type) {
+				case *ast.StructType, *ast.InterfaceType:
+				default:
+					info.Underlying = a.typeToString(ts.Type)
+				}
+
 				if structType, ok := ts.Type.(*ast.StructType); ok {
 					info.Fields = a.extractFields(structType)
 				}
+				if _, ok := ts.Type.(*ast.InterfaceType); ok {
+					info.Embeds, info.TypeSet, info.MethodSet = a.extractEmbeds(typ.Name, interfaces)
+				}
+				info.TypeParams = a.extractTypeParams(ts.TypeParams)
 			}
 		}
 	}
 
+	if d.kind != "" {
+		info.Kind = d.kind
+	}
+
+	if d.example != "" {
+		code, err := src.readExample(d.example)
+		if err != nil {
+			pkg.Warnings = append(pkg.Warnings, fmt.Sprintf("%s: %v", typ.Name, err))
+		} else {
+			info.Examples = append(info.Examples, code)
+		}
+	}
+
 	// Extract method names
 	for _, method := range typ.Methods {
+		if dirs[typ.Name+"."+method.Name].hidden {
+			continue
+		}
 		info.Methods = append(info.Methods, method.Name)
 	}
 
-	return info
+	return info, true
 }
 
-func (a *Analyser) analyseConstantDecl(c *doc.Value) []ConstantInfo {
+func (a *Analyser) analyseConstantDecl(c *doc.Value, scope valueScope) []ConstantInfo {
 	var constants []ConstantInfo
 
+	// Constant specs without a type or value repeat the previous spec's
+	var prevType ast.Expr
+	var prevValues []ast.Expr
+
 	for _, spec := range c.Decl.Specs {
 		if vs, ok := spec.(*ast.ValueSpec); ok {
+			typ, values := vs.Type, vs.Values
+			if typ == nil && len(values) == 0 {
+				typ, values = prevType, prevValues
+			}
+			prevType, prevValues = typ, values
+
 			for i, name := range vs.Names {
 				constInfo := ConstantInfo{
 					Name:        name.Name,
@@ -206,8 +604,10 @@ func (a *Analyser) analyseConstantDecl(c *doc.Value) []ConstantInfo {
 					IsExported:  ast.IsExported(name.Name),
 				}
 
-				if vs.Type != nil {
-					constInfo.Type = a.typeToString(vs.Type)
+				if typ != nil {
+					constInfo.Type = a.typeToString(typ)
+				} else if i < len(values) && values[i] != nil {
+					constInfo.Type = a.inferType(values[i], scope)
 				}
 
 				if i < len(vs.Values) && vs.Values[i] != nil {
@@ -222,21 +622,32 @@ func (a *Analyser) analyseConstantDecl(c *doc.Value) []ConstantInfo {
 	return constants
 }
 
-func (a *Analyser) analyseVariableDecl(v *doc.Value) []VariableInfo {
+func (a *Analyser) analyseVariableDecl(v *doc.Value, scope valueScope) []VariableInfo {
 	var variables []VariableInfo
 
 	for _, spec := range v.Decl.Specs {
 		if vs, ok := spec.(*ast.ValueSpec); ok {
-			for _, name := range vs.Names {
+			for i, name := range vs.Names {
 				varInfo := VariableInfo{
 					Name:        name.Name,
 					Description: cleanDoc(v.Doc),
 					IsExported:  ast.IsExported(name.Name),
 				}
 
+				// Sentinels are often declared in a group, each with its own comment
+				if vs.Doc != nil {
+					varInfo.Description = cleanDoc(vs.Doc.Text())
+				}
+
 				if vs.Type != nil {
 					varInfo.Type = a.typeToString(vs.Type)
+				} else if len(vs.Values) == len(vs.Names) {
+					varInfo.Type = a.inferType(vs.Values[i], scope)
 				}
+				if len(vs.Values) == len(vs.Names) {
+					varInfo.Value = a.exprToString(vs.Values[i])
+				}
+				varInfo.IsError = varInfo.Type == "error"
 
 				variables = append(variables, varInfo)
 			}
@@ -260,6 +671,7 @@ func (a *Analyser) extractImports(pkg *ast.Package) []string {
 	for imp := range importSet {
 		imports = append(imports, imp)
 	}
+	slices.Sort(imports)
 
 	return imports
 }
@@ -297,16 +709,38 @@ func (a *Analyser) extractReturns(fields *ast.FieldList) []ReturnInfo {
 		return nil
 	}
 
+	// Named results sharing a type, as in (x, y int), are still separate values
 	var returns []ReturnInfo
 	for _, field := range fields.List {
-		returns = append(returns, ReturnInfo{
-			Type: a.typeToString(field.Type),
-		})
+		resultType := a.typeToString(field.Type)
+		if len(field.Names) == 0 {
+			returns = append(returns, ReturnInfo{Type: resultType})
+			continue
+		}
+		for _, name := range field.Names {
+			returns = append(returns, ReturnInfo{Name: name.Name, Type: resultType})
+		}
 	}
 
 	return returns
 }
 
+func (a *Analyser) extractTypeParams(fields *ast.FieldList) []TypeParam {
+	if fields == nil {
+		return nil
+	}
+
+	var params []TypeParam
+	for _, field := range fields.List {
+		constraint := a.exprToString(field.Type)
+		for _, name := range field.Names {
+			params = append(params, TypeParam{Name: name.Name, Constraint: constraint})
+		}
+	}
+
+	return params
+}
+
 func (a *Analyser) extractFields(structType *ast.StructType) []FieldInfo {
 	var fields []FieldInfo
 
@@ -317,19 +751,27 @@ func (a *Analyser) extractFields(structType *ast.StructType) []FieldInfo {
 			tag = field.Tag.Value
 		}
 
+		doc := field.Doc.Text() + field.Comment.Text()
+
 		if len(field.Names) == 0 {
 			// Embedded field
 			fields = append(fields, FieldInfo{
-				Name: "",
-				Type: fieldType,
-				Tag:  tag,
+				Name:        "",
+				Type:        fieldType,
+				Tag:         tag,
+				Description: cleanDoc(stripDefault(doc)),
+				IsExported:  ast.IsExported(embeddedFieldName(field.Type)),
+				Default:     extractDefault(doc),
 			})
 		} else {
 			for _, name := range field.Names {
 				fields = append(fields, FieldInfo{
-					Name: name.Name,
-					Type: fieldType,
-					Tag:  tag,
+					Name:        name.Name,
+					Type:        fieldType,
+					Tag:         tag,
+					Description: cleanDoc(stripDefault(doc)),
+					IsExported:  ast.IsExported(name.Name),
+					Default:     extractDefault(doc),
 				})
 			}
 		}
@@ -338,87 +780,398 @@ func (a *Analyser) extractFields(structType *ast.StructType) []FieldInfo {
 	return fields
 }
 
+// getFunctionSignature prints decl's signature as gofmt would, without its
+// doc comment or body.
 func (a *Analyser) getFunctionSignature(decl *ast.FuncDecl) string {
-	// This is a simplified version - you'd want more sophisticated formatting
-	var parts []string
-
-	parts = append(parts, "func")
+	sig := *decl
+	sig.Doc = nil
+	sig.Body = nil
 
-	if decl.Recv != nil {
-		recv := a.fieldListToString(decl.Recv)
-		parts = append(parts, fmt.Sprintf("(%s)", recv))
+	var buf strings.Builder
+	if err := gofmtConfig.Fprint(&buf, a.fset, &sig); err != nil {
+		return "func " + decl.Name.Name
 	}
+	return buf.String()
+}
 
-	parts = append(parts, decl.Name.Name)
+func (a *Analyser) typeToString(expr ast.Expr) string {
+	// Simplified type-to-string conversion
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return "*" + a.typeToString(t.X)
+	case *ast.ParenExpr:
+		return a.typeToString(t.X)
+	case *ast.ArrayType:
+		if t.Len != nil {
+			return "[" + a.exprToString(t.Len) + "]" + a.typeToString(t.Elt)
+		}
+		return "[]" + a.typeToString(t.Elt)
+	case *ast.MapType:
+		return fmt.Sprintf("map[%s]%s", a.typeToString(t.Key), a.typeToString(t.Value))
+	case *ast.SelectorExpr:
+		return fmt.Sprintf("%s.%s", a.typeToString(t.X), t.Sel.Name)
+	case *ast.IndexExpr:
+		return fmt.Sprintf("%s[%s]", a.typeToString(t.X), a.typeToString(t.Index))
+	case *ast.IndexListExpr:
+		var indices []string
+		for _, index := range t.Indices {
+			indices = append(indices, a.typeToString(index))
+		}
+		return fmt.Sprintf("%s[%s]", a.typeToString(t.X), strings.Join(indices, ", "))
+	case *ast.StructType:
+		return "struct{" + a.inlineFields(t.Fields) + "}"
+	case *ast.InterfaceType:
+		return "interface{" + a.inlineFields(t.Methods) + "}"
+	case *ast.Ellipsis:
+		return "..." + a.typeToString(t.Elt)
+	case *ast.ChanType:
+		value := a.typeToString(t.Value)
+		switch t.Dir {
+		case ast.SEND:
+			return "chan<- " + value
+		case ast.RECV:
+			return "<-chan " + value
+		}
+		// chan (<-chan T) needs its parentheses to keep its meaning
+		if inner, ok := ast.Unparen(t.Value).(*ast.ChanType); ok && inner.Dir == ast.RECV {
+			value = "(" + value + ")"
+		}
+		return "chan " + value
+	case *ast.FuncType:
+		return "func" + a.funcTypeString(t)
+	default:
+		// Anything else, such as array lengths, is printed as written
+		return a.exprToString(expr)
+	}
+}
 
-	if decl.Type.Params != nil {
-		params := a.fieldListToString(decl.Type.Params)
-		parts = append(parts, fmt.Sprintf("(%s)", params))
-	} else {
-		parts = append(parts, "()")
+// inlineFields renders the members of an anonymous struct or interface on a
+// single line, e.g. " A, B int; C string ", or "" when there are none.
+func (a *Analyser) inlineFields(fields *ast.FieldList) string {
+	if fields == nil || len(fields.List) == 0 {
+		return ""
 	}
 
-	if decl.Type.Results != nil {
-		results := a.fieldListToString(decl.Type.Results)
-		if len(decl.Type.Results.List) == 1 && len(decl.Type.Results.List[0].Names) == 0 {
-			parts = append(parts, results)
-		} else {
-			parts = append(parts, fmt.Sprintf("(%s)", results))
+	var members []string
+	for _, field := range fields.List {
+		var names []string
+		for _, name := range field.Names {
+			names = append(names, name.Name)
 		}
+
+		if fn, ok := field.Type.(*ast.FuncType); ok && len(names) == 1 {
+			// Interface method: print the signature without the func keyword
+			members = append(members, names[0]+a.funcTypeString(fn))
+			continue
+		}
+
+		member := a.typeToString(field.Type)
+		if len(names) > 0 {
+			member = strings.Join(names, ", ") + " " + member
+		}
+		if field.Tag != nil {
+			member += " " + field.Tag.Value
+		}
+		members = append(members, member)
 	}
 
-	return strings.Join(parts, " ")
+	return " " + strings.Join(members, "; ") + " "
 }
 
-func (a *Analyser) fieldListToString(fields *ast.FieldList) string {
+// funcTypeString renders the parameters and results of a function type, e.g.
+// "(a, b int) (string, error)".
+func (a *Analyser) funcTypeString(fn *ast.FuncType) string {
+	params := "(" + a.paramList(fn.Params) + ")"
+	if fn.Results == nil || len(fn.Results.List) == 0 {
+		return params
+	}
+
+	results := a.paramList(fn.Results)
+	if len(fn.Results.List) == 1 && len(fn.Results.List[0].Names) == 0 {
+		return params + " " + results
+	}
+	return params + " (" + results + ")"
+}
+
+func (a *Analyser) paramList(fields *ast.FieldList) string {
 	if fields == nil {
 		return ""
 	}
 
-	var parts []string
+	var params []string
 	for _, field := range fields.List {
-		fieldType := a.typeToString(field.Type)
-		if len(field.Names) == 0 {
-			parts = append(parts, fieldType)
-		} else {
+		param := a.typeToString(field.Type)
+		if len(field.Names) > 0 {
+			var names []string
 			for _, name := range field.Names {
-				parts = append(parts, fmt.Sprintf("%s %s", name.Name, fieldType))
+				names = append(names, name.Name)
 			}
+			param = strings.Join(names, ", ") + " " + param
 		}
+		params = append(params, param)
 	}
 
-	return strings.Join(parts, ", ")
+	return strings.Join(params, ", ")
 }
 
-func (a *Analyser) typeToString(expr ast.Expr) string {
-	// Simplified type-to-string conversion
-	switch t := expr.(type) {
-	case *ast.Ident:
-		return t.Name
-	case *ast.StarExpr:
-		return "*" + a.typeToString(t.X)
-	case *ast.ArrayType:
-		return "[]" + a.typeToString(t.Elt)
-	case *ast.MapType:
-		return fmt.Sprintf("map[%s]%s", a.typeToString(t.Key), a.typeToString(t.Value))
-	case *ast.SelectorExpr:
-		return fmt.Sprintf("%s.%s", a.typeToString(t.X), t.Sel.Name)
-	case *ast.InterfaceType:
-		return "interface{}"
-	default:
-		return "unknown"
+// gofmtConfig prints nodes with the same layout as gofmt.
+var gofmtConfig = printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+
+// exprToString renders expr as gofmt would print it, e.g. 1 << 10 or
+// time.Second * 30.
+func (a *Analyser) exprToString(expr ast.Expr) string {
+	var buf strings.Builder
+	if err := gofmtConfig.Fprint(&buf, a.fset, expr); err != nil {
+		return "..."
 	}
+	return buf.String()
 }
 
-func (a *Analyser) exprToString(expr ast.Expr) string {
+// collectResultTypes maps each package-level function with a single result
+// to that result's type, so call expressions can be resolved by inferType.
+func (a *Analyser) collectResultTypes(pkg *ast.Package) map[string]string {
+	results := make(map[string]string)
+
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || fn.Type.Results == nil {
+				continue
+			}
+
+			list := fn.Type.Results.List
+			if len(list) == 1 && len(list[0].Names) <= 1 {
+				results[fn.Name.Name] = a.typeToString(list[0].Type)
+			}
+		}
+	}
+
+	return results
+}
+
+// valueScope holds what inferType can resolve identifiers against.
+type valueScope struct {
+	results map[string]string // function name to its single result type
+	consts  map[string]string // constant name to its type, see untypedPrefix
+}
+
+// untypedPrefix marks the default type of an untyped constant expression,
+// which gives way to any typed operand it is combined with.
+const untypedPrefix = "untyped "
+
+// untypedRank orders the untyped numeric kinds, so that an expression mixing
+// two of them takes the wider one.
+var untypedRank = map[string]int{
+	untypedPrefix + "int":        1,
+	untypedPrefix + "rune":       2,
+	untypedPrefix + "float64":    3,
+	untypedPrefix + "complex128": 4,
+}
+
+// collectValueTypes gathers the function result types and package-level
+// constant types that inferType resolves identifiers against.
+func (a *Analyser) collectValueTypes(pkg *ast.Package) valueScope {
+	scope := valueScope{
+		results: a.collectResultTypes(pkg),
+		consts:  make(map[string]string),
+	}
+
+	type constSpec struct {
+		name  string
+		typ   ast.Expr
+		value ast.Expr
+	}
+	var specs []constSpec
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.CONST {
+				continue
+			}
+			var prevType ast.Expr
+			var prevValues []ast.Expr
+			for _, spec := range gd.Specs {
+				vs := spec.(*ast.ValueSpec)
+				typ, values := vs.Type, vs.Values
+				if typ == nil && len(values) == 0 {
+					typ, values = prevType, prevValues
+				}
+				prevType, prevValues = typ, values
+				for i, name := range vs.Names {
+					cs := constSpec{name: name.Name, typ: typ}
+					if i < len(values) {
+						cs.value = values[i]
+					}
+					specs = append(specs, cs)
+				}
+			}
+		}
+	}
+
+	// Constants may refer to ones declared later, so resolve until nothing changes
+	for changed := true; changed; {
+		changed = false
+		for _, cs := range specs {
+			if _, ok := scope.consts[cs.name]; ok || cs.name == "_" {
+				continue
+			}
+			var typ string
+			if cs.typ != nil {
+				typ = a.typeToString(cs.typ)
+			} else if cs.value != nil {
+				typ = a.valueType(cs.value, scope)
+			}
+			if typ != "" {
+				scope.consts[cs.name] = typ
+				changed = true
+			}
+		}
+	}
+
+	return scope
+}
+
+// inferType makes a best-effort guess at the type of a value expression.
+// It returns an empty string when the type cannot be determined syntactically.
+func (a *Analyser) inferType(expr ast.Expr, scope valueScope) string {
+	return strings.TrimPrefix(a.valueType(expr, scope), untypedPrefix)
+}
+
+// valueType is inferType without defaulting untyped constants, which are
+// reported with untypedPrefix so that binary expressions can combine them.
+func (a *Analyser) valueType(expr ast.Expr, scope valueScope) string {
 	switch e := expr.(type) {
 	case *ast.BasicLit:
-		return e.Value
+		switch e.Kind {
+		case token.INT:
+			return untypedPrefix + "int"
+		case token.FLOAT:
+			return untypedPrefix + "float64"
+		case token.IMAG:
+			return untypedPrefix + "complex128"
+		case token.CHAR:
+			return untypedPrefix + "rune"
+		case token.STRING:
+			return untypedPrefix + "string"
+		}
 	case *ast.Ident:
-		return e.Name
-	default:
-		return "..."
+		switch e.Name {
+		case "true", "false":
+			return untypedPrefix + "bool"
+		case "iota":
+			return untypedPrefix + "int"
+		}
+		return scope.consts[e.Name]
+	case *ast.CompositeLit:
+		// [...]T{a, b} has the array type [2]T
+		if arr, ok := e.Type.(*ast.ArrayType); ok {
+			if _, ok := arr.Len.(*ast.Ellipsis); ok && !hasKeyedElements(e) {
+				return fmt.Sprintf("[%d]%s", len(e.Elts), a.typeToString(arr.Elt))
+			}
+		}
+		if e.Type != nil {
+			return a.typeToString(e.Type)
+		}
+	case *ast.FuncLit:
+		return a.typeToString(e.Type)
+	case *ast.UnaryExpr:
+		if e.Op == token.AND {
+			if inner := a.inferType(e.X, scope); inner != "" {
+				return "*" + inner
+			}
+			return ""
+		}
+		if e.Op == token.NOT {
+			return "bool"
+		}
+		return a.valueType(e.X, scope)
+	case *ast.ParenExpr:
+		return a.valueType(e.X, scope)
+	case *ast.BinaryExpr:
+		switch e.Op {
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ, token.LAND, token.LOR:
+			return "bool"
+		case token.SHL, token.SHR:
+			return a.valueType(e.X, scope)
+		}
+		return binaryType(a.valueType(e.X, scope), a.valueType(e.Y, scope))
+	case *ast.CallExpr:
+		return a.inferCallType(e, scope.results)
+	}
+
+	return ""
+}
+
+// binaryType combines the operand types of an arithmetic expression the way
+// the compiler does: a typed operand wins over an untyped one, and two untyped
+// numeric operands take the wider kind. An unknown operand, such as a constant
+// from another package, leaves the result unknown too.
+func binaryType(left, right string) string {
+	if left == "" || right == "" {
+		return ""
+	}
+	leftUntyped := strings.HasPrefix(left, untypedPrefix)
+	rightUntyped := strings.HasPrefix(right, untypedPrefix)
+	switch {
+	case leftUntyped && rightUntyped:
+		if untypedRank[right] > untypedRank[left] {
+			return right
+		}
+		return left
+	case leftUntyped:
+		return right
+	case rightUntyped, left == right:
+		return left
+	}
+	return ""
+}
+
+func hasKeyedElements(lit *ast.CompositeLit) bool {
+	for _, elt := range lit.Elts {
+		if _, ok := elt.(*ast.KeyValueExpr); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *Analyser) inferCallType(call *ast.CallExpr, results map[string]string) string {
+	switch fn := call.Fun.(type) {
+	case *ast.Ident:
+		switch fn.Name {
+		case "make":
+			if len(call.Args) > 0 {
+				return a.typeToString(call.Args[0])
+			}
+		case "new":
+			if len(call.Args) > 0 {
+				return "*" + a.typeToString(call.Args[0])
+			}
+		case "len", "cap", "copy":
+			return "int"
+		case "string", "int", "int8", "int16", "int32", "int64",
+			"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+			"float32", "float64", "complex64", "complex128", "byte", "rune", "bool":
+			return fn.Name
+		}
+		return results[fn.Name]
+	case *ast.SelectorExpr:
+		if pkg, ok := fn.X.(*ast.Ident); ok {
+			switch pkg.Name + "." + fn.Sel.Name {
+			case "errors.New", "fmt.Errorf":
+				return "error"
+			case "fmt.Sprintf", "fmt.Sprint", "fmt.Sprintln":
+				return "string"
+			}
+		}
+	case *ast.ArrayType, *ast.MapType, *ast.StarExpr, *ast.ParenExpr:
+		// Conversion to a composite type, e.g. []byte("x")
+		return a.typeToString(fn)
 	}
+
+	return ""
 }
 
 func (a *Analyser) getTypeKind(expr ast.Expr) string {
@@ -436,7 +1189,7 @@ func (a *Analyser) getTypeKind(expr ast.Expr) string {
 	case *ast.FuncType:
 		return "function"
 	default:
-		return "alias"
+		return "defined"
 	}
 }
 
@@ -480,6 +1233,69 @@ func (a *Analyser) extractExamples(doc string) []string {
 	return examples
 }
 
+// packageOverview returns a package doc with its paragraph formatting kept,
+// or "" when it is a single paragraph that the description already covers.
+func packageOverview(doc string) string {
+	doc = strings.TrimSpace(strings.ReplaceAll(doc, "\r\n", "\n"))
+	if !strings.Contains(doc, "\n\n") {
+		return ""
+	}
+	return doc
+}
+
+// extractSince returns the version from a "Since: v1.4.0" line in doc.
+func extractSince(doc string) string {
+	for _, line := range strings.Split(doc, "\n") {
+		if version, ok := strings.CutPrefix(strings.TrimSpace(line), "Since:"); ok {
+			return strings.TrimSpace(version)
+		}
+	}
+	return ""
+}
+
+// stripSince removes any "Since:" line from doc so it isn't repeated in the description.
+func stripSince(doc string) string {
+	lines := strings.Split(doc, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if !strings.HasPrefix(strings.TrimSpace(line), "Since:") {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// extractDefault returns the value from a "default: 30s" line in a field's doc.
+func extractDefault(doc string) string {
+	for _, line := range strings.Split(doc, "\n") {
+		if value, ok := cutDefault(line); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// stripDefault removes any "default:" line from doc so it isn't repeated in
+// the description.
+func stripDefault(doc string) string {
+	lines := strings.Split(doc, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if _, ok := cutDefault(line); !ok {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+func cutDefault(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if len(line) < len("default:") || !strings.EqualFold(line[:len("default:")], "default:") {
+		return "", false
+	}
+	return strings.TrimSpace(line[len("default:"):]), true
+}
+
 func cleanDoc(doc string) string {
 	if doc == "" {
 		return ""
@@ -0,0 +1,693 @@
+package analyser
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+// writePackage writes files, keyed by name, to a temporary directory and
+// returns it.
+func writePackage(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// analyseSource analyses a package made of the single file src.
+func analyseSource(t *testing.T, src string, opts ...Option) *PackageInfo {
+	t.Helper()
+	info, err := NewAnalyser(opts...).AnalysePackage(writePackage(t, map[string]string{"pkg.go": src}))
+	if err != nil {
+		t.Fatalf("AnalysePackage: %v", err)
+	}
+	return info
+}
+
+func findType(t *testing.T, info *PackageInfo, name string) TypeInfo {
+	t.Helper()
+	for _, typ := range info.Types {
+		if typ.Name == name {
+			return typ
+		}
+	}
+	t.Fatalf("type %s not found", name)
+	return TypeInfo{}
+}
+
+func TestTypeAliases(t *testing.T) {
+	info := analyseSource(t, `package p
+
+type List[T any] []T
+
+// Alias is another name for string.
+type Alias = string
+
+// Defined has string as its underlying type.
+type Defined string
+
+// Ints is an alias to an instantiated generic type.
+type Ints = List[int]
+
+// IDs is a list of IDs.
+type IDs []string
+
+// Counts counts by name.
+type Counts map[string]int
+
+// Option configures a Client.
+type Option func(*Client)
+
+// Events carries events.
+type Events chan<- string
+
+// Client is a client.
+type Client struct{}
+`)
+
+	tests := []struct {
+		name       string
+		kind       string
+		underlying string
+		alias      bool
+	}{
+		{"Alias", "alias", "string", true},
+		{"Defined", "defined", "string", false},
+		{"Ints", "alias", "List[int]", true},
+		{"List", "array", "[]T", false},
+		{"IDs", "array", "[]string", false},
+		{"Counts", "map", "map[string]int", false},
+		{"Option", "function", "func(*Client)", false},
+		{"Events", "channel", "chan<- string", false},
+		{"Client", "struct", "", false},
+	}
+	for _, tt := range tests {
+		typ := findType(t, info, tt.name)
+		if typ.Kind != tt.kind || typ.Underlying != tt.underlying || typ.IsAlias != tt.alias {
+			t.Errorf("%s: got kind %q underlying %q alias %t, want %q %q %t", tt.name, typ.Kind, typ.Underlying, typ.IsAlias, tt.kind, tt.underlying, tt.alias)
+		}
+	}
+}
+
+func TestInferredValueTypes(t *testing.T) {
+	info := analyseSource(t, `package p
+
+const Pi = 3.14
+
+var Names = []string{"a", "b"}
+
+var Unknown = compute()
+`)
+
+	if len(info.Constants) != 1 || info.Constants[0].Type != "float64" {
+		t.Errorf("Pi: got %+v, want type float64", info.Constants)
+	}
+
+	types := make(map[string]string)
+	for _, v := range info.Variables {
+		types[v.Name] = v.Type
+	}
+	if types["Names"] != "[]string" {
+		t.Errorf("Names: got type %q, want []string", types["Names"])
+	}
+	if types["Unknown"] != "" {
+		t.Errorf("Unknown: got type %q, want it left blank", types["Unknown"])
+	}
+}
+
+func TestConstantExpressionTypes(t *testing.T) {
+	info := analyseSource(t, `package p
+
+import "time"
+
+type Size int
+
+const KB Size = 1024
+
+const (
+	Timeout = 30 * time.Second
+	MB      = 1024 * KB
+	Mixed   = 1 + 2.5
+	Letter  = 'a' + 1
+	Wave    = 2 * (1 + 1i)
+	GB      = MB * 1024
+)
+`)
+
+	types := make(map[string]string)
+	for _, c := range info.Constants {
+		types[c.Name] = c.Type
+	}
+	tests := []struct {
+		name, want string
+	}{
+		// time.Second's type is only known to the type checker
+		{"Timeout", ""},
+		{"MB", "Size"},
+		{"Mixed", "float64"},
+		{"Letter", "rune"},
+		{"Wave", "complex128"},
+		{"GB", "Size"},
+	}
+	for _, tt := range tests {
+		if got := types[tt.name]; got != tt.want {
+			t.Errorf("%s: got type %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPackageSummaryAndOverview(t *testing.T) {
+	info := analyseSource(t, `// Package store stores things. It is safe for concurrent use.
+//
+// Values are kept in memory until Flush writes them out.
+package store
+`)
+	if info.Summary != "Package store stores things." {
+		t.Errorf("got summary %q", info.Summary)
+	}
+	if !strings.Contains(info.Overview, "safe for concurrent use.\n\nValues are kept") {
+		t.Errorf("overview should keep both paragraphs, got %q", info.Overview)
+	}
+}
+
+func TestTestOnlyPackage(t *testing.T) {
+	dir := writePackage(t, map[string]string{
+		"store_test.go": "package store_test\n\nimport \"testing\"\n\nfunc TestPut(t *testing.T) {}\n",
+	})
+	if _, err := NewAnalyser().AnalysePackage(dir); !errors.Is(err, ErrTestOnlyPackage) {
+		t.Errorf("got %v, want ErrTestOnlyPackage", err)
+	}
+}
+
+func TestSince(t *testing.T) {
+	info := analyseSource(t, `package p
+
+// Open opens it.
+//
+// Since: v1.4.0
+func Open() {}
+
+// Close closes it.
+func Close() {}
+
+// File is a file.
+// Since: v1.2.0
+type File struct{}
+`)
+
+	since := make(map[string]string)
+	descriptions := make(map[string]string)
+	for _, fn := range info.Functions {
+		since[fn.Name] = fn.Since
+		descriptions[fn.Name] = fn.Description
+	}
+	if since["Open"] != "v1.4.0" || since["Close"] != "" {
+		t.Errorf("got since %v, want Open v1.4.0 and Close none", since)
+	}
+	if strings.Contains(descriptions["Open"], "Since") {
+		t.Errorf("Since line left in the description: %q", descriptions["Open"])
+	}
+	if file := findType(t, info, "File"); file.Since != "v1.2.0" || file.Description != "File is a file." {
+		t.Errorf("File: got since %q description %q", file.Since, file.Description)
+	}
+}
+
+func TestReceivers(t *testing.T) {
+	info := analyseSource(t, `package p
+
+// Counter counts.
+type Counter struct{ n int }
+
+// Value returns the count.
+func (c Counter) Value() int { return c.n }
+
+// Inc adds one.
+func (c *Counter) Inc() { c.n++ }
+`)
+
+	methods := make(map[string]FunctionInfo)
+	for _, fn := range info.Functions {
+		methods[fn.Name] = fn
+	}
+	if m := methods["Value"]; m.PointerReceiver || !strings.Contains(m.Signature, "(c Counter) Value()") {
+		t.Errorf("Value: got pointer %t signature %q", m.PointerReceiver, m.Signature)
+	}
+	if m := methods["Inc"]; !m.PointerReceiver || !strings.Contains(m.Signature, "(c *Counter) Inc()") {
+		t.Errorf("Inc: got pointer %t signature %q", m.PointerReceiver, m.Signature)
+	}
+}
+
+func TestSentinelErrors(t *testing.T) {
+	info := analyseSource(t, `package p
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound is returned for missing keys.
+	ErrNotFound = errors.New("not found")
+	// ErrClosed is returned after Close.
+	ErrClosed = fmt.Errorf("closed: %w", ErrNotFound)
+)
+
+// Limit caps the number of entries.
+var Limit = 10
+`)
+
+	isError := make(map[string]bool)
+	descriptions := make(map[string]string)
+	for _, v := range info.Variables {
+		isError[v.Name] = v.IsError
+		descriptions[v.Name] = v.Description
+	}
+	if !isError["ErrNotFound"] || !isError["ErrClosed"] || isError["Limit"] {
+		t.Errorf("got IsError %v, want the two sentinels only", isError)
+	}
+	if descriptions["ErrClosed"] != "ErrClosed is returned after Close." {
+		t.Errorf("grouped sentinel lost its own comment: %q", descriptions["ErrClosed"])
+	}
+}
+
+func TestValueExpressions(t *testing.T) {
+	info := analyseSource(t, `package p
+
+import "time"
+
+type Config struct {
+	Name    string
+	Retries int
+}
+
+const KiB = 1 << 10
+
+var Timeout = time.Second * 30
+
+var Default = Config{Name: "default", Retries: 3}
+
+var Enabled = !false
+`)
+
+	values := make(map[string]string)
+	for _, c := range info.Constants {
+		values[c.Name] = c.Value
+	}
+	for _, v := range info.Variables {
+		values[v.Name] = v.Value
+	}
+
+	want := map[string]string{
+		"KiB":     "1 << 10",
+		"Timeout": "time.Second * 30",
+		"Default": `Config{Name: "default", Retries: 3}`,
+		"Enabled": "!false",
+	}
+	for name, value := range want {
+		if values[name] != value {
+			t.Errorf("%s: got value %q, want %q", name, values[name], value)
+		}
+	}
+}
+
+func TestSignaturesMatchGofmt(t *testing.T) {
+	info := analyseSource(t, `package p
+
+import "io"
+
+func Variadic(format string, args ...any) {}
+
+func Shared(a, b int, c string) (n, m int, err error) { return }
+
+func Generic[K comparable, V any](m map[K]V, keys ...K) []V { return nil }
+
+func Func(fn func(int) (string, error), ch <-chan struct{}) {}
+
+func Unnamed(int, *io.Writer) {}
+
+type List[T any] []T
+
+func (l *List[T]) Push(v T) {}
+`)
+
+	want := map[string]string{
+		"Variadic": "func Variadic(format string, args ...any)",
+		"Shared":   "func Shared(a, b int, c string) (n, m int, err error)",
+		"Generic":  "func Generic[K comparable, V any](m map[K]V, keys ...K) []V",
+		"Func":     "func Func(fn func(int) (string, error), ch <-chan struct{})",
+		"Unnamed":  "func Unnamed(int, *io.Writer)",
+		"Push":     "func (l *List[T]) Push(v T)",
+	}
+	for _, fn := range info.Functions {
+		if sig, ok := want[fn.Name]; ok && fn.Signature != sig {
+			t.Errorf("%s: got %q, want %q", fn.Name, fn.Signature, sig)
+		}
+	}
+}
+
+func TestConstraints(t *testing.T) {
+	info := analyseSource(t, `package p
+
+// Number is any integer or float.
+type Number interface {
+	~int | ~int64 | ~float64
+}
+
+// Map maps keys to values.
+type Map[K comparable, V any] struct {
+	entries map[K]V
+}
+
+// Sum adds up values.
+func Sum[T Number](values ...T) T { var s T; return s }
+`)
+
+	number := findType(t, info, "Number")
+	if !slices.Equal(number.TypeSet, []string{"~int | ~int64 | ~float64"}) {
+		t.Errorf("Number type set: got %q", number.TypeSet)
+	}
+
+	m := findType(t, info, "Map")
+	want := []TypeParam{{Name: "K", Constraint: "comparable"}, {Name: "V", Constraint: "any"}}
+	if !slices.Equal(m.TypeParams, want) {
+		t.Errorf("Map type params: got %+v, want %+v", m.TypeParams, want)
+	}
+
+	for _, fn := range info.Functions {
+		if fn.Name == "Sum" && !slices.Equal(fn.TypeParams, []TypeParam{{Name: "T", Constraint: "Number"}}) {
+			t.Errorf("Sum type params: got %+v", fn.TypeParams)
+		}
+	}
+}
+
+func TestBrokenFileSkipped(t *testing.T) {
+	dir := writePackage(t, map[string]string{
+		"good.go":   "package p\n\n// Good works.\nfunc Good() {}\n",
+		"broken.go": "package p\n\nfunc Broken( {\n",
+	})
+
+	info, err := NewAnalyser().AnalysePackage(dir)
+	if err != nil {
+		t.Fatalf("one broken file failed the package: %v", err)
+	}
+	if len(info.Functions) != 1 || info.Functions[0].Name != "Good" {
+		t.Errorf("got functions %+v, want Good", info.Functions)
+	}
+	if len(info.Warnings) != 1 || !strings.Contains(info.Warnings[0], "broken.go") {
+		t.Errorf("got warnings %q, want one naming broken.go", info.Warnings)
+	}
+}
+
+func TestFunctionResults(t *testing.T) {
+	info := analyseSource(t, `package p
+
+import "net/http"
+
+type T struct{}
+
+func Middleware() func(http.Handler) http.Handler { return nil }
+
+func Load(name string) (T, error) { return T{}, nil }
+
+func Chain() func(func(int) error) (func() string, error) { return nil }
+`)
+
+	want := map[string]struct {
+		signature string
+		returns   []string
+	}{
+		"Middleware": {"func Middleware() func(http.Handler) http.Handler", []string{"func(http.Handler) http.Handler"}},
+		"Load":       {"func Load(name string) (T, error)", []string{"T", "error"}},
+		"Chain":      {"func Chain() func(func(int) error) (func() string, error)", []string{"func(func(int) error) (func() string, error)"}},
+	}
+	for _, fn := range info.Functions {
+		w := want[fn.Name]
+		if fn.Signature != w.signature {
+			t.Errorf("%s signature: got %q, want %q", fn.Name, fn.Signature, w.signature)
+		}
+		var returns []string
+		for _, r := range fn.Returns {
+			returns = append(returns, r.Type)
+		}
+		if !slices.Equal(returns, w.returns) {
+			t.Errorf("%s returns: got %q, want %q", fn.Name, returns, w.returns)
+		}
+	}
+}
+
+func TestAnonymousTypes(t *testing.T) {
+	info := analyseSource(t, `package p
+
+type Server struct {
+	Config struct {
+		Addr string
+		Port int
+	}
+}
+
+func Serve(opts interface{ Name() string }, any interface{}) {}
+`)
+
+	server := findType(t, info, "Server")
+	if len(server.Fields) != 1 || server.Fields[0].Type != "struct{ Addr string; Port int }" {
+		t.Errorf("got fields %+v, want Config rendered with its members", server.Fields)
+	}
+
+	if len(info.Functions) != 1 {
+		t.Fatalf("got functions %+v", info.Functions)
+	}
+	var params []string
+	for _, param := range info.Functions[0].Parameters {
+		params = append(params, param.Type)
+	}
+	if want := []string{"interface{ Name() string }", "interface{}"}; !slices.Equal(params, want) {
+		t.Errorf("got parameter types %q, want %q", params, want)
+	}
+}
+
+func TestGenericMethods(t *testing.T) {
+	info := analyseSource(t, `package p
+
+type Stack[T any] struct{ items []T }
+
+func (s *Stack[T]) Push(v T) { s.items = append(s.items, v) }
+
+func (s Stack[T]) Peek() (T, bool) { var zero T; return zero, false }
+
+type Pair[K comparable, V any] struct{}
+
+func (p *Pair[K, V]) Swap(k K, v V) (V, K) { return v, k }
+`)
+
+	want := map[string]string{
+		"Push": "func (s *Stack[T]) Push(v T)",
+		"Peek": "func (s Stack[T]) Peek() (T, bool)",
+		"Swap": "func (p *Pair[K, V]) Swap(k K, v V) (V, K)",
+	}
+	for _, fn := range info.Functions {
+		if fn.Signature != want[fn.Name] {
+			t.Errorf("%s: got %q, want %q", fn.Name, fn.Signature, want[fn.Name])
+		}
+	}
+	if got := findType(t, info, "Stack").Methods; !slices.Equal(got, []string{"Peek", "Push"}) {
+		t.Errorf("Stack methods %q, want Peek and Push", got)
+	}
+}
+
+func TestSourcePositions(t *testing.T) {
+	dir := writePackage(t, map[string]string{
+		"shape.go": `package shape
+
+// Square is a square.
+type Square struct{ Side float64 }
+
+// Area returns the square's area.
+func (s Square) Area() float64 { return s.Side * s.Side }
+`,
+		"new.go": `package shape
+
+// NewSquare returns a square with the given side.
+func NewSquare(side float64) Square { return Square{Side: side} }
+`,
+	})
+	info, err := NewAnalyser().AnalysePackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if square := findType(t, info, "Square"); square.File != "shape.go" || square.Line != 4 {
+		t.Errorf("Square declared at %s:%d, want shape.go:4", square.File, square.Line)
+	}
+	want := map[string]string{"Area": "shape.go:7", "NewSquare": "new.go:4"}
+	for _, fn := range info.Functions {
+		if got := fmt.Sprintf("%s:%d", fn.File, fn.Line); got != want[fn.Name] {
+			t.Errorf("%s declared at %s, want %s", fn.Name, got, want[fn.Name])
+		}
+		delete(want, fn.Name)
+	}
+	if len(want) > 0 {
+		t.Errorf("functions not found: %v", want)
+	}
+}
+
+func TestConcurrentAnalysis(t *testing.T) {
+	var dirs []string
+	for i := range 8 {
+		// Each package declares its function on a different line, so positions
+		// read from a shared FileSet would be caught
+		src := fmt.Sprintf("package p%d\n%s\n// F%d does nothing.\nfunc F%d() {}\n", i, strings.Repeat("\n", i), i, i)
+		dirs = append(dirs, writePackage(t, map[string]string{"pkg.go": src}))
+	}
+
+	a := NewAnalyser()
+	infos := make([]*PackageInfo, len(dirs))
+	errs := make([]error, len(dirs))
+	var wg sync.WaitGroup
+	for i, dir := range dirs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			infos[i], errs[i] = a.AnalysePackage(dir)
+		}()
+	}
+	wg.Wait()
+
+	for i, info := range infos {
+		if errs[i] != nil {
+			t.Fatalf("package %d: %v", i, errs[i])
+		}
+		if len(info.Functions) != 1 {
+			t.Fatalf("package %d: got %d functions, want 1", i, len(info.Functions))
+		}
+		if fn := info.Functions[0]; fn.Name != fmt.Sprintf("F%d", i) || fn.Line != i+4 {
+			t.Errorf("package %d: got %s at line %d, want F%d at line %d", i, fn.Name, fn.Line, i, i+4)
+		}
+	}
+}
+
+func TestTestHelpers(t *testing.T) {
+	info := analyseSource(t, `package store
+
+import "testing"
+
+// Open opens the store.
+func Open() error { return nil }
+
+// MustOpen opens the store, failing tb on error.
+func MustOpen(tb testing.TB) {}
+
+// Bench runs b against the store.
+func Bench(b *testing.B, name string) {}
+`)
+	helpers := map[string]bool{"Open": false, "MustOpen": true, "Bench": true}
+	for _, fn := range info.Functions {
+		if fn.IsTestHelper != helpers[fn.Name] {
+			t.Errorf("%s: IsTestHelper = %t, want %t", fn.Name, fn.IsTestHelper, helpers[fn.Name])
+		}
+	}
+}
+
+func TestPrivateFields(t *testing.T) {
+	src := `package p
+
+// Config configures the client.
+type Config struct {
+	// Name is shown in logs.
+	Name  string ` + "`json:\"name\"`" + `
+	token string ` + "`json:\"token\"`" + `
+}
+`
+	names := func(info *PackageInfo) []string {
+		var names []string
+		for _, field := range findType(t, info, "Config").Fields {
+			names = append(names, field.Name)
+		}
+		return names
+	}
+
+	if got := names(analyseSource(t, src)); !slices.Equal(got, []string{"Name"}) {
+		t.Errorf("fields without PrivateFields: got %v, want [Name]", got)
+	}
+
+	dir := writePackage(t, map[string]string{"pkg.go": src})
+	a := NewAnalyser()
+	a.PrivateFields = true
+	info, err := a.AnalysePackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := findType(t, info, "Config").Fields
+	if got := names(info); !slices.Equal(got, []string{"Name", "token"}) {
+		t.Fatalf("fields with PrivateFields: got %v, want [Name token]", got)
+	}
+	if fields[0].Description != "Name is shown in logs." || !fields[0].IsExported || fields[1].IsExported {
+		t.Errorf("got fields %+v", fields)
+	}
+}
+
+func TestInlineUnionConstraints(t *testing.T) {
+	info := analyseSource(t, `package p
+
+// Format formats v.
+func Format[T int | string](v T) string { return "" }
+
+// Abs returns the absolute value of v.
+func Abs[T ~int | ~float64, U interface{ ~int8 }](v T, u U) T { return v }
+`)
+	tests := map[string]struct {
+		signature string
+		params    []TypeParam
+	}{
+		"Format": {"func Format[T int | string](v T) string", []TypeParam{{Name: "T", Constraint: "int | string"}}},
+		"Abs":    {"func Abs[T ~int | ~float64, U interface{ ~int8 }](v T, u U) T", []TypeParam{{Name: "T", Constraint: "~int | ~float64"}, {Name: "U", Constraint: "interface{ ~int8 }"}}},
+	}
+	for _, fn := range info.Functions {
+		want := tests[fn.Name]
+		if fn.Signature != want.signature {
+			t.Errorf("%s signature: got %q, want %q", fn.Name, fn.Signature, want.signature)
+		}
+		if !slices.Equal(fn.TypeParams, want.params) {
+			t.Errorf("%s type params: got %+v, want %+v", fn.Name, fn.TypeParams, want.params)
+		}
+	}
+}
+
+func TestErrorClassification(t *testing.T) {
+	tests := []struct {
+		name string
+		dir  string
+		want error
+	}{
+		{"missing directory", filepath.Join(t.TempDir(), "missing"), ErrNoPackage},
+		{"no Go files", writePackage(t, map[string]string{"README.md": "# nothing\n"}), ErrNoPackage},
+		{"broken file", writePackage(t, map[string]string{"broken.go": "package p\n\nfunc {\n"}), ErrParse},
+		{"test code only", writePackage(t, map[string]string{"p_test.go": "package p\n"}), ErrTestOnlyPackage},
+	}
+	for _, test := range tests {
+		_, err := NewAnalyser().AnalysePackage(test.dir)
+		if !errors.Is(err, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.want)
+		}
+	}
+
+	// A directory that can't be read is a parse failure, not a missing package
+	fsys := fstest.MapFS{"p": &fstest.MapFile{Data: []byte("not a directory")}}
+	if _, err := NewAnalyser().AnalysePackageFS(fsys, "p"); !errors.Is(err, ErrParse) || errors.Is(err, ErrNoPackage) {
+		t.Errorf("unreadable directory: got %v, want %v", err, ErrParse)
+	}
+}
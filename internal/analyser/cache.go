@@ -0,0 +1,151 @@
+package analyser
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// packageCache holds analysed packages by directory.
+type packageCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// cacheEntry is a package analysed earlier, valid while its directory's
+// fingerprint is unchanged.
+type cacheEntry struct {
+	fingerprint string
+	info        *PackageInfo
+}
+
+// fingerprint identifies the state of the files in the source directory by
+// name, size and modification time, without reading them. The options, which
+// include any build tags, the ignore rules and the enclosing go.mod are part
+// of it too, as they also change the analysis.
+func (s source) fingerprint(options string, ignore *IgnoreRules) (string, error) {
+	entries, err := s.readDir()
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintln(&b, options)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "%s %d %d\n", entry.Name(), info.Size(), info.ModTime().UnixNano())
+	}
+
+	if ignore != nil {
+		fmt.Fprintf(&b, "ignore %s %v\n", ignore.root, ignore.patterns)
+	}
+	if goMod, info, ok := s.goMod(); ok {
+		fmt.Fprintf(&b, "%s %d %d\n", goMod, info.Size(), info.ModTime().UnixNano())
+	}
+
+	return b.String(), nil
+}
+
+// goMod finds the go.mod nearest to the source directory, in it or above.
+func (s source) goMod() (string, fs.FileInfo, bool) {
+	if s.fsys != nil {
+		for dir := path.Clean(s.dir); ; dir = path.Dir(dir) {
+			goMod := path.Join(dir, "go.mod")
+			if info, err := fs.Stat(s.fsys, goMod); err == nil {
+				return goMod, info, true
+			}
+			if dir == "." || dir == "/" {
+				return "", nil, false
+			}
+		}
+	}
+
+	dir, err := filepath.Abs(s.dir)
+	if err != nil {
+		return "", nil, false
+	}
+	for ; ; dir = filepath.Dir(dir) {
+		goMod := filepath.Join(dir, "go.mod")
+		if info, err := os.Stat(goMod); err == nil {
+			return goMod, info, true
+		}
+		if filepath.Dir(dir) == dir {
+			return "", nil, false
+		}
+	}
+}
+
+func (a *Analyser) cached(dir string, fingerprint string) (*PackageInfo, bool) {
+	a.cache.mu.Lock()
+	defer a.cache.mu.Unlock()
+
+	entry, ok := a.cache.entries[dir]
+	if !ok || entry.fingerprint != fingerprint {
+		return nil, false
+	}
+	return entry.info.clone(), true
+}
+
+func (a *Analyser) store(dir string, fingerprint string, info *PackageInfo) {
+	a.cache.mu.Lock()
+	defer a.cache.mu.Unlock()
+
+	a.cache.entries[dir] = cacheEntry{fingerprint: fingerprint, info: info.clone()}
+}
+
+// clone copies info deeply, so that a caller changing the copy, such as by
+// enhancing descriptions or adding examples, leaves the original untouched.
+func (info *PackageInfo) clone() *PackageInfo {
+	c := *info
+	c.Flags = slices.Clone(info.Flags)
+	c.Routes = slices.Clone(info.Routes)
+	c.Functions = cloneFunctions(info.Functions)
+	c.Types = cloneTypes(info.Types)
+	c.Constants = slices.Clone(info.Constants)
+	c.Variables = slices.Clone(info.Variables)
+	c.Examples = slices.Clone(info.Examples)
+	c.Imports = slices.Clone(info.Imports)
+	c.Warnings = slices.Clone(info.Warnings)
+	c.UnexportedFunctions = cloneFunctions(info.UnexportedFunctions)
+	c.UnexportedTypes = cloneTypes(info.UnexportedTypes)
+	return &c
+}
+
+func cloneFunctions(functions []FunctionInfo) []FunctionInfo {
+	c := slices.Clone(functions)
+	for i := range c {
+		c[i].Parameters = slices.Clone(c[i].Parameters)
+		c[i].Returns = slices.Clone(c[i].Returns)
+		c[i].Examples = slices.Clone(c[i].Examples)
+		c[i].TypeParams = slices.Clone(c[i].TypeParams)
+	}
+	return c
+}
+
+func cloneTypes(types []TypeInfo) []TypeInfo {
+	c := slices.Clone(types)
+	for i := range c {
+		c[i].Fields = slices.Clone(c[i].Fields)
+		c[i].Methods = slices.Clone(c[i].Methods)
+		c[i].Constructors = slices.Clone(c[i].Constructors)
+		c[i].Options = slices.Clone(c[i].Options)
+		c[i].Implements = slices.Clone(c[i].Implements)
+		c[i].TypeParams = slices.Clone(c[i].TypeParams)
+		c[i].TypeSet = slices.Clone(c[i].TypeSet)
+		c[i].Embeds = slices.Clone(c[i].Embeds)
+		c[i].MethodSet = slices.Clone(c[i].MethodSet)
+		c[i].Examples = slices.Clone(c[i].Examples)
+	}
+	return c
+}
@@ -0,0 +1,197 @@
+package analyser
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// spyFS counts the Go files opened through it.
+type spyFS struct {
+	fs.FS
+	reads map[string]int
+}
+
+func (s *spyFS) Open(name string) (fs.File, error) {
+	if path.Ext(name) == ".go" {
+		s.reads[name]++
+	}
+	return s.FS.Open(name)
+}
+
+func TestAnalysePackageCached(t *testing.T) {
+	modTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	files := fstest.MapFS{
+		"p/p.go": {Data: []byte("package p\n\n// Value returns the value.\nfunc Value() int { return 1 }\n"), ModTime: modTime},
+	}
+	spy := &spyFS{FS: files, reads: make(map[string]int)}
+
+	a := NewAnalyser()
+	a.fsys = spy
+
+	first, err := a.AnalysePackage("p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spy.reads["p/p.go"] != 1 {
+		t.Fatalf("first analysis read p.go %d times, want 1", spy.reads["p/p.go"])
+	}
+
+	first.Functions[0].Description = "changed by the caller"
+	second, err := a.AnalysePackage("p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spy.reads["p/p.go"] != 1 {
+		t.Errorf("unchanged package was read again: %d reads", spy.reads["p/p.go"])
+	}
+	if second.Functions[0].Description != "Value returns the value." {
+		t.Errorf("cached package shares state with an earlier result: %q", second.Functions[0].Description)
+	}
+
+	files["p/p.go"] = &fstest.MapFile{Data: []byte("package p\n\n// Value returns two.\nfunc Value() int { return 2 }\n"), ModTime: modTime.Add(time.Second)}
+	third, err := a.AnalysePackage("p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spy.reads["p/p.go"] != 2 || third.Functions[0].Description != "Value returns two." {
+		t.Errorf("changed package not re-read: %d reads, description %q", spy.reads["p/p.go"], third.Functions[0].Description)
+	}
+}
+
+func TestCachedPackageIsCopied(t *testing.T) {
+	files := fstest.MapFS{
+		"p/p.go": {Data: []byte(`package p
+
+import "time"
+
+// Client calls the API.
+type Client struct {
+	// Timeout bounds each call.
+	Timeout time.Duration
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithTimeout sets the timeout.
+func WithTimeout(d time.Duration) Option { return nil }
+
+// NewClient returns a Client.
+func NewClient(opts ...Option) *Client { return &Client{} }
+
+// Do makes a call.
+func (c *Client) Do() error { return nil }
+`)},
+	}
+	spy := &spyFS{FS: files, reads: make(map[string]int)}
+	a := NewAnalyser()
+	a.fsys = spy
+
+	first, err := a.AnalysePackage("p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fresh := NewAnalyser()
+	fresh.fsys = files
+	want, err := fresh.AnalysePackage("p")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Change every nested slice of the first result in place
+	for i := range first.Functions {
+		fn := &first.Functions[i]
+		for j := range fn.Parameters {
+			fn.Parameters[j].Type = "changed"
+		}
+		for j := range fn.Returns {
+			fn.Returns[j].Type = "changed"
+		}
+	}
+	for i := range first.Types {
+		typ := &first.Types[i]
+		for j := range typ.Fields {
+			typ.Fields[j].Description = "changed"
+		}
+		for _, names := range [][]string{typ.Methods, typ.Constructors, typ.Options} {
+			for j := range names {
+				names[j] = "changed"
+			}
+		}
+	}
+	for i := range first.Imports {
+		first.Imports[i] = "changed"
+	}
+
+	second, err := a.AnalysePackage("p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spy.reads["p/p.go"] != 1 {
+		t.Errorf("unchanged package was read again: %d reads", spy.reads["p/p.go"])
+	}
+	if !reflect.DeepEqual(second, want) {
+		t.Errorf("cached package shares state with an earlier result:\n got %+v\nwant %+v", second, want)
+	}
+	client := findType(t, second, "Client")
+	if len(client.Options) != 1 || len(client.Constructors) != 1 || len(client.Methods) != 1 {
+		t.Errorf("Client should have an option, a constructor and a method: %+v", client)
+	}
+}
+
+func TestCacheFingerprint(t *testing.T) {
+	root := writePackage(t, map[string]string{
+		"go.mod":      "module example.com/a\n",
+		"p/p.go":      "package p\n\n// Value returns one.\nfunc Value() int { return 1 }\n",
+		"p/gen.go":    "package p\n\n// Generated is generated.\nfunc Generated() {}\n",
+		IgnoreFile:    "",
+		"other/x.txt": "",
+	})
+	dir := filepath.Join(root, "p")
+	a := NewAnalyser()
+
+	analyse := func() *PackageInfo {
+		t.Helper()
+		info, err := a.AnalysePackage(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info
+	}
+
+	if info := analyse(); info.ImportPath != "example.com/a/p" || len(info.Functions) != 2 {
+		t.Fatalf("got import path %q and %d functions", info.ImportPath, len(info.Functions))
+	}
+
+	// A changed go.mod changes the import path
+	goMod := filepath.Join(root, "go.mod")
+	if err := os.WriteFile(goMod, []byte("module example.com/b\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(goMod, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if info := analyse(); info.ImportPath != "example.com/b/p" {
+		t.Errorf("go.mod change ignored, got import path %q", info.ImportPath)
+	}
+
+	// New ignore rules leave out the files they match
+	if err := os.WriteFile(filepath.Join(root, IgnoreFile), []byte("p/gen.go\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := LoadIgnoreRules(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Ignore = rules
+	if info := analyse(); len(info.Functions) != 1 || info.Functions[0].Name != "Value" {
+		t.Errorf("ignore rules change ignored, got functions %v", functionNames(info))
+	}
+}
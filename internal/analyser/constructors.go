@@ -0,0 +1,199 @@
+package analyser
+
+import (
+	"go/ast"
+	"go/token"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// linkConstructors records each New or Make function on the type it returns,
+// e.g. NewClient() (*Client, error) becomes a constructor of Client.
+func linkConstructors(pkg *PackageInfo) {
+	types := make(map[string]*TypeInfo)
+	for i := range pkg.Types {
+		types[pkg.Types[i].Name] = &pkg.Types[i]
+	}
+
+	for _, fn := range pkg.Functions {
+		if fn.IsMethod || len(fn.Returns) == 0 {
+			continue
+		}
+		if !strings.HasPrefix(fn.Name, "New") && !strings.HasPrefix(fn.Name, "Make") {
+			continue
+		}
+
+		typ, ok := types[constructedType(fn.Returns[0].Type)]
+		if ok {
+			typ.Constructors = append(typ.Constructors, fn.Name)
+		}
+	}
+}
+
+// linkOptions groups the functional options of the package, functions such as
+// WithTimeout(d) Option returning one of its ...Option types, under the types
+// whose constructors take them. Options no constructor takes are grouped
+// under the option type itself.
+func linkOptions(pkg *PackageInfo) {
+	types := make(map[string]*TypeInfo)
+	constructs := make(map[string]string)
+	for i := range pkg.Types {
+		typ := &pkg.Types[i]
+		types[typ.Name] = typ
+		for _, name := range typ.Constructors {
+			constructs[name] = typ.Name
+		}
+	}
+
+	options := make(map[string][]string)
+	for _, fn := range pkg.Functions {
+		if fn.IsMethod || !fn.IsExported || len(fn.Returns) != 1 {
+			continue
+		}
+		if name := constructedType(fn.Returns[0].Type); strings.HasSuffix(name, "Option") && types[name] != nil {
+			options[name] = append(options[name], fn.Name)
+		}
+	}
+	if len(options) == 0 {
+		return
+	}
+
+	taken := make(map[string]bool)
+	for _, fn := range pkg.Functions {
+		typ, ok := types[constructs[fn.Name]]
+		if fn.IsMethod || !ok {
+			continue
+		}
+		for _, param := range fn.Parameters {
+			name := constructedType(strings.TrimPrefix(strings.TrimPrefix(param.Type, "..."), "[]"))
+			for _, option := range options[name] {
+				if !slices.Contains(typ.Options, option) {
+					typ.Options = append(typ.Options, option)
+				}
+			}
+			taken[name] = taken[name] || len(options[name]) > 0
+		}
+	}
+
+	for name, funcs := range options {
+		if !taken[name] {
+			types[name].Options = funcs
+		}
+	}
+}
+
+// constructedType strips the pointer and type arguments from a result type,
+// so *List[T] names List.
+func constructedType(result string) string {
+	name := strings.TrimPrefix(result, "*")
+	if i := strings.Index(name, "["); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// collectConstructorDefaults returns, by type, the constant values New and
+// Make functions returning the type give its fields in composite literals,
+// e.g. Timeout: 30 * time.Second in return &Client{Timeout: 30 * time.Second}.
+// Values taken from parameters or variables are not defaults and are left
+// out. It must run before doc.New, which drops function bodies.
+func (a *Analyser) collectConstructorDefaults(pkg *ast.Package) map[string]map[string]string {
+	consts := make(map[string]bool)
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.CONST {
+				for _, spec := range gen.Specs {
+					for _, name := range spec.(*ast.ValueSpec).Names {
+						consts[name.Name] = true
+					}
+				}
+			}
+		}
+	}
+
+	defaults := make(map[string]map[string]string)
+	for _, file := range pkg.Files {
+		imports := make(map[string]bool)
+		for _, imp := range file.Imports {
+			if imp.Name != nil {
+				imports[imp.Name.Name] = true
+			} else if importPath, err := strconv.Unquote(imp.Path.Value); err == nil {
+				imports[path.Base(importPath)] = true
+			}
+		}
+
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || fn.Body == nil || fn.Type.Results == nil {
+				continue
+			}
+			if !strings.HasPrefix(fn.Name.Name, "New") && !strings.HasPrefix(fn.Name.Name, "Make") {
+				continue
+			}
+
+			typ := receiverTypeName(fn.Type.Results.List[0].Type)
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				lit, ok := n.(*ast.CompositeLit)
+				if !ok || lit.Type == nil || receiverTypeName(lit.Type) != typ {
+					return true
+				}
+				for _, elt := range lit.Elts {
+					kv, ok := elt.(*ast.KeyValueExpr)
+					if !ok {
+						continue
+					}
+					key, ok := kv.Key.(*ast.Ident)
+					if !ok || !isConstantExpr(kv.Value, consts, imports) {
+						continue
+					}
+					if defaults[typ] == nil {
+						defaults[typ] = make(map[string]string)
+					}
+					if _, seen := defaults[typ][key.Name]; !seen {
+						defaults[typ][key.Name] = a.exprToString(kv.Value)
+					}
+				}
+				return true
+			})
+		}
+	}
+	return defaults
+}
+
+// isConstantExpr reports whether expr is built only from literals, the
+// package's constants and names qualified by an imported package, such as
+// time.Second.
+func isConstantExpr(expr ast.Expr, consts, imports map[string]bool) bool {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return true
+	case *ast.Ident:
+		return consts[e.Name] || e.Name == "true" || e.Name == "false"
+	case *ast.SelectorExpr:
+		pkg, ok := e.X.(*ast.Ident)
+		return ok && imports[pkg.Name]
+	case *ast.ParenExpr:
+		return isConstantExpr(e.X, consts, imports)
+	case *ast.UnaryExpr:
+		return e.Op != token.AND && isConstantExpr(e.X, consts, imports)
+	case *ast.BinaryExpr:
+		return isConstantExpr(e.X, consts, imports) && isConstantExpr(e.Y, consts, imports)
+	}
+	return false
+}
+
+// applyDefaults gives the fields of pkg's types without a "default:"
+// comment the values their constructors set.
+func applyDefaults(pkg *PackageInfo, defaults map[string]map[string]string) {
+	for i := range pkg.Types {
+		values := defaults[pkg.Types[i].Name]
+		for j := range pkg.Types[i].Fields {
+			field := &pkg.Types[i].Fields[j]
+			if field.Default == "" && field.Name != "" {
+				field.Default = values[field.Name]
+			}
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package analyser
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestConstructors(t *testing.T) {
+	info, err := NewAnalyser().AnalysePackage(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := findType(t, info, "Analyser").Constructors; !slices.Equal(got, []string{"NewAnalyser"}) {
+		t.Errorf("Analyser constructors: got %q, want NewAnalyser", got)
+	}
+
+	info = analyseSource(t, `package p
+
+type Client struct{}
+
+func NewClient() *Client { return nil }
+
+func MakeClient(addr string) (Client, error) { return Client{}, nil }
+
+func NewDefaultClient() (*Client, error) { return nil, nil }
+
+func NewName() string { return "" }
+
+func Newline() {}
+
+func (c *Client) NewCopy() *Client { return c }
+`)
+	want := []string{"MakeClient", "NewClient", "NewDefaultClient"}
+	if got := findType(t, info, "Client").Constructors; !slices.Equal(got, want) {
+		t.Errorf("Client constructors: got %q, want %q", got, want)
+	}
+}
+
+func TestFunctionalOptions(t *testing.T) {
+	info := analyseSource(t, `package p
+
+import "time"
+
+type Client struct{}
+
+type Option func(*Client)
+
+func New(addr string, opts ...Option) *Client { return nil }
+
+func WithTimeout(d time.Duration) Option { return nil }
+
+func WithRetries(n int) Option { return nil }
+
+type ServerOption func(*server)
+
+type server struct{}
+
+func WithPort(port int) ServerOption { return nil }
+`)
+	if got := findType(t, info, "Client").Options; !slices.Equal(got, []string{"WithRetries", "WithTimeout"}) {
+		t.Errorf("Client options: got %q, want [WithRetries WithTimeout]", got)
+	}
+	if got := findType(t, info, "Option").Options; len(got) != 0 {
+		t.Errorf("options taken by New also grouped under Option: %q", got)
+	}
+	if got := findType(t, info, "ServerOption").Options; !slices.Equal(got, []string{"WithPort"}) {
+		t.Errorf("ServerOption options: got %q, want [WithPort]", got)
+	}
+}
+
+func TestFieldDefaults(t *testing.T) {
+	info := analyseSource(t, `package p
+
+import "time"
+
+const defaultRetries = 3
+
+// Config configures a Client.
+type Config struct {
+	// Addr is the server address.
+	// Default: localhost:8080
+	Addr string
+
+	// Timeout bounds each request.
+	Timeout time.Duration
+
+	// Retries is how often a request is retried.
+	Retries int
+
+	// Name identifies the client.
+	Name string
+
+	// Verbose logs each request.
+	Verbose bool
+
+	// Limit caps concurrent requests.
+	Limit int
+}
+
+func NewConfig(name string) *Config {
+	limit := 10
+	return &Config{
+		Addr:    "ignored, the comment wins",
+		Timeout: 30 * time.Second,
+		Retries: defaultRetries,
+		Name:    name,
+		Verbose: false,
+		Limit:   limit,
+	}
+}
+`)
+	want := map[string]string{
+		"Addr":    "localhost:8080",
+		"Timeout": "30 * time.Second",
+		"Retries": "defaultRetries",
+		"Name":    "",
+		"Verbose": "false",
+		"Limit":   "",
+	}
+	for _, field := range findType(t, info, "Config").Fields {
+		if field.Default != want[field.Name] {
+			t.Errorf("%s: got default %q, want %q", field.Name, field.Default, want[field.Name])
+		}
+		if strings.Contains(field.Description, "Default:") {
+			t.Errorf("%s: default line left in description %q", field.Name, field.Description)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package analyser
+
+import (
+	"go/ast"
+	"strings"
+)
+
+const directivePrefix = "//docura:"
+
+// directives are the //docura: comments attached to a declaration:
+//
+//	//docura:hidden             exclude the symbol from the docs
+//	//docura:example file.go    use file.go, relative to the package, as an example
+//	//docura:kind enum          override the reported kind of a type
+type directives struct {
+	hidden  bool
+	example string
+	kind    string
+}
+
+func parseDirectives(groups ...*ast.CommentGroup) directives {
+	var d directives
+
+	for _, group := range groups {
+		if group == nil {
+			continue
+		}
+
+		for _, comment := range group.List {
+			text, ok := strings.CutPrefix(comment.Text, directivePrefix)
+			if !ok {
+				continue
+			}
+
+			name, arg, _ := strings.Cut(strings.TrimSpace(text), " ")
+			arg = strings.TrimSpace(arg)
+			switch name {
+			case "hidden":
+				d.hidden = true
+			case "example":
+				d.example = arg
+			case "kind":
+				d.kind = arg
+			}
+		}
+	}
+
+	return d
+}
+
+// collectDirectives gathers the directives of every declaration in pkg, keyed
+// by symbol name, or "Type.Method" for methods. It must run before doc.New,
+// which detaches doc comments from the AST.
+func collectDirectives(pkg *ast.Package) map[string]directives {
+	dirs := make(map[string]directives)
+
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				name := decl.Name.Name
+				if decl.Recv != nil && len(decl.Recv.List) > 0 {
+					name = receiverTypeName(decl.Recv.List[0].Type) + "." + name
+				}
+				dirs[name] = parseDirectives(decl.Doc)
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok {
+						dirs[ts.Name.Name] = parseDirectives(decl.Doc, ts.Doc)
+					}
+				}
+			}
+		}
+	}
+
+	return dirs
+}
+
+// isPointerReceiver reports whether a method's receiver is a pointer, as in
+// func (r *T) rather than func (r T).
+func isPointerReceiver(recv *ast.FieldList) bool {
+	if recv == nil || len(recv.List) == 0 {
+		return false
+	}
+
+	_, ok := ast.Unparen(recv.List[0].Type).(*ast.StarExpr)
+	return ok
+}
+
+func receiverTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexListExpr:
+		return receiverTypeName(t.X)
+	case *ast.ParenExpr:
+		return receiverTypeName(t.X)
+	case *ast.Ident:
+		return t.Name
+	default:
+		return ""
+	}
+}
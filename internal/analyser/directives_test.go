@@ -0,0 +1,74 @@
+package analyser
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDirectives(t *testing.T) {
+	dir := writePackage(t, map[string]string{
+		"store.go": `package store
+
+// Put stores v.
+//
+//docura:example examples/put.go
+func Put(v string) {}
+
+// Internal is exported for tests only.
+//
+//docura:hidden
+func Internal() {}
+
+// Mode selects how values are stored.
+//
+//docura:kind enum
+type Mode int
+
+// Secret is not documented.
+//
+//docura:hidden
+type Secret struct{}
+
+// Store holds values.
+type Store struct{}
+
+// Get gets a value.
+func (s *Store) Get() string { return "" }
+
+// Reset is not documented.
+//
+//docura:hidden
+func (s *Store) Reset() {}
+`,
+		"examples/put.go": "store.Put(\"value\")\n",
+	})
+
+	info, err := NewAnalyser().AnalysePackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var functions []string
+	for _, fn := range info.Functions {
+		functions = append(functions, fn.Name)
+		if fn.Name == "Put" && (len(fn.Examples) != 1 || !strings.Contains(fn.Examples[0], `store.Put("value")`)) {
+			t.Errorf("Put examples: got %q, want examples/put.go", fn.Examples)
+		}
+	}
+	if slices.Contains(functions, "Internal") || slices.Contains(functions, "Reset") {
+		t.Errorf("hidden functions documented: %v", functions)
+	}
+
+	for _, typ := range info.Types {
+		if typ.Name == "Secret" {
+			t.Error("hidden type Secret documented")
+		}
+	}
+	if mode := findType(t, info, "Mode"); mode.Kind != "enum" {
+		t.Errorf("Mode kind: got %q, want enum", mode.Kind)
+	}
+	if store := findType(t, info, "Store"); !slices.Equal(store.Methods, []string{"Get"}) {
+		t.Errorf("Store methods: got %v, want [Get]", store.Methods)
+	}
+}
@@ -0,0 +1,110 @@
+package analyser
+
+import (
+	"go/ast"
+	"sort"
+)
+
+// collectInterfaces returns every interface declared in pkg by name. It must
+// run before doc.New, which drops unexported interfaces and methods.
+func collectInterfaces(pkg *ast.Package) map[string]*ast.InterfaceType {
+	interfaces := make(map[string]*ast.InterfaceType)
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok {
+					if iface, ok := ts.Type.(*ast.InterfaceType); ok {
+						interfaces[ts.Name.Name] = iface
+					}
+				}
+			}
+		}
+	}
+	return interfaces
+}
+
+// extractEmbeds sorts the embedded elements of the named interface into the
+// interfaces it embeds, such as Reader or io.Closer, and the rest of its type
+// set, such as ~int | ~string. It also returns the interface's exported
+// methods, flattened to include those of the package's interfaces it embeds.
+func (a *Analyser) extractEmbeds(name string, interfaces map[string]*ast.InterfaceType) (embeds []string, typeSet []string, methodSet []string) {
+	iface, ok := interfaces[name]
+	if !ok {
+		return nil, nil, nil
+	}
+
+	for _, field := range iface.Methods.List {
+		if len(field.Names) > 0 {
+			continue
+		}
+		if embedded, ok := embeddedInterface(field.Type, interfaces); ok {
+			if ast.IsExported(embedded) || embedded == "error" {
+				embeds = append(embeds, a.typeToString(field.Type))
+			}
+			continue
+		}
+		typeSet = append(typeSet, a.exprToString(field.Type))
+	}
+
+	methods := make(map[string]string)
+	a.flattenMethods(name, interfaces, methods, make(map[string]bool))
+	for method, sig := range methods {
+		methodSet = append(methodSet, method+sig)
+	}
+	sort.Strings(methodSet)
+
+	return embeds, typeSet, methodSet
+}
+
+// embeddedInterface reports whether an embedded interface element names an
+// interface rather than a type set term. Qualified names are assumed to be
+// interfaces, as are the package's own interfaces and error.
+func embeddedInterface(expr ast.Expr, interfaces map[string]*ast.InterfaceType) (string, bool) {
+	switch t := expr.(type) {
+	case *ast.IndexExpr:
+		return embeddedInterface(t.X, interfaces)
+	case *ast.IndexListExpr:
+		return embeddedInterface(t.X, interfaces)
+	case *ast.SelectorExpr:
+		return t.Sel.Name, true
+	case *ast.Ident:
+		_, local := interfaces[t.Name]
+		return t.Name, local || t.Name == "error"
+	}
+	return "", false
+}
+
+// flattenMethods adds the exported methods of the named interface, and of the
+// package's interfaces it embeds, to methods.
+func (a *Analyser) flattenMethods(name string, interfaces map[string]*ast.InterfaceType, methods map[string]string, seen map[string]bool) {
+	if name == "error" {
+		methods["Error"] = "() string"
+		return
+	}
+
+	iface, ok := interfaces[name]
+	if !ok || seen[name] {
+		return
+	}
+	seen[name] = true
+
+	for _, field := range iface.Methods.List {
+		if fn, ok := field.Type.(*ast.FuncType); ok {
+			for _, method := range field.Names {
+				if ast.IsExported(method.Name) {
+					methods[method.Name] = a.funcTypeString(fn)
+				}
+			}
+			continue
+		}
+		if embedded, ok := embeddedInterface(field.Type, interfaces); ok {
+			if _, qualified := field.Type.(*ast.SelectorExpr); !qualified {
+				a.flattenMethods(embedded, interfaces, methods, seen)
+			}
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package analyser
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEmbeds(t *testing.T) {
+	info := analyseSource(t, `package p
+
+import "io"
+
+type Reader interface {
+	Read(p []byte) (int, error)
+}
+
+type Writer interface {
+	Write(p []byte) (int, error)
+}
+
+type ReadWriter interface {
+	Reader
+	Writer
+}
+
+type ReadWriteCloser interface {
+	ReadWriter
+	io.Closer
+	Flush() error
+}
+`)
+
+	rw := findType(t, info, "ReadWriter")
+	if !slices.Equal(rw.Embeds, []string{"Reader", "Writer"}) {
+		t.Errorf("ReadWriter embeds %q, want Reader and Writer", rw.Embeds)
+	}
+	if want := []string{"Read(p []byte) (int, error)", "Write(p []byte) (int, error)"}; !slices.Equal(rw.MethodSet, want) {
+		t.Errorf("ReadWriter method set %q, want %q", rw.MethodSet, want)
+	}
+
+	rwc := findType(t, info, "ReadWriteCloser")
+	if !slices.Equal(rwc.Embeds, []string{"ReadWriter", "io.Closer"}) {
+		t.Errorf("ReadWriteCloser embeds %q, want ReadWriter and io.Closer", rwc.Embeds)
+	}
+	for _, want := range []string{"Flush() error", "Read(p []byte) (int, error)", "Write(p []byte) (int, error)"} {
+		if !slices.Contains(rwc.MethodSet, want) {
+			t.Errorf("ReadWriteCloser method set %q missing %q", rwc.MethodSet, want)
+		}
+	}
+}
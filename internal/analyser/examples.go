@@ -0,0 +1,93 @@
+package analyser
+
+import (
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/printer"
+	"strings"
+	"unicode"
+)
+
+// extractTestExamples returns the testable Example functions in the package's
+// _test.go files, with the output they are checked against.
+func (a *Analyser) extractTestExamples(src source) []ExampleInfo {
+	entries, err := src.readDir()
+	if err != nil {
+		return nil
+	}
+
+	var files []*ast.File
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), "_test.go") {
+			continue
+		}
+		data, err := src.readFile(entry.Name())
+		if err != nil {
+			continue
+		}
+		file, err := parser.ParseFile(a.fset, src.join(entry.Name()), data, parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		files = append(files, file)
+	}
+
+	var examples []ExampleInfo
+	for _, ex := range doc.Examples(files...) {
+		examples = append(examples, ExampleInfo{
+			Name:     ex.Name,
+			Code:     a.exampleBody(ex),
+			Doc:      ex.Doc,
+			Output:   strings.TrimSpace(ex.Output),
+			Symbol:   exampleSymbol(ex.Name),
+			Testable: true,
+		})
+	}
+
+	return examples
+}
+
+// exampleBody prints an example function's body without its braces or its
+// output comment.
+func (a *Analyser) exampleBody(ex *doc.Example) string {
+	var buf strings.Builder
+	node := &printer.CommentedNode{Node: ex.Code, Comments: ex.Comments}
+	if err := gofmtConfig.Fprint(&buf, a.fset, node); err != nil {
+		return ""
+	}
+
+	body := strings.TrimSpace(buf.String())
+	body = strings.TrimSuffix(strings.TrimPrefix(body, "{"), "}")
+
+	var lines []string
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "// Output:") || strings.HasPrefix(trimmed, "// Unordered output:") {
+			break
+		}
+		lines = append(lines, strings.TrimPrefix(line, "\t"))
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// exampleSymbol returns the symbol an example documents from its name, e.g.
+// "Client.Do" for ExampleClient_Do, or "" for package examples. A lower-case
+// suffix, as in ExampleParse_strict, only tells examples apart.
+func exampleSymbol(name string) string {
+	symbol, rest, _ := strings.Cut(name, "_")
+	if rest != "" {
+		if method, _, _ := strings.Cut(rest, "_"); startsUpper(method) {
+			symbol += "." + method
+		}
+	}
+	return symbol
+}
+
+func startsUpper(s string) bool {
+	for _, r := range s {
+		return unicode.IsUpper(r)
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package analyser
+
+import (
+	"go/ast"
+)
+
+// collectStructFields returns the fields, unexported ones included, of every
+// struct type declared in pkg by name. It must run before doc.New, which
+// drops unexported fields.
+func (a *Analyser) collectStructFields(pkg *ast.Package) map[string][]FieldInfo {
+	fields := make(map[string][]FieldInfo)
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok {
+					if structType, ok := ts.Type.(*ast.StructType); ok {
+						fields[ts.Name.Name] = a.extractFields(structType)
+					}
+				}
+			}
+		}
+	}
+	return fields
+}
+
+// embeddedFieldName returns the name an embedded field is known by, e.g.
+// "Mutex" for *sync.Mutex.
+func embeddedFieldName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedFieldName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	}
+	return receiverTypeName(expr)
+}
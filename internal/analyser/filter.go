@@ -0,0 +1,125 @@
+package analyser
+
+import "strings"
+
+// FilterSymbols restricts pkg to the named symbols, written Foo for functions,
+// types, constants and variables or Type.Method for methods. Types used by the
+// kept functions and fields are kept too so their links still resolve. Naming
+// a type keeps all of its methods. It returns the names found in pkg.
+func FilterSymbols(pkg *PackageInfo, names []string) []string {
+	keepFuncs := make(map[string]bool) // Name, or Type.Name for methods
+	keepTypes := make(map[string]bool)
+	allMethods := make(map[string]bool) // types whose methods are all kept
+	keepValues := make(map[string]bool)
+
+	typeNames := make(map[string]bool)
+	for _, typ := range pkg.Types {
+		typeNames[typ.Name] = true
+	}
+
+	var found []string
+	for _, name := range names {
+		matched := false
+		for _, fn := range pkg.Functions {
+			if functionKey(fn) == name {
+				keepFuncs[name] = true
+				if fn.IsMethod {
+					keepTypes[fn.Receiver] = true
+				}
+				matched = true
+			}
+		}
+		if typeNames[name] {
+			keepTypes[name] = true
+			allMethods[name] = true
+			matched = true
+		}
+		for _, c := range pkg.Constants {
+			matched = matched || c.Name == name
+		}
+		for _, v := range pkg.Variables {
+			matched = matched || v.Name == name
+		}
+		if matched {
+			keepValues[name] = true
+			found = append(found, name)
+		}
+	}
+
+	var functions []FunctionInfo
+	for _, fn := range pkg.Functions {
+		if keepFuncs[functionKey(fn)] || (fn.IsMethod && allMethods[fn.Receiver]) {
+			functions = append(functions, fn)
+			markReferencedTypes(fn.Signature, typeNames, keepTypes)
+		}
+	}
+
+	// Follow field types until no new dependencies are found
+	for changed := true; changed; {
+		changed = false
+		for _, typ := range pkg.Types {
+			if !keepTypes[typ.Name] {
+				continue
+			}
+			for _, field := range typ.Fields {
+				changed = markReferencedTypes(field.Type, typeNames, keepTypes) || changed
+			}
+		}
+	}
+
+	var types []TypeInfo
+	for _, typ := range pkg.Types {
+		if !keepTypes[typ.Name] {
+			continue
+		}
+		var methods []string
+		for _, method := range typ.Methods {
+			if allMethods[typ.Name] || keepFuncs[typ.Name+"."+method] {
+				methods = append(methods, method)
+			}
+		}
+		typ.Methods = methods
+		types = append(types, typ)
+	}
+
+	var constants []ConstantInfo
+	for _, c := range pkg.Constants {
+		if keepValues[c.Name] {
+			constants = append(constants, c)
+		}
+	}
+
+	var variables []VariableInfo
+	for _, v := range pkg.Variables {
+		if keepValues[v.Name] {
+			variables = append(variables, v)
+		}
+	}
+
+	pkg.Functions, pkg.Types = functions, types
+	pkg.Constants, pkg.Variables = constants, variables
+
+	return found
+}
+
+func functionKey(fn FunctionInfo) string {
+	if fn.IsMethod {
+		return fn.Receiver + "." + fn.Name
+	}
+	return fn.Name
+}
+
+// markReferencedTypes adds the package's types mentioned in text to keep,
+// reporting whether any were new.
+func markReferencedTypes(text string, typeNames map[string]bool, keep map[string]bool) bool {
+	added := false
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return !(r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+	}) {
+		if typeNames[word] && !keep[word] {
+			keep[word] = true
+			added = true
+		}
+	}
+	return added
+}
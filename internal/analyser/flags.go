@@ -0,0 +1,114 @@
+package analyser
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// flagTypes are the value types of the flag and pflag definition functions,
+// e.g. String, StringVar, StringP and StringVarP for "String".
+var flagTypes = map[string]bool{
+	"String": true, "Bool": true, "Duration": true,
+	"Int": true, "Int8": true, "Int16": true, "Int32": true, "Int64": true,
+	"Uint": true, "Uint8": true, "Uint16": true, "Uint32": true, "Uint64": true,
+	"Float32": true, "Float64": true,
+	"StringSlice": true, "StringArray": true, "IntSlice": true, "BoolSlice": true,
+	"StringToString": true,
+}
+
+// extractFlags finds command line flags registered with the standard flag
+// package or pflag (as used by cobra), in the order they are defined. This is
+// best effort: only definitions whose flag name is a string literal are found.
+func (a *Analyser) extractFlags(pkg *ast.Package) []FlagInfo {
+	names := make([]string, 0, len(pkg.Files))
+	for name := range pkg.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var flags []FlagInfo
+	seen := make(map[string]bool)
+	for _, name := range names {
+		ast.Inspect(pkg.Files[name], func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			if flag, ok := a.flagDefinition(call); ok && !seen[flag.Name] {
+				seen[flag.Name] = true
+				flags = append(flags, flag)
+			}
+			return true
+		})
+	}
+
+	return flags
+}
+
+// flagDefinition matches calls such as flag.String("name", "default", "usage")
+// or cmd.Flags().StringVarP(&v, "name", "n", "default", "usage").
+func (a *Analyser) flagDefinition(call *ast.CallExpr) (FlagInfo, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return FlagInfo{}, false
+	}
+
+	method := sel.Sel.Name
+	method, hasShorthand := strings.CutSuffix(method, "P")
+	method, isVar := strings.CutSuffix(method, "Var")
+	if !flagTypes[method] {
+		return FlagInfo{}, false
+	}
+
+	args := call.Args
+	if isVar {
+		if len(args) == 0 {
+			return FlagInfo{}, false
+		}
+		args = args[1:]
+	}
+
+	want := 3
+	if hasShorthand {
+		want = 4
+	}
+	if len(args) != want {
+		return FlagInfo{}, false
+	}
+
+	name, ok := stringLiteral(args[0])
+	if !ok {
+		return FlagInfo{}, false
+	}
+
+	flag := FlagInfo{
+		Name:    name,
+		Type:    strings.ToLower(method[:1]) + method[1:],
+		Default: a.exprToString(args[len(args)-2]),
+		Usage:   a.exprToString(args[len(args)-1]),
+	}
+	if usage, ok := stringLiteral(args[len(args)-1]); ok {
+		flag.Usage = usage
+	}
+	if hasShorthand {
+		flag.Shorthand, _ = stringLiteral(args[1])
+	}
+
+	return flag, true
+}
+
+func stringLiteral(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+
+	value, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", false
+	}
+	return value, true
+}
@@ -0,0 +1,55 @@
+package analyser
+
+import "testing"
+
+func TestExtractFlags(t *testing.T) {
+	dir := writePackage(t, map[string]string{
+		"main.go": `package main
+
+import (
+	"flag"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+var (
+	verbose bool
+	output  string
+)
+
+var name = flag.String("name", "world", "who to greet")
+
+func main() {
+	var count int
+	flag.IntVar(&count, "count", 1, "how many times")
+	flag.Duration("timeout", 5*time.Second, "how long to wait")
+
+	cmd := &cobra.Command{}
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "print more")
+	cmd.PersistentFlags().StringP("output", "o", "-", "where to write")
+}
+`,
+	})
+
+	info, err := NewAnalyser().AnalysePackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// In the order they are defined, with defaults as written
+	want := []FlagInfo{
+		{Name: "name", Type: "string", Default: `"world"`, Usage: "who to greet"},
+		{Name: "count", Type: "int", Default: "1", Usage: "how many times"},
+		{Name: "timeout", Type: "duration", Default: "5 * time.Second", Usage: "how long to wait"},
+		{Name: "verbose", Shorthand: "v", Type: "bool", Default: "false", Usage: "print more"},
+		{Name: "output", Shorthand: "o", Type: "string", Default: `"-"`, Usage: "where to write"},
+	}
+	if len(info.Flags) != len(want) {
+		t.Fatalf("got flags %+v, want %+v", info.Flags, want)
+	}
+	for i := range want {
+		if info.Flags[i] != want[i] {
+			t.Errorf("flag %d: got %+v, want %+v", i, info.Flags[i], want[i])
+		}
+	}
+}
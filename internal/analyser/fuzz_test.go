@@ -0,0 +1,51 @@
+package analyser
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func FuzzAnalyse(f *testing.F) {
+	for _, seed := range []string{
+		"package p\n\nfunc F(a ...int) (n int, err error) { return }\n",
+		"package p\n\ntype T[K comparable, V any] map[K]V\n\nfunc G[T ~int | ~string](m map[T][]*T) T { var t T; return t }\n",
+		"package p\n\nimport \"net/http\"\n\nfunc M() func(http.Handler) http.Handler { return nil }\n",
+		"package p\n\ntype S struct {\n\tA struct{ B int `json:\"b\"` }\n\tC interface{ D() (int, error) }\n\tE chan (<-chan int)\n\tF [4]func(...string)\n}\n",
+		"package p\n\nimport \"sync/atomic\"\n\nvar V atomic.Pointer[map[string]int]\n\nconst C = (1 << 10) + len(\"x\")\n",
+		"package p\n\nfunc (p *(T)) M(x (int)) {}\n\ntype T struct{}\n",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, src string) {
+		fsys := fstest.MapFS{"p/p.go": {Data: []byte(src)}}
+		info, err := NewAnalyser().AnalysePackageFS(fsys, "p")
+		if err != nil {
+			return
+		}
+
+		check := func(what, s string) {
+			if strings.Contains(s, "unknown") && !strings.Contains(src, "unknown") || s == "..." {
+				t.Errorf("%s rendered as %q from:\n%s", what, s, src)
+			}
+		}
+		for _, fn := range info.Functions {
+			check(fn.Name+" signature", fn.Signature)
+			for _, param := range fn.Parameters {
+				check(fn.Name+" parameter", param.Type)
+			}
+			for _, result := range fn.Returns {
+				check(fn.Name+" result", result.Type)
+			}
+		}
+		for _, typ := range info.Types {
+			for _, field := range typ.Fields {
+				check(typ.Name+"."+field.Name, field.Type)
+			}
+		}
+		for _, c := range info.Constants {
+			check(c.Name, c.Value)
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package analyser
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFile is the name of the file listing paths to leave out of the
+// documentation, read from the project root.
+const IgnoreFile = ".docuraignore"
+
+// IgnoreRules holds the gitignore-style patterns read from an IgnoreFile.
+// A nil *IgnoreRules ignores nothing.
+type IgnoreRules struct {
+	root     string
+	patterns []ignorePattern
+}
+
+type ignorePattern struct {
+	segments []string // pattern split on "/", where "**" matches any number of segments
+	negate   bool     // pattern started with "!" and re-includes matches
+	dirOnly  bool     // pattern ended with "/" and only matches directories
+}
+
+// LoadIgnoreRules reads the IgnoreFile in root. It returns nil rules, and no
+// error, when root has no IgnoreFile.
+func LoadIgnoreRules(root string) (*IgnoreRules, error) {
+	f, err := os.Open(filepath.Join(root, IgnoreFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", IgnoreFile, err)
+	}
+	defer f.Close()
+
+	rules := &IgnoreRules{root: root}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var p ignorePattern
+		if rest, ok := strings.CutPrefix(line, "!"); ok {
+			p.negate = true
+			line = rest
+		}
+		if rest, ok := strings.CutSuffix(line, "/"); ok {
+			p.dirOnly = true
+			line = rest
+		}
+
+		// Like .gitignore, a pattern without a slash matches at any depth
+		if !strings.Contains(line, "/") {
+			line = "**/" + line
+		}
+		p.segments = strings.Split(strings.TrimPrefix(line, "/"), "/")
+		rules.patterns = append(rules.patterns, p)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", IgnoreFile, err)
+	}
+
+	return rules, nil
+}
+
+// Ignored reports whether path, a file or directory below the project root,
+// is excluded by the rules, either directly or through one of its parent
+// directories.
+func (r *IgnoreRules) Ignored(p string, isDir bool) bool {
+	if r == nil || len(r.patterns) == 0 {
+		return false
+	}
+
+	rel, err := filepath.Rel(r.root, p)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+
+	segments := strings.Split(filepath.ToSlash(rel), "/")
+	for i := 1; i < len(segments); i++ {
+		if r.match(segments[:i], true) {
+			return true
+		}
+	}
+	return r.match(segments, isDir)
+}
+
+// match applies the patterns in order, so later ones override earlier ones.
+func (r *IgnoreRules) match(segments []string, isDir bool) bool {
+	ignored := false
+	for _, p := range r.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if matchSegments(p.segments, segments) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+func matchSegments(pattern []string, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
@@ -0,0 +1,62 @@
+package analyser
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnoreRules(t *testing.T) {
+	root := writePackage(t, map[string]string{
+		IgnoreFile: "# generated code\n*_gen.go\ninternal/legacy/\n!keep_gen.go\n/tools\n",
+	})
+	rules, err := LoadIgnoreRules(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"api_gen.go", false, true},
+		{"store/model_gen.go", false, true},
+		{"store/keep_gen.go", false, false},
+		{"store/store.go", false, false},
+		{"internal/legacy", true, true},
+		{"internal/legacy", false, false},
+		{"tools", true, true},
+		{"cmd/tools", true, false},
+	}
+	for _, test := range tests {
+		if got := rules.Ignored(filepath.Join(root, filepath.FromSlash(test.path)), test.isDir); got != test.want {
+			t.Errorf("Ignored(%s, dir=%t) = %t, want %t", test.path, test.isDir, got, test.want)
+		}
+	}
+
+	if rules, err := LoadIgnoreRules(t.TempDir()); rules != nil || err != nil || rules.Ignored("x.go", false) {
+		t.Errorf("project without %s: got %v, %v", IgnoreFile, rules, err)
+	}
+}
+
+func TestIgnoredFileLeftOut(t *testing.T) {
+	root := writePackage(t, map[string]string{
+		IgnoreFile:  "secret.go\n",
+		"public.go": "package p\n\n// Public is documented.\nfunc Public() {}\n",
+		"secret.go": "package p\n\n// Secret is ignored.\nfunc Secret() {}\n",
+	})
+	rules, err := LoadIgnoreRules(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewAnalyser()
+	a.Ignore = rules
+	info, err := a.AnalysePackage(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Functions) != 1 || info.Functions[0].Name != "Public" {
+		t.Errorf("got functions %+v, want only Public", info.Functions)
+	}
+}
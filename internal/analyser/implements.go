@@ -0,0 +1,128 @@
+package analyser
+
+import (
+	"go/ast"
+	"sort"
+	"strings"
+)
+
+// extractImplements finds, for each type declared in pkg, the exported
+// interfaces of pkg that the type or a pointer to it implements, keyed by type
+// name. Method sets are compared by name and signature without type checking,
+// so interfaces embedding interfaces from other packages and generic types are
+// left out.
+func (a *Analyser) extractImplements(pkg *ast.Package) map[string][]string {
+	interfaces := make(map[string]*ast.InterfaceType)
+	var concrete []string
+	methods := make(map[string]map[string]string)
+
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil || len(decl.Recv.List) == 0 {
+					continue
+				}
+				recv := receiverTypeName(decl.Recv.List[0].Type)
+				if methods[recv] == nil {
+					methods[recv] = make(map[string]string)
+				}
+				methods[recv][decl.Name.Name] = a.signatureTypes(decl.Type)
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					ts, ok := spec.(*ast.TypeSpec)
+					if !ok || ts.TypeParams != nil {
+						continue
+					}
+					if iface, ok := ts.Type.(*ast.InterfaceType); ok {
+						interfaces[ts.Name.Name] = iface
+					} else {
+						concrete = append(concrete, ts.Name.Name)
+					}
+				}
+			}
+		}
+	}
+
+	methodSets := make(map[string]map[string]string)
+	for name := range interfaces {
+		if !ast.IsExported(name) {
+			continue
+		}
+		if set, ok := a.interfaceMethods(name, interfaces, make(map[string]bool)); ok && len(set) > 0 {
+			methodSets[name] = set
+		}
+	}
+
+	implements := make(map[string][]string)
+	for _, typ := range concrete {
+		for name, set := range methodSets {
+			if hasMethods(methods[typ], set) {
+				implements[typ] = append(implements[typ], name)
+			}
+		}
+		sort.Strings(implements[typ])
+	}
+
+	return implements
+}
+
+// interfaceMethods returns the method names and signatures of the named
+// interface, including those of embedded interfaces declared in the package.
+// It reports false if the interface embeds anything else.
+func (a *Analyser) interfaceMethods(name string, interfaces map[string]*ast.InterfaceType, seen map[string]bool) (map[string]string, bool) {
+	iface, ok := interfaces[name]
+	if !ok || seen[name] {
+		return nil, false
+	}
+	seen[name] = true
+
+	set := make(map[string]string)
+	for _, field := range iface.Methods.List {
+		if fn, ok := field.Type.(*ast.FuncType); ok && len(field.Names) == 1 {
+			set[field.Names[0].Name] = a.signatureTypes(fn)
+			continue
+		}
+
+		ident, ok := field.Type.(*ast.Ident)
+		if !ok {
+			return nil, false
+		}
+		embedded, ok := a.interfaceMethods(ident.Name, interfaces, seen)
+		if !ok {
+			return nil, false
+		}
+		for method, sig := range embedded {
+			set[method] = sig
+		}
+	}
+
+	return set, true
+}
+
+func hasMethods(methods map[string]string, required map[string]string) bool {
+	for name, sig := range required {
+		if methods[name] != sig {
+			return false
+		}
+	}
+	return true
+}
+
+// signatureTypes renders a function type without its parameter names, so
+// (key string) and (k string) compare equal.
+func (a *Analyser) signatureTypes(fn *ast.FuncType) string {
+	var b strings.Builder
+	for _, list := range []*ast.FieldList{fn.Params, fn.Results} {
+		b.WriteString("(")
+		if list != nil {
+			for _, field := range list.List {
+				for i := 0; i < max(len(field.Names), 1); i++ {
+					b.WriteString(a.typeToString(field.Type) + ",")
+				}
+			}
+		}
+		b.WriteString(")")
+	}
+	return b.String()
+}
@@ -0,0 +1,45 @@
+package analyser
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestImplements(t *testing.T) {
+	a := NewAnalyser()
+	a.Implements = true
+	info, err := a.AnalysePackage(writePackage(t, map[string]string{"shapes.go": `package shapes
+
+type Shape interface {
+	Area() float64
+}
+
+type Named interface {
+	Shape
+	Name() string
+}
+
+type Square struct{ Side float64 }
+
+func (s Square) Area() float64 { return s.Side * s.Side }
+
+func (s *Square) Name() string { return "square" }
+
+type Line struct{}
+
+func (Line) Length() float64 { return 0 }
+`}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := findType(t, info, "Square").Implements; !slices.Equal(got, []string{"Named", "Shape"}) {
+		t.Errorf("Square implements %q, want Named and Shape", got)
+	}
+	if got := findType(t, info, "Line").Implements; len(got) > 0 {
+		t.Errorf("Line implements %q, want none", got)
+	}
+	if got := findType(t, info, "Shape").Implements; len(got) > 0 {
+		t.Errorf("interface Shape implements %q, want none", got)
+	}
+}
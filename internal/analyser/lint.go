@@ -0,0 +1,105 @@
+package analyser
+
+// MissingDoc identifies an exported symbol whose doc comment is absent or
+// shorter than the required length.
+type MissingDoc struct {
+	Package string `json:"package"`
+	Kind    string `json:"kind"` // function, method, type, constant or variable
+	Name    string `json:"name"`
+}
+
+// FindMissingDocs reports every exported symbol in pkg whose description is
+// shorter than minLength characters. A minLength below 1 only flags symbols
+// with no documentation at all. It must be called before any AI enhancement.
+func FindMissingDocs(pkg *PackageInfo, minLength int) []MissingDoc {
+	if minLength < 1 {
+		minLength = 1
+	}
+
+	var missing []MissingDoc
+	forEachExported(pkg, func(kind, name, description string) {
+		if len(description) < minLength {
+			missing = append(missing, MissingDoc{Package: pkg.Path, Kind: kind, Name: name})
+		}
+	})
+
+	return missing
+}
+
+// DocCoverage counts how many of a package's exported symbols have a doc
+// comment.
+type DocCoverage struct {
+	Package    string  `json:"package,omitempty"`
+	Documented int     `json:"documented"`
+	Total      int     `json:"total"`
+	Percent    float64 `json:"percent"`
+}
+
+// Coverage measures how much of pkg's exported API is documented. Like
+// FindMissingDocs, it must be called before any AI enhancement.
+func Coverage(pkg *PackageInfo) DocCoverage {
+	coverage := DocCoverage{Package: pkg.Path}
+	forEachExported(pkg, func(kind, name, description string) {
+		coverage.Total++
+		if description != "" {
+			coverage.Documented++
+		}
+	})
+	coverage.Percent = percent(coverage.Documented, coverage.Total)
+
+	return coverage
+}
+
+// TotalCoverage sums the coverage of several packages.
+func TotalCoverage(packages []DocCoverage) DocCoverage {
+	var total DocCoverage
+	for _, c := range packages {
+		total.Documented += c.Documented
+		total.Total += c.Total
+	}
+	total.Percent = percent(total.Documented, total.Total)
+
+	return total
+}
+
+// percent returns documented as a percentage of total, treating a package
+// with nothing exported as fully documented.
+func percent(documented, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return float64(documented) * 100 / float64(total)
+}
+
+// forEachExported calls report with the kind, name and description of every
+// exported symbol in pkg.
+func forEachExported(pkg *PackageInfo, report func(kind, name, description string)) {
+	for _, fn := range pkg.Functions {
+		if !fn.IsExported {
+			continue
+		}
+		if fn.IsMethod {
+			report("method", fn.Receiver+"."+fn.Name, fn.Description)
+		} else {
+			report("function", fn.Name, fn.Description)
+		}
+	}
+
+	for _, typ := range pkg.Types {
+		if typ.IsExported {
+			report("type", typ.Name, typ.Description)
+		}
+	}
+
+	for _, c := range pkg.Constants {
+		if c.IsExported {
+			report("constant", c.Name, c.Description)
+		}
+	}
+
+	for _, v := range pkg.Variables {
+		if v.IsExported {
+			report("variable", v.Name, v.Description)
+		}
+	}
+}
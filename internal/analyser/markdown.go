@@ -0,0 +1,137 @@
+package analyser
+
+import "strings"
+
+// markdownExampleFiles are checked, in order, for hand-written usage examples.
+var markdownExampleFiles = []string{"README.md", "examples.md"}
+
+// extractMarkdownExamples collects the fenced Go blocks from the Markdown files
+// next to a package, skipping any block that repeats an earlier one.
+func extractMarkdownExamples(src source) []ExampleInfo {
+	var examples []ExampleInfo
+	seen := make(map[string]bool)
+
+	for _, name := range markdownExampleFiles {
+		data, err := src.readFile(name)
+		if err != nil {
+			continue
+		}
+
+		for _, example := range parseMarkdownExamples(string(data), name) {
+			key := strings.Join(strings.Fields(example.Code), " ")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			examples = append(examples, example)
+		}
+	}
+
+	return examples
+}
+
+// parseMarkdownExamples returns each ```go block in source, named after the
+// heading it appears under.
+func parseMarkdownExamples(source string, file string) []ExampleInfo {
+	var examples []ExampleInfo
+	var block []string
+	heading := ""
+	inBlock, isGo := false, false
+
+	for _, line := range strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if fence, ok := strings.CutPrefix(trimmed, "```"); ok {
+			if !inBlock {
+				inBlock = true
+				lang := strings.ToLower(strings.TrimSpace(fence))
+				isGo = lang == "go" || lang == "golang"
+				block = block[:0]
+				continue
+			}
+
+			inBlock = false
+			code := strings.TrimSpace(strings.Join(block, "\n"))
+			if isGo && code != "" {
+				name := heading
+				if name == "" {
+					name = "Example"
+				}
+				examples = append(examples, ExampleInfo{
+					Name: name,
+					Code: code,
+					Doc:  "From " + file,
+				})
+			}
+			continue
+		}
+
+		if inBlock {
+			block = append(block, line)
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "#") {
+			heading = strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+		}
+	}
+
+	return examples
+}
+
+// extractReadme returns the body of the README.md next to a package without
+// its title, leaving out the paragraphs that repeat the package doc.
+func extractReadme(src source, doc string) string {
+	data, err := src.readFile("README.md")
+	if err != nil {
+		return ""
+	}
+
+	known := make(map[string]bool)
+	for _, para := range strings.Split(doc, "\n\n") {
+		known[strings.Join(strings.Fields(para), " ")] = true
+	}
+
+	var kept []string
+	for i, para := range markdownParagraphs(string(data)) {
+		if i == 0 && strings.HasPrefix(para, "# ") {
+			continue
+		}
+		if known[strings.Join(strings.Fields(para), " ")] {
+			continue
+		}
+		kept = append(kept, para)
+	}
+
+	return strings.Join(kept, "\n\n")
+}
+
+// markdownParagraphs splits source on blank lines, keeping each fenced code
+// block whole.
+func markdownParagraphs(source string) []string {
+	var paras []string
+	var para []string
+	inBlock := false
+
+	flush := func() {
+		if text := strings.TrimSpace(strings.Join(para, "\n")); text != "" {
+			paras = append(paras, text)
+		}
+		para = para[:0]
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inBlock = !inBlock
+		}
+		if trimmed == "" && !inBlock {
+			flush()
+			continue
+		}
+		para = append(para, strings.TrimRight(line, " \t"))
+	}
+	flush()
+
+	return paras
+}
@@ -0,0 +1,62 @@
+package analyser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownExamples(t *testing.T) {
+	dir := writePackage(t, map[string]string{
+		"store.go":    "package store\n\n// Put stores v.\nfunc Put(v string) {}\n",
+		"README.md":   "# store\n\n## Storing values\n\n```go\nstore.Put(\"value\")\n```\n\n```bash\ngo get example.com/store\n```\n",
+		"examples.md": "## Again\n\n```go\nstore.Put(\"value\")\n```\n\n## Other\n\n```go\nstore.Put(\"other\")\n```\n",
+	})
+
+	info, err := NewAnalyser().AnalysePackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Examples) != 0 {
+		t.Errorf("got examples %v without MarkdownExamples", info.Examples)
+	}
+
+	a := NewAnalyser()
+	a.MarkdownExamples = true
+	if info, err = a.AnalysePackage(dir); err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Examples) != 2 {
+		t.Fatalf("got %d examples, want the README block and the new examples.md one: %+v", len(info.Examples), info.Examples)
+	}
+	if info.Examples[0].Name != "Storing values" || strings.TrimSpace(info.Examples[0].Code) != `store.Put("value")` {
+		t.Errorf("first example: got %+v", info.Examples[0])
+	}
+	if !strings.Contains(info.Examples[1].Code, `"other"`) {
+		t.Errorf("second example: got %+v", info.Examples[1])
+	}
+}
+
+func TestMergeReadme(t *testing.T) {
+	dir := writePackage(t, map[string]string{
+		"store.go":  "// Package store keeps values in memory.\npackage store\n",
+		"README.md": "# store\n\nPackage store keeps values\nin memory.\n\n## Limits\n\nValues larger than 1MB are rejected.\n\n```go\nstore.Put(\"value\")\n\nstore.Get()\n```\n",
+	})
+
+	info, err := NewAnalyser().AnalysePackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Readme != "" {
+		t.Errorf("got README %q without MergeReadme", info.Readme)
+	}
+
+	a := NewAnalyser()
+	a.MergeReadme = true
+	if info, err = a.AnalysePackage(dir); err != nil {
+		t.Fatal(err)
+	}
+	want := "## Limits\n\nValues larger than 1MB are rejected.\n\n```go\nstore.Put(\"value\")\n\nstore.Get()\n```"
+	if info.Readme != want {
+		t.Errorf("got README %q, want %q without the title or the paragraph repeating the package doc", info.Readme, want)
+	}
+}
@@ -0,0 +1,126 @@
+package analyser
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var errNoModule = errors.New("no go.mod found")
+
+// resolveImportPath computes the import path of the package in dir from the
+// module path declared in the nearest enclosing go.mod, which it also returns.
+func resolveImportPath(dir string) (importPath, modulePath string, err error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", "", err
+	}
+
+	for root := absDir; ; root = filepath.Dir(root) {
+		modulePath, err := readModulePath(filepath.Join(root, "go.mod"))
+		if err == nil {
+			rel, err := filepath.Rel(root, absDir)
+			if err != nil {
+				return "", "", err
+			}
+			return path.Join(modulePath, filepath.ToSlash(rel)), modulePath, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", "", err
+		}
+
+		if filepath.Dir(root) == root {
+			return "", "", errNoModule
+		}
+	}
+}
+
+// resolveImportPathFS is resolveImportPath for a slash-separated dir within fsys.
+func resolveImportPathFS(fsys fs.FS, dir string) (importPath, modulePath string, err error) {
+	dir = path.Clean(dir)
+	for root := dir; ; root = path.Dir(root) {
+		goMod := path.Join(root, "go.mod")
+		file, err := fsys.Open(goMod)
+		if err == nil {
+			modulePath, err := parseModulePath(file, goMod)
+			file.Close()
+			if err != nil {
+				return "", "", err
+			}
+			rel := strings.TrimPrefix(strings.TrimPrefix(dir, root), "/")
+			if root == "." {
+				rel = dir
+			}
+			return path.Join(modulePath, rel), modulePath, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", "", err
+		}
+
+		if root == "." || root == "/" {
+			return "", "", errNoModule
+		}
+	}
+}
+
+func readModulePath(goMod string) (string, error) {
+	file, err := os.Open(goMod)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	return parseModulePath(file, goMod)
+}
+
+func parseModulePath(r io.Reader, goMod string) (string, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if rest, ok := strings.CutPrefix(line, "module"); ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			modulePath, _, _ := strings.Cut(strings.TrimSpace(rest), "//")
+			modulePath = strings.TrimSpace(modulePath)
+			if unquoted, err := strconv.Unquote(modulePath); err == nil {
+				modulePath = unquoted
+			}
+			if modulePath != "" {
+				return modulePath, nil
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return "", fmt.Errorf("no module directive in %s", goMod)
+}
+
+// commandName returns the name go build gives the binary for a main package:
+// the last element of its import path, or of its directory without a module.
+func commandName(importPath string, dir string) string {
+	if importPath != "" {
+		return path.Base(importPath)
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	return filepath.Base(dir)
+}
+
+// isInternalPath reports whether a slash-separated path has an "internal"
+// element, making it importable only from within its parent tree.
+func isInternalPath(p string) bool {
+	for _, elem := range strings.Split(p, "/") {
+		if elem == "internal" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package analyser
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestImportPath(t *testing.T) {
+	dir := writePackage(t, map[string]string{
+		"go.mod":                  "// The module of the test project.\nmodule \"example.com/project\"\n\ngo 1.22\n",
+		"root.go":                 "package project\n",
+		"internal/store/store.go": "package store\n",
+	})
+
+	tests := []struct {
+		dir        string
+		importPath string
+		internal   bool
+	}{
+		{".", "example.com/project", false},
+		{"internal/store", "example.com/project/internal/store", true},
+	}
+	for _, tt := range tests {
+		info, err := NewAnalyser().AnalysePackage(filepath.Join(dir, tt.dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.ImportPath != tt.importPath || info.Module != "example.com/project" || info.IsInternal != tt.internal {
+			t.Errorf("%s: got import path %q module %q internal %t, want %q example.com/project %t", tt.dir, info.ImportPath, info.Module, info.IsInternal, tt.importPath, tt.internal)
+		}
+	}
+}
+
+func TestImportPathOutsideModule(t *testing.T) {
+	dir := writePackage(t, map[string]string{"store.go": "package store\n"})
+	if _, _, err := resolveImportPath(dir); err == nil {
+		// The temp dir may itself be inside a module on some machines
+		t.Skip("temp dir is inside a module")
+	}
+
+	info, err := NewAnalyser().AnalysePackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ImportPath != "" {
+		t.Errorf("got import path %q outside a module, want none", info.ImportPath)
+	}
+}
@@ -0,0 +1,66 @@
+package analyser
+
+import (
+	"go/build"
+	"go/parser"
+	"io"
+	"path"
+	"strings"
+)
+
+// Option configures how an Analyser parses packages.
+type Option func(*Analyser)
+
+// WithTests includes a package's in-package _test.go files, such as
+// export_test.go, in its API. Files of the external _test package are still
+// only read for their examples.
+func WithTests(include bool) Option {
+	return func(a *Analyser) {
+		a.tests = include
+	}
+}
+
+// WithBuildTags leaves out the files whose build constraints or _GOOS and
+// _GOARCH suffixes don't match the host platform with tags set. Without it
+// every file is read, whatever its constraints.
+func WithBuildTags(tags ...string) Option {
+	return func(a *Analyser) {
+		a.buildTags = append([]string{}, tags...)
+	}
+}
+
+// WithMode adds mode, such as parser.SkipObjectResolution, to the flags every
+// file is parsed with. Comments are always parsed.
+func WithMode(mode parser.Mode) Option {
+	return func(a *Analyser) {
+		a.mode = mode
+	}
+}
+
+// parseMode returns the flags files are parsed with.
+func (a *Analyser) parseMode() parser.Mode {
+	return a.mode | parser.ParseComments
+}
+
+// includes reports whether the named file in src is part of the package
+// analysed.
+func (a *Analyser) includes(src source, name string) bool {
+	if strings.HasSuffix(name, "_test.go") && !a.tests {
+		return false
+	}
+	if a.buildTags == nil {
+		return true
+	}
+
+	ctxt := build.Default
+	ctxt.BuildTags = a.buildTags
+	if src.fsys != nil {
+		ctxt.JoinPath = path.Join
+		ctxt.OpenFile = func(p string) (io.ReadCloser, error) {
+			return src.fsys.Open(p)
+		}
+	}
+
+	match, err := ctxt.MatchFile(src.dir, name)
+	return err == nil && match
+}
@@ -0,0 +1,84 @@
+package analyser
+
+import (
+	"go/parser"
+	"slices"
+	"testing"
+)
+
+func functionNames(info *PackageInfo) []string {
+	var names []string
+	for _, fn := range info.Functions {
+		names = append(names, fn.Name)
+	}
+	return names
+}
+
+func TestWithTests(t *testing.T) {
+	dir := writePackage(t, map[string]string{
+		"store.go":        "package store\n\n// Put stores v.\nfunc Put(v string) {}\n",
+		"export_test.go":  "package store\n\n// Reset empties the store for tests.\nfunc Reset() {}\n",
+		"example_test.go": "package store_test\n\nimport \"store\"\n\nfunc ExamplePut() {\n\tstore.Put(\"v\")\n}\n",
+	})
+
+	info, err := NewAnalyser().AnalysePackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := functionNames(info); !slices.Equal(got, []string{"Put"}) {
+		t.Errorf("functions without tests: got %v, want [Put]", got)
+	}
+
+	info, err = NewAnalyser(WithTests(true)).AnalysePackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := functionNames(info); !slices.Equal(got, []string{"Put", "Reset"}) {
+		t.Errorf("functions with tests: got %v, want [Put Reset]", got)
+	}
+	if len(info.Examples) != 1 || info.Examples[0].Symbol != "Put" {
+		t.Errorf("got examples %+v, want ExamplePut", info.Examples)
+	}
+}
+
+func TestWithBuildTags(t *testing.T) {
+	dir := writePackage(t, map[string]string{
+		"store.go":       "package store\n\n// Put stores v.\nfunc Put(v string) {}\n",
+		"debug.go":       "//go:build debug\n\npackage store\n\n// Dump prints the store.\nfunc Dump() {}\n",
+		"store_plan9.go": "package store\n\n// Sync flushes the store.\nfunc Sync() {}\n",
+	})
+
+	tests := []struct {
+		opts []Option
+		want []string
+	}{
+		{nil, []string{"Dump", "Put", "Sync"}},
+		{[]Option{WithBuildTags()}, []string{"Put"}},
+		{[]Option{WithBuildTags("debug")}, []string{"Dump", "Put"}},
+	}
+	for _, test := range tests {
+		info, err := NewAnalyser(test.opts...).AnalysePackage(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := functionNames(info)
+		slices.Sort(got)
+		if !slices.Equal(got, test.want) {
+			t.Errorf("%d options: got %v, want %v", len(test.opts), got, test.want)
+		}
+	}
+}
+
+func TestWithMode(t *testing.T) {
+	a := NewAnalyser(WithMode(parser.SkipObjectResolution))
+	if a.parseMode() != parser.SkipObjectResolution|parser.ParseComments {
+		t.Errorf("got mode %v, want comments parsed as well", a.parseMode())
+	}
+	info, err := a.AnalysePackage(writePackage(t, map[string]string{"pkg.go": "package p\n\n// F is documented.\nfunc F() {}\n"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Functions) != 1 || info.Functions[0].Description != "F is documented." {
+		t.Errorf("got functions %+v", info.Functions)
+	}
+}
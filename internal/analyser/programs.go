@@ -0,0 +1,103 @@
+package analyser
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ProgramExample is a runnable main package, such as examples/basic, kept as
+// an example of using the project's libraries.
+type ProgramExample struct {
+	ExampleInfo
+	Imports []string
+}
+
+// ProgramExamples returns the main packages under dir as usage examples, each
+// named after its directory relative to dir and holding its full source.
+func (a *Analyser) ProgramExamples(dir string) ([]ProgramExample, error) {
+	a = a.forPackage()
+	a.tests = false // an example's tests aren't part of its source
+	dirs, err := FindPackageDirs(dir, -1, a.Ignore)
+	if err != nil {
+		return nil, fmt.Errorf("finding example programs: %w", err)
+	}
+
+	var examples []ProgramExample
+	for _, pkgDir := range dirs {
+		src := source{dir: pkgDir}
+		pkgs, _, err := a.parsePackages(src)
+		if err != nil {
+			return nil, fmt.Errorf("parsing example program %s: %w", pkgDir, err)
+		}
+		pkg, ok := pkgs["main"]
+		if !ok {
+			continue
+		}
+
+		var filenames []string
+		for filename := range pkg.Files {
+			filenames = append(filenames, filename)
+		}
+		sort.Strings(filenames)
+
+		var code []string
+		var imports []string
+		for _, filename := range filenames {
+			data, err := src.readFile(filepath.Base(filename))
+			if err != nil {
+				return nil, fmt.Errorf("reading example program: %w", err)
+			}
+			code = append(code, strings.TrimSpace(string(data)))
+
+			for _, spec := range pkg.Files[filename].Imports {
+				if path, err := strconv.Unquote(spec.Path.Value); err == nil && !slices.Contains(imports, path) {
+					imports = append(imports, path)
+				}
+			}
+		}
+
+		name, err := filepath.Rel(dir, pkgDir)
+		if err != nil || name == "." {
+			name = filepath.Base(pkgDir)
+		}
+
+		examples = append(examples, ProgramExample{
+			ExampleInfo: ExampleInfo{
+				Name: filepath.ToSlash(name),
+				Code: strings.Join(code, "\n\n"),
+			},
+			Imports: imports,
+		})
+	}
+
+	return examples, nil
+}
+
+// AttachProgramExamples adds each example to the packages in pkgs that it
+// imports, as an example of the package as a whole. It returns the examples
+// that import none of them.
+func AttachProgramExamples(pkgs []*PackageInfo, examples []ProgramExample) []ProgramExample {
+	var unattached []ProgramExample
+	for _, ex := range examples {
+		attached := false
+		for _, pkg := range pkgs {
+			if pkg.ImportPath == "" || !slices.Contains(ex.Imports, pkg.ImportPath) {
+				continue
+			}
+			attached = true
+
+			// Cached packages may already carry the example from an earlier run
+			if !slices.Contains(pkg.Examples, ex.ExampleInfo) {
+				pkg.Examples = append(pkg.Examples, ex.ExampleInfo)
+			}
+		}
+		if !attached {
+			unattached = append(unattached, ex)
+		}
+	}
+	return unattached
+}
@@ -0,0 +1,126 @@
+package analyser
+
+import (
+	"go/ast"
+	"sort"
+	"strings"
+)
+
+// routerMethods maps the method names routers such as chi, gin and echo use
+// to register handlers to the HTTP method they handle.
+var routerMethods = map[string]string{
+	"Get": "GET", "Post": "POST", "Put": "PUT", "Patch": "PATCH",
+	"Delete": "DELETE", "Head": "HEAD", "Options": "OPTIONS",
+	"GET": "GET", "POST": "POST", "PUT": "PUT", "PATCH": "PATCH",
+	"DELETE": "DELETE", "HEAD": "HEAD", "OPTIONS": "OPTIONS",
+}
+
+// extractRoutes finds HTTP routes registered in pkg, with http.HandleFunc,
+// ServeMux.Handle or router methods such as r.Get, and functions with the
+// http.HandlerFunc signature that aren't registered anywhere in the package.
+// Only routes whose pattern is a string literal are found.
+func (a *Analyser) extractRoutes(pkg *ast.Package) []RouteInfo {
+	names := make([]string, 0, len(pkg.Files))
+	for name := range pkg.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var routes []RouteInfo
+	var handlers []string
+	registered := make(map[string]bool)
+	for _, name := range names {
+		ast.Inspect(pkg.Files[name], func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncDecl:
+				if n.Recv == nil && isHandlerFunc(n.Type) {
+					handlers = append(handlers, n.Name.Name)
+				}
+			case *ast.CallExpr:
+				if route, ok := a.routeRegistration(n); ok {
+					routes = append(routes, route)
+					registered[route.Handler] = true
+				}
+			}
+			return true
+		})
+	}
+
+	for _, handler := range handlers {
+		if !registered[handler] {
+			routes = append(routes, RouteInfo{Handler: handler})
+		}
+	}
+
+	return routes
+}
+
+func (a *Analyser) routeRegistration(call *ast.CallExpr) (RouteInfo, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || len(call.Args) < 2 {
+		return RouteInfo{}, false
+	}
+
+	pattern, ok := stringLiteral(call.Args[0])
+	if !ok {
+		return RouteInfo{}, false
+	}
+	handler := call.Args[len(call.Args)-1]
+	if conv, ok := handler.(*ast.CallExpr); ok && len(conv.Args) == 1 && a.exprToString(conv.Fun) == "http.HandlerFunc" {
+		handler = conv.Args[0]
+	}
+	if !isHandlerExpr(handler) {
+		return RouteInfo{}, false
+	}
+	route := RouteInfo{Path: pattern, Handler: a.exprToString(handler)}
+
+	switch method := sel.Sel.Name; method {
+	case "HandleFunc", "Handle":
+		// Go 1.22 patterns may start with a method, e.g. "GET /users/{id}"
+		if m, path, ok := strings.Cut(pattern, " "); ok && m == strings.ToUpper(m) {
+			route.Method, route.Path = m, strings.TrimSpace(path)
+		}
+	default:
+		if route.Method, ok = routerMethods[method]; !ok {
+			return RouteInfo{}, false
+		}
+	}
+
+	if !strings.HasPrefix(route.Path, "/") {
+		return RouteInfo{}, false
+	}
+
+	return route, true
+}
+
+// isHandlerExpr reports whether expr can name a handler: an identifier other
+// than nil, a selector or a function literal.
+func isHandlerExpr(expr ast.Expr) bool {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name != "nil"
+	case *ast.SelectorExpr, *ast.FuncLit:
+		return true
+	}
+	return false
+}
+
+// isHandlerFunc reports whether fn has the signature of an http.HandlerFunc.
+func isHandlerFunc(fn *ast.FuncType) bool {
+	if fn.Params == nil || len(fn.Params.List) != 2 || fn.Results != nil {
+		return false
+	}
+
+	isHTTP := func(expr ast.Expr, name string) bool {
+		sel, ok := expr.(*ast.SelectorExpr)
+		if !ok {
+			return false
+		}
+		pkg, ok := sel.X.(*ast.Ident)
+		return ok && pkg.Name == "http" && sel.Sel.Name == name
+	}
+
+	writer, request := fn.Params.List[0], fn.Params.List[1]
+	star, ok := request.Type.(*ast.StarExpr)
+	return len(writer.Names) <= 1 && isHTTP(writer.Type, "ResponseWriter") && ok && isHTTP(star.X, "Request")
+}
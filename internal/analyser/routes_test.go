@@ -0,0 +1,58 @@
+package analyser
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRoutes(t *testing.T) {
+	a := NewAnalyser()
+	a.HTTPRoutes = true
+	info, err := a.AnalysePackage(writePackage(t, map[string]string{"server.go": `package server
+
+import "net/http"
+
+func listUsers(w http.ResponseWriter, r *http.Request) {}
+
+func health(w http.ResponseWriter, r *http.Request) {}
+
+func orphan(w http.ResponseWriter, r *http.Request) {}
+
+type api struct{}
+
+func (api) getUser(w http.ResponseWriter, r *http.Request) {}
+
+func routes(a api) {
+	http.HandleFunc("GET /users", listUsers)
+	http.Handle("/health", http.HandlerFunc(health))
+	http.HandleFunc("/users/{id}", a.getUser)
+	http.HandleFunc("/inline", func(w http.ResponseWriter, r *http.Request) {})
+	http.Handle("GET /y", nil)
+	http.Handle("/made", makeHandler())
+}
+
+func makeHandler() http.Handler { return nil }
+`}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, route := range info.Routes {
+		got = append(got, route.Method+" "+route.Path+" "+route.Handler)
+	}
+	want := []string{"GET /users listUsers", " /health health", " /users/{id} a.getUser", "  orphan"}
+	for _, w := range want {
+		if !slices.Contains(got, w) {
+			t.Errorf("routes missing %q, got %q", w, got)
+		}
+	}
+	for _, route := range info.Routes {
+		if route.Path == "/y" || route.Path == "/made" || route.Handler == "nil" {
+			t.Errorf("route with no named handler listed: %+v", route)
+		}
+	}
+	if len(info.Routes) != 5 {
+		t.Errorf("got %d routes %q, want the func literal route and %q", len(info.Routes), got, want)
+	}
+}
@@ -0,0 +1,111 @@
+package analyser
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// source is the directory a package is read from, either on disk or, when
+// fsys is set, within a file system such as an embed.FS or fstest.MapFS.
+type source struct {
+	fsys fs.FS
+	dir  string
+}
+
+func (s source) join(name string) string {
+	if s.fsys == nil {
+		return filepath.Join(s.dir, filepath.FromSlash(name))
+	}
+	return path.Join(s.dir, name)
+}
+
+func (s source) readFile(name string) ([]byte, error) {
+	if s.fsys == nil {
+		return os.ReadFile(s.join(name))
+	}
+	return fs.ReadFile(s.fsys, s.join(name))
+}
+
+func (s source) readDir() ([]fs.DirEntry, error) {
+	if s.fsys == nil {
+		return os.ReadDir(s.dir)
+	}
+	return fs.ReadDir(s.fsys, s.dir)
+}
+
+// parsePackages parses the Go files in the source directory that a includes,
+// grouped by package name like parser.ParseDir. Unlike ParseDir, a file that
+// fails to parse is skipped rather than failing the package, and its error
+// returned alongside the packages parsed from the other files. Files matched
+// by a's ignore rules are left out.
+func (a *Analyser) parsePackages(s source) (map[string]*ast.Package, []error, error) {
+	entries, err := s.readDir()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var fileErrs []error
+	pkgs := make(map[string]*ast.Package)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || !a.includes(s, name) {
+			continue
+		}
+
+		if s.fsys == nil && a.Ignore.Ignored(s.join(name), false) {
+			continue
+		}
+
+		src, err := s.readFile(name)
+		if err != nil {
+			fileErrs = append(fileErrs, err)
+			continue
+		}
+
+		filename := s.join(name)
+		file, err := parser.ParseFile(a.fset, filename, src, a.parseMode())
+		if err != nil {
+			fileErrs = append(fileErrs, err)
+			continue
+		}
+
+		pkg, ok := pkgs[file.Name.Name]
+		if !ok {
+			pkg = &ast.Package{Name: file.Name.Name, Files: make(map[string]*ast.File)}
+			pkgs[file.Name.Name] = pkg
+		}
+		pkg.Files[filename] = file
+	}
+
+	return pkgs, fileErrs, nil
+}
+
+func (s source) hasTestFiles() bool {
+	entries, err := s.readDir()
+	if err != nil {
+		return false
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), "_test.go") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s source) readExample(name string) (string, error) {
+	data, err := s.readFile(name)
+	if err != nil {
+		return "", fmt.Errorf("reading example %s: %w", name, err)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
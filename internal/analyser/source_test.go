@@ -0,0 +1,29 @@
+package analyser
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestAnalysePackageFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"go.mod":          {Data: []byte("module example.com/virtual\n\ngo 1.22\n")},
+		"gen/gen.go":      {Data: []byte("// Package gen is generated.\npackage gen\n\n// Value returns the value.\nfunc Value() int { return 1 }\n")},
+		"gen/gen_test.go": {Data: []byte("package gen\n\nfunc helper() {}\n")},
+	}
+
+	info, err := NewAnalyser().AnalysePackageFS(fsys, "gen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name != "gen" || info.ImportPath != "example.com/virtual/gen" {
+		t.Errorf("got name %q import path %q", info.Name, info.ImportPath)
+	}
+	if len(info.Functions) != 1 || info.Functions[0].Name != "Value" || info.Functions[0].Description != "Value returns the value." {
+		t.Errorf("got functions %+v, want Value without test code", info.Functions)
+	}
+
+	if _, err := NewAnalyser().AnalysePackageFS(fsys, "missing"); err == nil {
+		t.Error("analysing a missing directory succeeded")
+	}
+}
@@ -0,0 +1,138 @@
+package analyser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// typeCheck runs go/types over pkg in dir, importing its dependencies from
+// the export data the go command builds for them. It must run before doc.New,
+// which strips unexported declarations the checker needs, and fails if the
+// package doesn't compile.
+func (a *Analyser) typeCheck(pkg *ast.Package, dir string) (*types.Package, error) {
+	exports, err := exportData(dir)
+	if err != nil {
+		return nil, err
+	}
+	lookup := func(path string) (io.ReadCloser, error) {
+		file, ok := exports[path]
+		if !ok || file == "" {
+			return nil, fmt.Errorf("no export data for %s", path)
+		}
+		return os.Open(file)
+	}
+
+	names := make([]string, 0, len(pkg.Files))
+	for name := range pkg.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	files := make([]*ast.File, 0, len(names))
+	for _, name := range names {
+		files = append(files, pkg.Files[name])
+	}
+
+	var errs []error
+	conf := types.Config{
+		Importer: importer.ForCompiler(a.fset, "gc", lookup),
+		Error:    func(err error) { errs = append(errs, err) },
+	}
+	checked, _ := conf.Check(pkg.Name, a.fset, files, nil)
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return checked, nil
+}
+
+// exportData builds the package in dir and its dependencies with the go
+// command, returning the export data file of each by import path.
+func exportData(dir string) (map[string]string, error) {
+	cmd := exec.Command("go", "list", "-export", "-deps", "-f", "{{.ImportPath}}\t{{.Export}}", ".")
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("building package: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	exports := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		path, file, _ := strings.Cut(line, "\t")
+		exports[path] = file
+	}
+
+	return exports, nil
+}
+
+// applyTypes replaces the syntactically inferred types of info's constants
+// and variables with those resolved by the type checker, and, when
+// Implements is set, finds implemented interfaces from the real method sets.
+func (a *Analyser) applyTypes(info *PackageInfo, checked *types.Package) {
+	qualifier := func(p *types.Package) string {
+		if p == checked {
+			return ""
+		}
+		return p.Name()
+	}
+	scope := checked.Scope()
+
+	for i, c := range info.Constants {
+		if obj := scope.Lookup(c.Name); obj != nil {
+			info.Constants[i].Type = types.TypeString(types.Default(obj.Type()), qualifier)
+		}
+	}
+	for i, v := range info.Variables {
+		if obj := scope.Lookup(v.Name); obj != nil {
+			info.Variables[i].Type = types.TypeString(obj.Type(), qualifier)
+		}
+	}
+
+	if !a.Implements {
+		return
+	}
+
+	for i, typ := range info.Types {
+		named := lookupNamed(scope, typ.Name)
+		if named == nil || named.TypeParams() != nil || types.IsInterface(named) {
+			continue
+		}
+
+		var implements []string
+		for _, name := range scope.Names() {
+			iface := lookupNamed(scope, name)
+			if iface == nil || !ast.IsExported(name) || iface.TypeParams() != nil {
+				continue
+			}
+			underlying, ok := iface.Underlying().(*types.Interface)
+			if !ok || underlying.NumMethods() == 0 {
+				continue
+			}
+			if types.Implements(named, underlying) || types.Implements(types.NewPointer(named), underlying) {
+				implements = append(implements, name)
+			}
+		}
+		info.Types[i].Implements = implements
+	}
+}
+
+func lookupNamed(scope *types.Scope, name string) *types.Named {
+	obj, ok := scope.Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil
+	}
+	named, _ := obj.Type().(*types.Named)
+	return named
+}
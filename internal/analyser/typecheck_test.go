@@ -0,0 +1,139 @@
+package analyser
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestTypeCheck(t *testing.T) {
+	root := writePackage(t, map[string]string{
+		"go.mod": "module example.com/shop\n\ngo 1.22\n",
+		"store/store.go": `package store
+
+type Item struct{ Name string }
+
+func NewItem(name string) *Item { return &Item{Name: name} }
+
+const Limit = 10
+`,
+		"cart/cart.go": `package cart
+
+import (
+	"io"
+
+	"example.com/shop/store"
+)
+
+var Default = store.NewItem("default")
+
+const Max = store.Limit * 2
+
+type Source interface {
+	io.Reader
+}
+
+type File struct{}
+
+func (File) Read(p []byte) (int, error) { return 0, nil }
+`,
+	})
+	dir := filepath.Join(root, "cart")
+
+	a := NewAnalyser()
+	a.Implements = true
+	syntactic, err := a.AnalysePackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a = NewAnalyser()
+	a.Implements = true
+	a.TypeCheck = true
+	resolved, err := a.AnalysePackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := resolved.Variables[0].Type; got != "*store.Item" {
+		t.Errorf("resolved Default type %q, want *store.Item", got)
+	}
+	if got := syntactic.Variables[0].Type; got == "*store.Item" {
+		t.Errorf("syntactic analysis resolved Default across packages: %q", got)
+	}
+	if got := resolved.Constants[0].Type; got != "int" {
+		t.Errorf("resolved Max type %q, want int", got)
+	}
+	if got := findType(t, resolved, "File").Implements; !slices.Equal(got, []string{"Source"}) {
+		t.Errorf("resolved File implements %q, want Source", got)
+	}
+	if got := findType(t, syntactic, "File").Implements; len(got) > 0 {
+		t.Errorf("syntactic File implements %q, want none without the method set of io.Reader", got)
+	}
+
+	broken := writePackage(t, map[string]string{
+		"go.mod": "module example.com/broken\n\ngo 1.22\n",
+		"b.go":   "package b\n\nvar X int = \"not an int\"\n",
+	})
+	if _, err := a.AnalysePackage(broken); !errors.Is(err, ErrTypeCheck) {
+		t.Errorf("package that doesn't compile: got %v, want ErrTypeCheck", err)
+	}
+}
+
+func TestTypeCheckImportedTypes(t *testing.T) {
+	root := writePackage(t, map[string]string{
+		"go.mod": "module example.com/shop\n\ngo 1.22\n",
+		"store/store.go": `package store
+
+type Item struct{ Name string }
+
+func List() []Item { return nil }
+`,
+		"cart/cart.go": `package cart
+
+import (
+	"net/http"
+	"time"
+
+	"example.com/shop/store"
+)
+
+const Timeout = 30 * time.Second
+
+var (
+	Client  = http.DefaultClient
+	Started = time.Now()
+	Items   = store.List()
+)
+`,
+	})
+
+	a := NewAnalyser()
+	a.TypeCheck = true
+	info, err := a.AnalysePackage(filepath.Join(root, "cart"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	types := make(map[string]string)
+	for _, c := range info.Constants {
+		types[c.Name] = c.Type
+	}
+	for _, v := range info.Variables {
+		types[v.Name] = v.Type
+	}
+	tests := []struct {
+		name, want string
+	}{
+		{"Timeout", "time.Duration"},
+		{"Client", "*http.Client"},
+		{"Started", "time.Time"},
+		{"Items", "[]store.Item"},
+	}
+	for _, tt := range tests {
+		if got := types[tt.name]; got != tt.want {
+			t.Errorf("%s: got type %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
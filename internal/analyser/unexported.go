@@ -0,0 +1,53 @@
+package analyser
+
+import (
+	"go/ast"
+	"go/doc"
+)
+
+// analyseUnexported returns what docPkg, read with doc.AllDecls, declares that
+// the package's API leaves out: its unexported functions and types, the
+// methods of those types, and the unexported methods of its exported types.
+func (a *Analyser) analyseUnexported(docPkg *doc.Package, dirs map[string]directives, interfaces map[string]*ast.InterfaceType, src source, pkg *PackageInfo) ([]FunctionInfo, []TypeInfo) {
+	var functions []FunctionInfo
+	var types []TypeInfo
+
+	addFuncs := func(funcs []*doc.Func) {
+		for _, fn := range funcs {
+			if ast.IsExported(fn.Name) {
+				continue
+			}
+			if fnInfo, ok := a.analyseFunctionDecl(fn, dirs[fn.Name], src, pkg); ok {
+				functions = append(functions, fnInfo)
+			}
+		}
+	}
+
+	addFuncs(docPkg.Funcs)
+	for _, typ := range docPkg.Types {
+		addFuncs(typ.Funcs)
+
+		exported := ast.IsExported(typ.Name)
+		if !exported {
+			if typeInfo, ok := a.analyseTypeDecl(typ, dirs, interfaces, src, pkg); ok {
+				types = append(types, typeInfo)
+			}
+		}
+
+		for _, method := range typ.Methods {
+			if exported && ast.IsExported(method.Name) {
+				continue
+			}
+			methodInfo, ok := a.analyseFunctionDecl(method, dirs[typ.Name+"."+method.Name], src, pkg)
+			if !ok {
+				continue
+			}
+			methodInfo.IsMethod = true
+			methodInfo.Receiver = typ.Name
+			methodInfo.PointerReceiver = isPointerReceiver(method.Decl.Recv)
+			functions = append(functions, methodInfo)
+		}
+	}
+
+	return functions, types
+}
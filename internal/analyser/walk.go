@@ -0,0 +1,87 @@
+package analyser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FindPackageDirs walks root and returns every directory containing
+// non-test Go source files, skipping vendor, .git and testdata trees below
+// root. Root itself is always walked, so a vendored tree can be documented by
+// asking for it explicitly. Directories more than maxDepth levels below root
+// are not walked; a negative maxDepth means no limit. Paths matched by ignore
+// are skipped.
+func FindPackageDirs(root string, maxDepth int, ignore *IgnoreRules) ([]string, error) {
+	var dirs []string
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			return nil
+		}
+
+		// Skip vendor, .git, and test directories
+		if path != root && shouldSkipDir(path) {
+			return filepath.SkipDir
+		}
+
+		if maxDepth >= 0 && dirDepth(root, path) > maxDepth {
+			return filepath.SkipDir
+		}
+		if path != root && ignore.Ignored(path, true) {
+			return filepath.SkipDir
+		}
+
+		// Check if directory contains Go files
+		hasGoFiles, err := hasGoSourceFiles(path, ignore)
+		if err != nil {
+			return err
+		}
+
+		if hasGoFiles {
+			dirs = append(dirs, path)
+		}
+
+		return nil
+	})
+
+	return dirs, err
+}
+
+// dirDepth returns how many directories path is below root.
+func dirDepth(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(filepath.ToSlash(rel), "/") + 1
+}
+
+func shouldSkipDir(path string) bool {
+	base := filepath.Base(path)
+	return base == "vendor" ||
+		base == ".git" ||
+		base == "testdata" ||
+		strings.HasSuffix(base, "_test")
+}
+
+func hasGoSourceFiles(dir string, ignore *IgnoreRules) (bool, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return false, err
+	}
+
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), ".go") &&
+			!strings.HasSuffix(file.Name(), "_test.go") &&
+			!ignore.Ignored(filepath.Join(dir, file.Name()), false) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,48 @@
+package analyser
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestFindPackageDirs(t *testing.T) {
+	root := writePackage(t, map[string]string{
+		"root.go":           "package root\n",
+		"a/a.go":            "package a\n",
+		"a/b/b.go":          "package b\n",
+		"a/b/c/c.go":        "package c\n",
+		"a/a_test.go":       "package a\n",
+		"vendor/v/v.go":     "package v\n",
+		"testdata/t/t.go":   "package t\n",
+		"only/only_test.go": "package only\n",
+		"docs/readme.md":    "# docs\n",
+	})
+
+	tests := []struct {
+		maxDepth int
+		want     []string
+	}{
+		{-1, []string{".", "a", "a/b", "a/b/c"}},
+		{0, []string{"."}},
+		{1, []string{".", "a"}},
+		{2, []string{".", "a", "a/b"}},
+	}
+	for _, test := range tests {
+		dirs, err := FindPackageDirs(root, test.maxDepth, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, dir := range dirs {
+			rel, err := filepath.Rel(root, dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, filepath.ToSlash(rel))
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("max depth %d: got %q, want %q", test.maxDepth, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package analyser
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// commentWidth is the column doc comments written back are wrapped at.
+const commentWidth = 77
+
+// docEdit replaces src[start:end] with text.
+type docEdit struct {
+	start, end int
+	text       string
+}
+
+// WriteBack writes the descriptions of pkg's functions, methods and types
+// back into its source as doc comments, for those whose doc comment in the
+// source is shorter than minLength. Descriptions shorter than minLength are
+// left out too, so once written back a symbol is not rewritten again. It
+// returns the files it rewrote.
+func WriteBack(pkg *PackageInfo, minLength int) ([]string, error) {
+	docs := make(map[string]map[string]string)
+	add := func(file, symbol, description string) {
+		if file == "" || len(strings.TrimSpace(description)) < minLength {
+			return
+		}
+		if docs[file] == nil {
+			docs[file] = make(map[string]string)
+		}
+		docs[file][symbol] = strings.TrimSpace(description)
+	}
+	for _, fn := range pkg.Functions {
+		symbol := fn.Name
+		if fn.IsMethod {
+			symbol = fn.Receiver + "." + fn.Name
+		}
+		add(fn.File, symbol, fn.Description)
+	}
+	for _, typ := range pkg.Types {
+		add(typ.File, typ.Name, typ.Description)
+	}
+
+	var written []string
+	for _, file := range slices.Sorted(maps.Keys(docs)) {
+		filename := filepath.Join(pkg.Path, file)
+		changed, err := writeBackFile(filename, docs[file], minLength)
+		if err != nil {
+			return written, fmt.Errorf("writing doc comments to %s: %w", filename, err)
+		}
+		if changed {
+			written = append(written, filename)
+		}
+	}
+	return written, nil
+}
+
+func writeBackFile(filename string, docs map[string]string, minLength int) (bool, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return false, err
+	}
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return false, err
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return false, err
+	}
+
+	var edits []docEdit
+	edit := func(symbol string, doc *ast.CommentGroup, pos token.Pos) {
+		description, ok := docs[symbol]
+		if !ok || len(strings.TrimSpace(doc.Text())) >= minLength {
+			return
+		}
+		// A thin comment is replaced, lines and all, keeping its directives
+		// below the new one
+		end := lineStart(src, fset.Position(pos).Offset)
+		start := end
+		if doc != nil {
+			start = lineStart(src, fset.Position(doc.Pos()).Offset)
+		}
+		indent := string(src[start : start+len(src[start:])-len(bytes.TrimLeft(src[start:], " \t"))])
+		text := docComment(description, indent)
+		if doc != nil {
+			for _, c := range doc.List {
+				if isDirective(c.Text) {
+					text += indent + c.Text + "\n"
+				}
+			}
+		}
+		edits = append(edits, docEdit{start: start, end: end, text: text})
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			symbol := d.Name.Name
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				symbol = receiverTypeName(d.Recv.List[0].Type) + "." + symbol
+			}
+			edit(symbol, d.Doc, d.Pos())
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if d.Lparen.IsValid() {
+					edit(ts.Name.Name, ts.Doc, ts.Pos())
+				} else {
+					edit(ts.Name.Name, d.Doc, d.Pos())
+				}
+			}
+		}
+	}
+	if len(edits) == 0 {
+		return false, nil
+	}
+
+	// Apply from the end so earlier offsets stay valid
+	slices.SortFunc(edits, func(a, b docEdit) int { return b.start - a.start })
+	out := slices.Clone(src)
+	for _, e := range edits {
+		out = slices.Concat(out[:e.start], []byte(e.text), out[e.end:])
+	}
+
+	formatted, err := format.Source(out)
+	if err != nil {
+		return false, fmt.Errorf("formatting: %w", err)
+	}
+	return true, os.WriteFile(filename, formatted, info.Mode().Perm())
+}
+
+// directiveComment matches comments read by tools rather than people, such as
+// //go:generate, //export and //nolint, as go/ast recognises them.
+var directiveComment = regexp.MustCompile(`^//(line |extern |export |nolint|[a-z0-9]+:[a-z0-9])`)
+
+// isDirective reports whether the comment text is a directive, which a doc
+// comment written back must not drop.
+func isDirective(text string) bool {
+	return directiveComment.MatchString(text)
+}
+
+// lineStart returns the offset of the start of the line holding offset.
+func lineStart(src []byte, offset int) int {
+	for offset > 0 && src[offset-1] != '\n' {
+		offset--
+	}
+	return offset
+}
+
+// docComment renders description as a // comment indented by indent, its
+// paragraphs wrapped at commentWidth columns.
+func docComment(description, indent string) string {
+	var b strings.Builder
+	for i, paragraph := range strings.Split(description, "\n\n") {
+		if i > 0 {
+			b.WriteString(indent + "//\n")
+		}
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			if line != "" && len(line)+1+len(word) > commentWidth {
+				b.WriteString(indent + "// " + line + "\n")
+				line = ""
+			}
+			if line != "" {
+				line += " "
+			}
+			line += word
+		}
+		if line != "" {
+			b.WriteString(indent + "// " + line + "\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,94 @@
+package analyser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteBack(t *testing.T) {
+	src := `package shape
+
+// Square is a square with sides of length Side.
+type Square struct{ Side float64 }
+
+func (s Square) Area() float64 { return s.Side * s.Side }
+
+// TODO
+//
+//go:noinline
+//nolint:unused
+func perimeter(s Square) float64 { return 4 * s.Side }
+
+//export Scale
+func Scale(s Square, by float64) Square { return Square{Side: s.Side * by} }
+`
+	dir := writePackage(t, map[string]string{"shape.go": src})
+	info, err := NewAnalyser().AnalysePackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info.Functions = append(info.Functions, FunctionInfo{Name: "perimeter", File: "shape.go"})
+	for i, fn := range info.Functions {
+		switch fn.Name {
+		case "Area":
+			info.Functions[i].Description = "Area returns the area of the square."
+		case "perimeter":
+			info.Functions[i].Description = "perimeter returns the length of the square's outline."
+		case "Scale":
+			info.Functions[i].Description = "Scale returns s with its sides multiplied by by."
+		}
+	}
+
+	written, err := WriteBack(info, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, "shape.go")
+	if len(written) != 1 || written[0] != filename {
+		t.Errorf("got files written %v, want %s", written, filename)
+	}
+
+	got, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `package shape
+
+// Square is a square with sides of length Side.
+type Square struct{ Side float64 }
+
+// Area returns the area of the square.
+func (s Square) Area() float64 { return s.Side * s.Side }
+
+// perimeter returns the length of the square's outline.
+//
+//go:noinline
+//nolint:unused
+func perimeter(s Square) float64 { return 4 * s.Side }
+
+// Scale returns s with its sides multiplied by by.
+//
+//export Scale
+func Scale(s Square, by float64) Square { return Square{Side: s.Side * by} }
+`
+	if string(got) != want {
+		t.Errorf("got source:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Written back, the comments are no longer thin, so nothing is rewritten
+	written, err = WriteBack(info, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(written) != 0 {
+		t.Errorf("second write back rewrote %v", written)
+	}
+	again, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != want {
+		t.Errorf("second write back changed the source:\n%s", again)
+	}
+}
@@ -0,0 +1,27 @@
+package diff
+
+import "testing"
+
+func TestUnified(t *testing.T) {
+	if patch := Unified("a", "b", "same\n", "same\n"); patch != "" {
+		t.Errorf("equal texts gave a diff:\n%s", patch)
+	}
+
+	old := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\n"
+	new := "one\ntwo\nthree\nfour\nFIVE\nsix\nseven\neight\n"
+	want := `--- old
++++ new
+@@ -2,7 +2,7 @@
+ two
+ three
+ four
+-five
++FIVE
+ six
+ seven
+ eight
+`
+	if patch := Unified("old", "new", old, new); patch != want {
+		t.Errorf("got:\n%s\nwant:\n%s", patch, want)
+	}
+}
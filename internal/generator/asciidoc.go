@@ -0,0 +1,351 @@
+package generator
+
+const asciidocTemplate = `= {{if .IsCommand}}{{.Command}}{{else}}{{.Name}}{{end}}
+:toc:
+{{if .Summary}}
+_{{.Summary}}_
+{{end}}
+{{if .Overview}}{{.Overview}}{{else}}{{.Description}}{{end}}
+{{with .Readme}}
+
+{{.}}
+{{end}}
+
+{{if .IsCommand}}
+== {{$.Label "Command"}}
+
+[source,{{$.Fence "shell"}}]
+----
+{{.Command}} [flags]
+----
+
+{{if .Flags}}
+=== {{$.Label "Flags"}}
+
+[cols="2,1,1,4",options="header"]
+|===
+|Flag |Type |Default |Description
+{{range .Flags}}
+|` + "`--{{.Name}}`" + `{{if .Shorthand}}, ` + "`-{{.Shorthand}}`" + `{{end}}
+|{{.Type}}
+|` + "`{{.Default}}`" + `
+|{{.Usage}}
+{{end}}
+|===
+{{end}}
+{{else}}
+{{if or .IsInternal .ImportPath}}
+== {{$.Label "Installation"}}
+
+{{if .IsInternal}}
+NOTE: This package can only be imported from within its own module.
+{{else}}
+[source,{{$.Fence "shell"}}]
+----
+go get {{.ImportPath}}
+----
+{{end}}
+{{end}}
+
+== {{$.Label "Usage"}}
+
+{{range $.PackageExamples}}
+.{{.Name}}
+[source,{{$.Fence "code"}}]
+----
+{{.Code}}
+----
+{{if .Output}}
+.{{$.Label "Output"}}
+----
+{{.Output}}
+----
+{{end}}
+{{end}}
+
+== {{$.Label "API Reference"}}
+
+{{if not .HasAPI}}
+_{{$.Label "No exported API."}}_
+{{end}}
+
+{{if .Routes}}
+=== {{$.Label "Routes"}}
+
+[cols="1,3,2",options="header"]
+|===
+|Method |Path |Handler
+{{range .Routes}}
+|{{or .Method "-"}}
+|{{if .Path}}` + "`{{.Path}}`" + `{{else}}-{{end}}
+|` + "`{{.Handler}}`" + `
+{{end}}
+|===
+{{end}}
+
+{{with .ExportedConstants}}
+=== {{$.Label "Constants"}}
+
+{{range .}}
+* ` + "`{{.Name}}`" + `{{if .Type}} ({{$.LinkTypes .Type}}){{end}}{{if .Value}} = ` + "`{{.Value}}`" + `{{end}}{{if .Description}} - {{.Description}}{{end}}
+{{end}}
+{{end}}
+
+{{with .ExportedVariables}}
+=== {{$.Label "Variables"}}
+
+{{range .}}
+* ` + "`{{.Name}}`" + `{{if .Type}} ({{$.LinkTypes .Type}}){{end}}{{if .Value}} = ` + "`{{.Value}}`" + `{{end}}{{if .Description}} - {{.Description}}{{end}}
+{{end}}
+{{end}}
+
+{{with .Errors}}
+=== {{$.Label "Errors"}}
+
+{{range .}}
+* ` + "`{{.Name}}`" + `{{if .Description}} - {{.Description}}{{end}}
+{{end}}
+{{end}}
+
+{{if .Functions}}
+=== {{$.Label "Functions"}}
+
+{{range .Functions}}
+{{if and .IsExported ($.InAPI .)}}
+[[{{$.FuncAnchor .}}]]
+==== {{if .IsMethod}}{{.Receiver}}.{{end}}{{.Name}}
+{{if .Since}}
+TIP: Available since {{.Since}}.
+{{end}}
+
+[source,{{$.Fence "code"}}]
+----
+{{$.FormatSignature .}}
+----
+{{if and $.ShowSource .File}}
+{{$url := $.SourceURL .File .Line}}{{$.Label "Source"}}: {{if $url}}{{$url}}[{{.File}}:{{.Line}}]{{else}}` + "`{{.File}}:{{.Line}}`" + `{{end}}
+{{end}}
+
+{{.Description}}
+
+{{if .Parameters}}
+*{{$.Label "Parameters"}}:*
+
+{{range .Parameters}}
+* ` + "`{{.Name}}`" + ` ({{$.LinkTypes .Type}})
+{{end}}
+{{end}}
+
+{{if .Returns}}
+*{{$.Label "Returns"}}:*
+
+{{range .Returns}}
+* {{if .Name}}` + "`{{.Name}}`" + ` {{end}}{{$.LinkTypes .Type}}{{if .Description}} - {{.Description}}{{end}}
+{{end}}
+{{end}}
+{{range $.InlineTypes .}}
+[source,{{$.Fence "code"}}]
+----
+{{.Definition}}
+----
+{{end}}
+
+{{if .TypeParams}}
+*{{$.Label "Constraints"}}:*
+
+{{range .TypeParams}}
+* ` + "`{{.Name}}`" + ` {{.Constraint}}
+{{end}}
+{{end}}
+
+{{$numbered := gt (len .Examples) 1}}
+{{range $i, $code := .Examples}}
+.{{if $numbered}}{{$.ExampleNumber $i}}{{else}}{{$.Label "Example"}}{{end}}
+[source,{{$.Fence "code"}}]
+----
+{{$code}}
+----
+{{end}}
+{{range $.FunctionExamples .}}
+.{{$.Label "Example"}}
+[source,{{$.Fence "code"}}]
+----
+{{.Code}}
+----
+{{if .Output}}
+.{{$.Label "Output"}}
+----
+{{.Output}}
+----
+{{end}}
+{{end}}
+
+{{end}}
+{{end}}
+{{end}}
+
+{{if .Types}}
+=== {{$.Label "Types"}}
+
+{{range .Types}}
+{{if .IsExported}}
+[[{{$.Anchor .Name}}]]
+==== {{.Name}}
+{{if .Since}}
+TIP: Available since {{.Since}}.
+{{end}}
+
+[source,{{$.Fence "code"}}]
+----
+type {{.Name}}{{typeParams .TypeParams}}{{if .IsAlias}} = {{.Underlying}}{{else if .Underlying}} {{.Underlying}}{{else}} {{.Kind}}{{end}}
+----
+{{if and $.ShowSource .File}}
+{{$url := $.SourceURL .File .Line}}{{$.Label "Source"}}: {{if $url}}{{$url}}[{{.File}}:{{.Line}}]{{else}}` + "`{{.File}}:{{.Line}}`" + `{{end}}
+{{end}}
+
+{{.Description}}
+
+{{if and .IsAlias ($.RefersToLocal .Underlying)}}
+*{{$.Label "Alias of"}}:* {{$.LinkTypes .Underlying}}
+{{end}}
+
+{{with .Implements}}
+*{{$.Label "Implements"}}:* {{range $i, $name := .}}{{if $i}}, {{end}}<<{{$.Anchor $name}},{{$name}}>>{{end}}
+{{end}}
+
+{{with .Embeds}}
+*{{$.Label "Embeds"}}:* {{range $i, $name := .}}{{if $i}}, {{end}}{{$.LinkTypes $name}}{{end}}
+{{end}}
+
+{{with .MethodSet}}
+*{{$.Label "Methods"}}:*
+
+{{range .}}
+* ` + "`{{.}}`" + `
+{{end}}
+{{end}}
+
+{{if .Constructors}}
+*{{$.Label "Constructors"}}:*
+
+{{range .Constructors}}
+* <<{{$.Anchor .}},{{.}}>>
+{{end}}
+{{end}}
+
+{{if .Options}}
+*{{$.Label "Options"}}:*
+
+{{range .Options}}
+* <<{{$.Anchor .}},{{.}}>>
+{{end}}
+{{end}}
+
+{{if .Fields}}
+*{{$.Label "Fields"}}:*
+
+{{range .Fields}}
+* ` + "`{{.Name}}`" + ` {{$.LinkTypes .Type}}{{if $.Redacted .}} ({{$.Label "unexported"}}){{end}}{{with .Default}} ({{$.Label "default"}} ` + "`{{.}}`" + `){{end}}{{if .Description}} - {{.Description}}{{end}}
+{{end}}
+{{end}}
+
+{{if or .TypeParams .TypeSet}}
+*{{$.Label "Constraints"}}:*
+
+{{range .TypeParams}}
+* ` + "`{{.Name}}`" + ` {{.Constraint}}
+{{end}}
+{{range .TypeSet}}
+* ` + "`{{.}}`" + `
+{{end}}
+{{end}}
+
+{{range .Examples}}
+.{{$.Label "Example"}}
+[source,{{$.Fence "code"}}]
+----
+{{.}}
+----
+{{end}}
+{{range $.ExamplesFor .Name}}
+.{{$.Label "Example"}}
+[source,{{$.Fence "code"}}]
+----
+{{.Code}}
+----
+{{if .Output}}
+.{{$.Label "Output"}}
+----
+{{.Output}}
+----
+{{end}}
+{{end}}
+
+{{if .Methods}}
+*{{$.Label "Methods"}}:*
+
+{{$type := .Name}}
+{{range .Methods}}
+* <<{{$.Anchor (printf "%s.%s" $type .)}},{{.}}>>
+{{end}}
+{{end}}
+
+{{end}}
+{{end}}
+{{end}}
+
+{{if or .TestHelpers .TestableExamples}}
+=== {{$.Label "Testing"}}
+
+{{range .TestHelpers}}
+[[{{$.FuncAnchor .}}]]
+==== {{.Name}}
+
+[source,{{$.Fence "code"}}]
+----
+{{$.FormatSignature .}}
+----
+{{if and $.ShowSource .File}}
+{{$url := $.SourceURL .File .Line}}{{$.Label "Source"}}: {{if $url}}{{$url}}[{{.File}}:{{.Line}}]{{else}}` + "`{{.File}}:{{.Line}}`" + `{{end}}
+{{end}}
+
+{{.Description}}
+
+{{end}}
+
+{{with .TestableExamples}}
+*{{$.Label "Examples"}}:*
+
+{{range .}}
+* ` + "`Example{{.Name}}`" + `{{if .Symbol}} - <<{{$.Anchor .Symbol}},{{.Symbol}}>>{{end}}
+{{end}}
+{{end}}
+{{end}}
+{{end}}
+
+{{if .Imports}}
+{{$imports := .ImportGroups}}
+== {{$.Label "Imports"}}
+{{with $imports.Standard}}
+*{{$.Label "Standard library"}}:*
+
+{{range .}}
+* ` + "`{{.}}`" + `
+{{end}}
+{{end}}
+{{with $imports.External}}
+*{{$.Label "Third party"}}:*
+
+{{range .}}
+* https://pkg.go.dev/{{.}}[{{.}}]
+{{end}}
+{{end}}
+{{with $imports.Module}}
+*{{$.Label "This module"}}:*
+
+{{range .}}
+* ` + "`{{.}}`" + `
+{{end}}
+{{end}}
+{{end}}
+`
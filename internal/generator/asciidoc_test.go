@@ -0,0 +1,59 @@
+package generator
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files with the current output")
+
+// checkGolden compares got with testdata/name, rewriting it with -update.
+func checkGolden(t *testing.T, name string, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file, run with -update to create it: %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s, run with -update if the change is intended:\n%s", path, got)
+	}
+}
+
+func TestAsciiDocGolden(t *testing.T) {
+	doc := render(t, `// Package shapes measures shapes.
+package shapes
+
+import "errors"
+
+// ErrNegative is returned for negative sizes.
+var ErrNegative = errors.New("negative size")
+
+// Square is a square.
+type Square struct {
+	// Side is the length of each side.
+	Side float64
+}
+
+// Area returns the area of s.
+func (s Square) Area() float64 { return s.Side * s.Side }
+
+// NewSquare returns a square with the given side.
+//
+// Since: v1.2.0
+func NewSquare(side float64) (*Square, error) { return &Square{Side: side}, nil }
+`, offlineConfig("asciidoc"))
+
+	checkGolden(t, "shapes.adoc", doc)
+}
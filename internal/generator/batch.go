@@ -0,0 +1,164 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"github.com/brendan-sadlier/docura/internal/logging"
+	"strings"
+
+	"github.com/tmc/langchaingo/prompts"
+)
+
+// batchItem is a single symbol whose description is enhanced as part of a batch.
+type batchItem struct {
+	id       string
+	summary  string                 // how the symbol is described in the prompt
+	apply    func(string)           // stores the enhanced description
+	fallback func() (string, error) // enhances the symbol on its own
+}
+
+// estimateTokens roughly approximates the token count of text.
+func estimateTokens(text string) int {
+	return len(text)/4 + 1
+}
+
+// splitBatches groups items into batches of at most size items whose
+// summaries fit within tokenBudget estimated tokens. A budget of 0 is unlimited.
+func splitBatches(items []batchItem, size int, tokenBudget int) [][]batchItem {
+	var batches [][]batchItem
+	var current []batchItem
+	tokens := 0
+
+	for _, item := range items {
+		itemTokens := estimateTokens(item.summary)
+		full := len(current) >= size || (tokenBudget > 0 && tokens+itemTokens > tokenBudget)
+		if len(current) > 0 && full {
+			batches = append(batches, current)
+			current, tokens = nil, 0
+		}
+		current = append(current, item)
+		tokens += itemTokens
+	}
+
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+
+	return batches
+}
+
+// enhanceSymbolsBatched enhances the package's function and type descriptions
+// several to a request. A symbol left out of a failed batch is enhanced on
+// its own, and if that fails too its error is added to failed.
+func (dg *DocGenerator) enhanceSymbolsBatched(ctx context.Context, pkg *analyser.PackageInfo, config DocConfig, failed *enhanceFailures) {
+	var items []batchItem
+
+	for i := range pkg.Functions {
+		fn := &pkg.Functions[i]
+		if !config.EnhanceFunctions || len(fn.Description) >= BriefDescription {
+			continue
+		}
+		items = append(items, batchItem{
+			id:       fmt.Sprintf("f%d", i),
+			summary:  fmt.Sprintf("Function %s: %s", fn.Name, fn.Signature),
+			apply:    func(d string) { fn.Description = d },
+			fallback: func() (string, error) { return dg.enhanceFunctionDescription(ctx, fn, config) },
+		})
+	}
+
+	for i := range pkg.Types {
+		typ := &pkg.Types[i]
+		if !config.EnhanceTypes || len(typ.Description) >= BriefDescription {
+			continue
+		}
+		var fields []string
+		for _, field := range typ.Fields {
+			fields = append(fields, strings.TrimSpace(field.Name+" "+field.Type))
+		}
+		items = append(items, batchItem{
+			id:       fmt.Sprintf("t%d", i),
+			summary:  fmt.Sprintf("Type %s (%s) fields: [%s] methods: [%s]", typ.Name, typ.Kind, strings.Join(fields, ", "), strings.Join(typ.Methods, ", ")),
+			apply:    func(d string) { typ.Description = d },
+			fallback: func() (string, error) { return dg.enhanceTypeDescription(ctx, typ, config) },
+		})
+	}
+
+	batches := splitBatches(items, config.BatchSize, config.BatchTokenBudget)
+	forEachConcurrently(len(batches), config.LLMConcurrency, func(i int) {
+		dg.enhanceBatch(ctx, pkg, batches[i], config, failed)
+	})
+}
+
+func (dg *DocGenerator) enhanceBatch(ctx context.Context, pkg *analyser.PackageInfo, batch []batchItem, config DocConfig, failed *enhanceFailures) {
+	descriptions, err := dg.requestBatch(ctx, pkg, batch, config)
+	if err != nil {
+		logging.Debugf("Batched enhancement failed, falling back to individual requests: %v", err)
+	}
+
+	for _, item := range batch {
+		if description := strings.TrimSpace(descriptions[item.id]); description != "" {
+			item.apply(clipWords(description, config.MaxDescriptionWords))
+			continue
+		}
+
+		enhanced, err := item.fallback()
+		if err != nil {
+			failed.add(err)
+		} else if enhanced != "" {
+			item.apply(enhanced)
+		}
+	}
+}
+
+func (dg *DocGenerator) requestBatch(ctx context.Context, pkg *analyser.PackageInfo, batch []batchItem, config DocConfig) (map[string]string, error) {
+	template := prompts.NewPromptTemplate(`
+Write a clear, concise description (1-2 sentences) for each of these symbols from the Go package {{.package}}.
+Describe what each does or represents, when to use it, and any important behavior.
+Write the descriptions in {{.language}}.
+
+{{.symbols}}
+
+Respond with only a JSON object mapping each ID to its description, for example:
+{"f0": "Description of the first symbol.", "t1": "Description of the second symbol."}`,
+		[]string{"package", "symbols", "language"})
+
+	var symbols strings.Builder
+	for _, item := range batch {
+		fmt.Fprintf(&symbols, "%s: %s\n", item.id, item.summary)
+	}
+
+	prompt, err := template.Format(map[string]any{
+		"package":  pkg.Name,
+		"symbols":  symbols.String(),
+		"language": config.language(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := dg.complete(ctx, prompt, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseBatchResponse(content)
+}
+
+// parseBatchResponse extracts the JSON object of descriptions from a batch
+// response, tolerating surrounding prose or code fences.
+func parseBatchResponse(content string) (map[string]string, error) {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no JSON object in batch response")
+	}
+
+	var descriptions map[string]string
+	if err := json.Unmarshal([]byte(content[start:end+1]), &descriptions); err != nil {
+		return nil, fmt.Errorf("parsing batch response: %w", err)
+	}
+
+	return descriptions, nil
+}
@@ -0,0 +1,151 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// batchIDs matches the symbol IDs listed in a batch prompt.
+var batchIDs = regexp.MustCompile(`(?m)^([ft]\d+): `)
+
+// answerBatch describes every symbol in a batch prompt, and any other
+// prompt with single.
+func answerBatch(single string) func(string) (string, error) {
+	return func(prompt string) (string, error) {
+		ids := batchIDs.FindAllStringSubmatch(prompt, -1)
+		if len(ids) == 0 {
+			return single, nil
+		}
+		descriptions := make(map[string]string)
+		for _, id := range ids {
+			descriptions[id[1]] = "Batched description of " + id[1] + "."
+		}
+		data, err := json.Marshal(descriptions)
+		return "Here you go:\n```json\n" + string(data) + "\n```", err
+	}
+}
+
+const batchSource = `package p
+
+func A() {}
+func B() {}
+func C() {}
+func D() {}
+
+type T struct{}
+type U struct{}
+`
+
+func batchConfig() DocConfig {
+	config := DefaultConfig()
+	config.EnhancePackage = false
+	config.BatchSize = 10
+	return config
+}
+
+func TestEnhanceBatched(t *testing.T) {
+	model := &fakeModel{respond: answerBatch("Single description.")}
+	pkg := analyseSource(t, batchSource)
+
+	if err := newTestGenerator(t, model).EnhanceDescriptions(context.Background(), pkg, batchConfig()); err != nil {
+		t.Fatal(err)
+	}
+
+	symbols := len(pkg.Functions) + len(pkg.Types)
+	if calls := len(model.Prompts()); calls >= symbols {
+		t.Errorf("%d symbols took %d requests, want fewer", symbols, calls)
+	}
+	for _, fn := range pkg.Functions {
+		if !strings.HasPrefix(fn.Description, "Batched description of f") {
+			t.Errorf("%s: got description %q", fn.Name, fn.Description)
+		}
+	}
+	for _, typ := range pkg.Types {
+		if !strings.HasPrefix(typ.Description, "Batched description of t") {
+			t.Errorf("%s: got description %q", typ.Name, typ.Description)
+		}
+	}
+}
+
+func TestEnhanceBatchedFallback(t *testing.T) {
+	model := &fakeModel{respond: func(prompt string) (string, error) {
+		return "Not JSON at all.", nil
+	}}
+	pkg := analyseSource(t, batchSource)
+
+	if err := newTestGenerator(t, model).EnhanceDescriptions(context.Background(), pkg, batchConfig()); err != nil {
+		t.Fatal(err)
+	}
+
+	// One batch request, then one request for each symbol
+	symbols := len(pkg.Functions) + len(pkg.Types)
+	if calls := len(model.Prompts()); calls != symbols+1 {
+		t.Errorf("got %d requests, want %d", calls, symbols+1)
+	}
+	for _, fn := range pkg.Functions {
+		if fn.Description != "Not JSON at all." {
+			t.Errorf("%s: fallback description not applied, got %q", fn.Name, fn.Description)
+		}
+	}
+}
+
+func TestEnhanceBatchedFailure(t *testing.T) {
+	errModel := errors.New("model unavailable")
+	batched := answerBatch("Single description.")
+	model := &fakeModel{respond: func(prompt string) (string, error) {
+		// The second batch fails, and so does D on its own
+		if strings.Contains(prompt, "f3: ") || strings.Contains(prompt, "Function: D\n") {
+			return "", errModel
+		}
+		return batched(prompt)
+	}}
+	pkg := analyseSource(t, batchSource)
+	config := batchConfig()
+	config.BatchSize = 3
+
+	err := newTestGenerator(t, model).EnhanceDescriptions(context.Background(), pkg, config)
+	if !errors.Is(err, errModel) {
+		t.Fatalf("got error %v, want the model's error", err)
+	}
+	if !strings.Contains(err.Error(), "(1 requests failed)") {
+		t.Errorf("got error %q, want one failed request", err)
+	}
+
+	descriptions := make(map[string]string)
+	for _, fn := range pkg.Functions {
+		descriptions[fn.Name] = fn.Description
+	}
+	for _, typ := range pkg.Types {
+		descriptions[typ.Name] = typ.Description
+	}
+	want := map[string]string{
+		"A": "Batched description of f0.",
+		"B": "Batched description of f1.",
+		"C": "Batched description of f2.",
+		"D": "",
+		"T": "Single description.",
+		"U": "Single description.",
+	}
+	if !maps.Equal(descriptions, want) {
+		t.Errorf("got descriptions %v, want %v", descriptions, want)
+	}
+}
+
+func TestSplitBatches(t *testing.T) {
+	items := make([]batchItem, 5)
+	for i := range items {
+		items[i].summary = strings.Repeat("x", 40)
+	}
+
+	if got := len(splitBatches(items, 2, 0)); got != 3 {
+		t.Errorf("batch size 2: got %d batches, want 3", got)
+	}
+	if got := len(splitBatches(items, 10, 25)); got != 3 {
+		t.Errorf("token budget 25: got %d batches, want 3", got)
+	}
+}
@@ -0,0 +1,32 @@
+package generator
+
+import "strings"
+
+// clipWords shortens text to at most max words, ending at the last sentence
+// boundary within the limit where there is one, and otherwise after the last
+// whole word. A max below 1 leaves text unchanged.
+func clipWords(text string, max int) string {
+	words := strings.Fields(text)
+	if max < 1 || len(words) <= max {
+		return text
+	}
+
+	clipped := strings.Join(words[:max], " ")
+	if end := strings.LastIndexAny(clipped, ".!?"); end > 0 {
+		return clipped[:end+1]
+	}
+
+	return strings.TrimRight(clipped, ",;:") + "…"
+}
+
+// firstSentence returns text up to the end of its first sentence, joined onto
+// one line.
+func firstSentence(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	for i := 0; i < len(text); i++ {
+		if strings.IndexByte(".!?", text[i]) >= 0 && (i+1 == len(text) || text[i+1] == ' ') {
+			return text[:i+1]
+		}
+	}
+	return text
+}
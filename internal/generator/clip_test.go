@@ -0,0 +1,45 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClipWords(t *testing.T) {
+	tests := []struct {
+		text string
+		max  int
+		want string
+	}{
+		{"Opens the file. Reads it all. Closes it again.", 6, "Opens the file. Reads it all."},
+		{"Opens the file. Reads it all. Closes it again.", 4, "Opens the file."},
+		{"Opens the file and reads every line of it", 5, "Opens the file and reads…"},
+		{"Opens the file, then reads it", 3, "Opens the file…"},
+		{"Short enough.", 5, "Short enough."},
+		{"No limit at all here.", 0, "No limit at all here."},
+	}
+	for _, test := range tests {
+		if got := clipWords(test.text, test.max); got != test.want {
+			t.Errorf("clipWords(%q, %d) = %q, want %q", test.text, test.max, got, test.want)
+		}
+	}
+}
+
+func TestMaxDescriptionWords(t *testing.T) {
+	long := "Frobnicates the widget. It does so carefully and thoroughly, checking every part of the widget twice."
+	model := &fakeModel{respond: func(prompt string) (string, error) { return long, nil }}
+
+	config := DefaultConfig()
+	config.GenerateExamples = false
+	config.MaxDescriptionWords = 8
+	doc, err := newTestGenerator(t, model).GeneratePackageDoc(analyseSource(t, "package p\n\nfunc Frob() {}\n\ntype Widget struct{}\n"), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(doc, "carefully") {
+		t.Errorf("description not clipped:\n%s", doc)
+	}
+	if strings.Count(doc, "Frobnicates the widget.") < 3 {
+		t.Errorf("package, function and type descriptions not all clipped at the sentence:\n%s", doc)
+	}
+}
@@ -0,0 +1,40 @@
+package generator
+
+import "strings"
+
+// extractCode pulls the Go code out of an LLM response. Models often wrap
+// examples in Markdown fences and surround them with prose despite being asked
+// not to; the first fenced block is preferred, falling back to everything from
+// the package clause onwards, and finally the trimmed response as-is.
+func extractCode(response string) string {
+	lines := strings.Split(strings.ReplaceAll(response, "\r\n", "\n"), "\n")
+
+	var block []string
+	inBlock := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			if inBlock {
+				return strings.TrimSpace(strings.Join(block, "\n"))
+			}
+			inBlock = true
+			continue
+		}
+		if inBlock {
+			block = append(block, line)
+		}
+	}
+
+	// An unterminated fence still yields the code that followed it
+	if inBlock {
+		return strings.TrimSpace(strings.Join(block, "\n"))
+	}
+
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "package ") {
+			return strings.TrimSpace(strings.Join(lines[i:], "\n"))
+		}
+	}
+
+	return strings.TrimSpace(response)
+}
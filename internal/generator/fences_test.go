@@ -0,0 +1,27 @@
+package generator
+
+import "testing"
+
+func TestExtractCode(t *testing.T) {
+	const code = "x := p.Frob(1)\nfmt.Println(x)"
+	tests := []struct {
+		name     string
+		response string
+	}{
+		{"plain", code},
+		{"fenced", "```\n" + code + "\n```"},
+		{"language tag", "```go\n" + code + "\n```\n"},
+		{"leading prose", "Here is an example of calling Frob:\n\n```go\n" + code + "\n```\n\nThis prints 2."},
+		{"unterminated fence", "```go\n" + code},
+		{"prose before package clause", "Sure! Here you go:\npackage main"},
+	}
+	for _, tt := range tests {
+		want := code
+		if tt.name == "prose before package clause" {
+			want = "package main"
+		}
+		if got := extractCode(tt.response); got != want {
+			t.Errorf("%s: extractCode() = %q, want %q", tt.name, got, want)
+		}
+	}
+}
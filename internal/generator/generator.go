@@ -2,10 +2,15 @@ package generator
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/brendan-sadlier/docura/internal/analyser"
+	"github.com/brendan-sadlier/docura/internal/logging"
+	"io"
 	"os"
+	"slices"
 	"strings"
+	"sync"
 	"text/template"
 
 	"github.com/tmc/langchaingo/llms"
@@ -16,6 +21,20 @@ import (
 type DocGenerator struct {
 	llm       llms.Model
 	templates map[string]*template.Template
+	hooks     []PostRenderHook
+
+	limiterMu sync.Mutex
+	limiter   *rateLimiter
+
+	modelsMu sync.Mutex
+	newModel func(name string) (llms.Model, error)
+	models   map[string]llms.Model
+
+	streamMu  sync.Mutex
+	streamOut io.Writer
+
+	refsMu sync.Mutex
+	refs   map[string]string
 }
 
 type DocConfig struct {
@@ -24,331 +43,640 @@ type DocConfig struct {
 	OutputDir        string `json:"output_dir"`
 	IncludePrivate   bool   `json:"include_private"`
 	GenerateExamples bool   `json:"generate_examples"`
-	Style            string `json:"style"` // "godoc", "markdown", "html"
+	Style            string `json:"style" enum:"godoc,markdown,html,asciidoc"`
+
+	// EnhancePackage, EnhanceFunctions and EnhanceTypes choose which brief
+	// descriptions the LLM rewrites: the package's own, its functions' and its
+	// types'. GenerateFunctionExamples generates an example for each function
+	// without one, on top of the package's usage example
+	EnhancePackage           bool `json:"enhance_package"`
+	EnhanceFunctions         bool `json:"enhance_functions"`
+	EnhanceTypes             bool `json:"enhance_types"`
+	GenerateFunctionExamples bool `json:"generate_function_examples"`
+
+	// RedactPrivateFields shows the unexported fields kept by IncludePrivate
+	// by name and type only, leaving out their tags and marking them
+	// (unexported)
+	RedactPrivateFields bool `json:"redact_private_fields"`
+
+	// ExamplesPerFunction is how many examples are generated for each
+	// function without one, each for a different use case, 0 meaning 1.
+	// Fewer are generated when the examples so far, which every further
+	// prompt lists, would exceed BatchTokenBudget
+	ExamplesPerFunction int `json:"examples_per_function"`
+
+	// ExampleValidation controls how generated examples are checked before
+	// being emitted: "none", "parse" (default) or "build"
+	ExampleValidation string `json:"example_validation" enum:"none,parse,build"`
+
+	// FieldStyle renders struct fields in Markdown as a "list" (default) or a "table"
+	FieldStyle string `json:"field_style" enum:"list,table"`
+
+	// SymbolOrder sorts functions and each type's methods "alphabetical",
+	// in "source" order, or "exported-first" (default), alphabetically
+	SymbolOrder string `json:"symbol_order" enum:"alphabetical,source,exported-first"`
+
+	// WrapWidth reflows Markdown paragraphs to at most this many columns,
+	// leaving code, lists, tables and headings as they are. 0 never wraps them
+	WrapWidth int `json:"wrap_width"`
+
+	// CollapseBlankLines squeezes the runs of blank lines left behind by
+	// template actions into one, outside code blocks
+	CollapseBlankLines bool `json:"collapse_blank_lines"`
+
+	// InlineResultTypes shows the definitions of the package's struct types
+	// with at most this many fields under the functions returning them. 0
+	// never does
+	InlineResultTypes int `json:"inline_result_types"`
+
+	// SignatureWidth wraps function signatures longer than this many
+	// characters, putting each parameter on its own line. 0 never wraps them
+	SignatureWidth int `json:"signature_width"`
+
+	// HeadingOffset demotes every heading by this many levels, for embedding
+	// the output in a larger document
+	HeadingOffset int `json:"heading_offset"`
+
+	// OutputExt overrides the extension of generated files, which otherwise
+	// follows Style: .md, .html, .adoc or .txt for godoc
+	OutputExt string `json:"output_ext"`
+
+	// OutputNameTemplate is a text/template for each package's output file,
+	// relative to OutputDir, e.g. "{{.Dir}}/{{.Name}}{{.Ext}}"
+	OutputNameTemplate string `json:"output_name_template"`
+
+	// LLMTimeout is the per-request timeout in seconds, 0 for none
+	LLMTimeout int `json:"llm_timeout"`
+	// LLMRetries is how many times a failed LLM request is retried
+	LLMRetries int `json:"llm_retries"`
+	// FallbackModels are tried in order when a request to the primary model
+	// still fails after its retries
+	FallbackModels []string `json:"fallback_models"`
+	// LLMConcurrency is how many LLM requests may be in flight at once
+	LLMConcurrency int `json:"llm_concurrency"`
+	// Temperature controls how varied model output is, kept low by default so
+	// regenerated docs don't churn
+	Temperature float64 `json:"temperature"`
+	// Seed asks models that support it for reproducible output, 0 for none
+	Seed int `json:"seed"`
+	// RequestsPerMinute caps the rate of LLM requests, 0 for no limit
+	RequestsPerMinute int `json:"requests_per_minute"`
+
+	// Mirror writes each package's docs under a path mirroring its location
+	// in the project, unless OutputNameTemplate is set
+	Mirror bool `json:"mirror"`
+
+	// BatchSize packs up to this many symbol descriptions into one LLM
+	// request, with BatchTokenBudget capping each prompt's estimated tokens
+	BatchSize        int `json:"batch_size"`
+	BatchTokenBudget int `json:"batch_token_budget"`
+
+	// MarkdownExamples uses the Go code blocks in a package's README.md or
+	// examples.md as its examples, in place of generated ones
+	MarkdownExamples bool `json:"markdown_examples"`
+
+	// MergeReadme adds the body of the README.md in a package's directory
+	// after its description, leaving out paragraphs the package doc repeats
+	MergeReadme bool `json:"merge_readme"`
+
+	// ExamplesDir is a directory, relative to the project, of runnable main
+	// packages shown as usage examples of the packages they import rather
+	// than documented themselves
+	ExamplesDir string `json:"examples_dir"`
+
+	// HTTPRoutes documents HTTP handlers and the routes they're registered on
+	HTTPRoutes bool `json:"http_routes"`
+
+	// Implements lists, under each type, the package's interfaces it satisfies
+	Implements bool `json:"implements"`
+
+	// TypeCheck resolves constant and variable types, and the interfaces
+	// listed by Implements, with go/types rather than from syntax alone.
+	// It is slower and needs the packages to compile
+	TypeCheck bool `json:"type_check"`
+
+	// Stream writes model output to the stream output as it arrives
+	Stream bool `json:"stream"`
+
+	// Language is the language generated descriptions are written in
+	Language string `json:"language"`
+	// Labels translates section headings such as "Parameters" and "Returns"
+	Labels map[string]string `json:"labels"`
+
+	// FenceLanguages overrides the language tags of "shell" blocks such as
+	// install commands, "code" blocks and example "output" blocks
+	FenceLanguages map[string]string `json:"fence_languages"`
+
+	// PromptTemplates overrides the "package", "function", "type" and
+	// "example" prompts, each given inline or as a path to a template file
+	PromptTemplates map[string]string `json:"prompt_templates"`
+
+	// MaxDescriptionWords clips generated descriptions to this many words,
+	// 0 for no limit
+	MaxDescriptionWords int `json:"max_description_words"`
+
+	// Offline skips AI enhancement and example generation, rendering docs
+	// from source comments only so output is deterministic
+	Offline bool `json:"offline"`
+
+	// WriteBack writes generated descriptions into the source as doc
+	// comments, for functions and types whose comments are missing or
+	// brief, instead of writing docs. Experimental
+	WriteBack bool `json:"write_back"`
+
+	// TestingSection documents exported test helpers, functions taking a
+	// *testing.T or testing.TB, and the package's testable examples in a
+	// Testing section apart from the rest of the API
+	TestingSection bool `json:"testing_section"`
+
+	// InternalDocs also writes each package's unexported functions, methods
+	// and types to <package>.internal.md next to its docs, keeping them out
+	// of the public page. Markdown only
+	InternalDocs bool `json:"internal_docs"`
+
+	// SkipEmpty skips packages with no exported API rather than writing a
+	// page noting that they have none
+	SkipEmpty bool `json:"skip_empty"`
+
+	// ShowSource notes the file and line each function and type is declared
+	// on. SourceURLTemplate, when set, links them to their source using a
+	// text/template given a SourceLocation, e.g.
+	// "https://github.com/org/repo/blob/{{.Ref}}/{{.File}}#L{{.Line}}"
+	ShowSource        bool   `json:"show_source"`
+	SourceURLTemplate string `json:"source_url_template"`
+	// SourceRef is the commit or branch source links point at, by default
+	// the commit checked out
+	SourceRef string `json:"source_ref"`
+
+	// SplitTypes writes each exported type to its own file, leaving the
+	// package file as an index linking to them (Markdown only)
+	SplitTypes bool `json:"split_types"`
+
+	// Packages lists the names of every package documented in this run
+	Packages []string `json:"-"`
 }
 
-func NewDocGenerator() (*DocGenerator, error) {
-	llm, err := openai.New(
-		openai.WithModel("llama3-8b-8192"),
-		openai.WithBaseURL("https://api.groq.com/openai/v1"),
-		openai.WithToken(os.Getenv("GROQ_API_KEY")),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("creating LLM: %w", err)
+// language returns the language descriptions are written in, English by default.
+func (c DocConfig) language() string {
+	if c.Language == "" {
+		return "English"
 	}
-
-	dg := &DocGenerator{
-		llm:       llm,
-		templates: make(map[string]*template.Template),
-	}
-
-	if err := dg.loadTemplates(); err != nil {
-		return nil, fmt.Errorf("loading templates: %w", err)
-	}
-
-	return dg, nil
+	return c.Language
 }
 
-func (dg *DocGenerator) loadTemplates() error {
-	// Package documentation template
-	packageTmpl := `# {{.Name}}
-
-{{.Description}}
-
-## Installation
-
-'''bash
-go get {{.Path}}
-'''
-
-## Usage
-
-{{if .Examples}}
-{{range .Examples}}
-'''go
-{{.Code}}
-'''
-{{end}}
-{{end}}
+// SplitsTypes reports whether exported types are rendered to their own files.
+// Only Markdown output supports split type pages.
+func (c DocConfig) SplitsTypes() bool {
+	return c.SplitTypes && c.Style != "html" && c.Style != "asciidoc"
+}
 
-## API Reference
+// WritesInternalDocs reports whether unexported symbols are written to a
+// separate file. Like split type pages, it is Markdown only.
+func (c DocConfig) WritesInternalDocs() bool {
+	return c.InternalDocs && c.Style != "html" && c.Style != "asciidoc"
+}
 
-{{if .Functions}}
-### Functions
+// SetStreamOutput sets where model output is written as it arrives when
+// DocConfig.Stream is set. It defaults to standard output.
+func (dg *DocGenerator) SetStreamOutput(w io.Writer) {
+	dg.streamMu.Lock()
+	defer dg.streamMu.Unlock()
+	dg.streamOut = w
+}
 
-{{range .Functions}}
-{{if .IsExported}}
-#### {{.Name}}
+var (
+	// ErrMissingAPIKey is returned by NewDocGenerator when GROQ_API_KEY isn't set.
+	ErrMissingAPIKey = errors.New("GROQ_API_KEY is not set")
+	// ErrLLM wraps the error of an LLM request that failed after its retries
+	// and fallback models.
+	ErrLLM = errors.New("LLM request failed")
+)
 
-'''go
-{{.Signature}}
-'''
+func NewDocGenerator() (*DocGenerator, error) {
+	token := os.Getenv("GROQ_API_KEY")
+	if token == "" {
+		return nil, fmt.Errorf("%w: create a key at https://console.groq.com/keys and run export GROQ_API_KEY=<key>, or use offline mode (--offline) to document from source comments only", ErrMissingAPIKey)
+	}
 
-{{.Description}}
+	newModel := func(name string) (llms.Model, error) {
+		return openai.New(
+			openai.WithModel(name),
+			openai.WithBaseURL("https://api.groq.com/openai/v1"),
+			openai.WithToken(token),
+		)
+	}
 
-{{if .Parameters}}
-**Parameters:**
-{{range .Parameters}}
-- '{{.Name}}' ({{.Type}})
-{{end}}
-{{end}}
+	llm, err := newModel("llama3-8b-8192")
+	if err != nil {
+		return nil, fmt.Errorf("creating LLM: %w", err)
+	}
 
-{{if .Returns}}
-**Returns:**
-{{range .Returns}}
-- {{.Type}}{{if .Description}} - {{.Description}}{{end}}
-{{end}}
-{{end}}
+	dg, err := NewDocGeneratorWithModel(llm)
+	if err != nil {
+		return nil, err
+	}
+	dg.SetModelFactory(newModel)
 
-{{if .Examples}}
-**Example:**
-{{range .Examples}}
-'''go
-{{.}}
-'''
-{{end}}
-{{end}}
+	return dg, nil
+}
 
-{{end}}
-{{end}}
-{{end}}
+// SetModelFactory sets how the models named in DocConfig.FallbackModels are
+// created. Without one, fallback models are ignored.
+func (dg *DocGenerator) SetModelFactory(newModel func(name string) (llms.Model, error)) {
+	dg.modelsMu.Lock()
+	defer dg.modelsMu.Unlock()
+	dg.newModel = newModel
+	dg.models = make(map[string]llms.Model)
+}
 
-{{if .Types}}
-### Types
+// fallbackModel returns the named fallback model, creating it on first use.
+func (dg *DocGenerator) fallbackModel(name string) (llms.Model, error) {
+	dg.modelsMu.Lock()
+	defer dg.modelsMu.Unlock()
 
-{{range .Types}}
-{{if .IsExported}}
-#### {{.Name}}
+	if model, ok := dg.models[name]; ok {
+		return model, nil
+	}
+	if dg.newModel == nil {
+		return nil, fmt.Errorf("no model factory to create fallback model %s", name)
+	}
 
-'''go
-type {{.Name}} {{.Kind}}
-'''
+	model, err := dg.newModel(name)
+	if err != nil {
+		return nil, fmt.Errorf("creating fallback model %s: %w", name, err)
+	}
+	dg.models[name] = model
+	return model, nil
+}
 
-{{.Description}}
+// NewDocGeneratorWithModel creates a DocGenerator backed by the given model,
+// allowing custom LLM backends or fakes in place of the default Groq client.
+func NewDocGeneratorWithModel(model llms.Model) (*DocGenerator, error) {
+	dg := &DocGenerator{
+		llm:       model,
+		templates: make(map[string]*template.Template),
+		streamOut: os.Stdout,
+	}
 
-{{if .Fields}}
-**Fields:**
-{{range .Fields}}
-- '{{.Name}}' {{.Type}}{{if .Description}} - {{.Description}}{{end}}
-{{end}}
-{{end}}
+	if err := dg.loadTemplates(); err != nil {
+		return nil, fmt.Errorf("loading templates: %w", err)
+	}
 
-{{if .Methods}}
-**Methods:**
-{{range .Methods}}
-- [{{.}}](#{{.}})
-{{end}}
-{{end}}
+	return dg, nil
+}
 
-{{end}}
-{{end}}
-{{end}}
-`
+func (dg *DocGenerator) GeneratePackageDoc(pkg *analyser.PackageInfo, config DocConfig) (string, error) {
+	return dg.GeneratePackageDocContext(context.Background(), pkg, config)
+}
 
-	tmpl, err := template.New("package").Parse(packageTmpl)
+func (dg *DocGenerator) GeneratePackageDocContext(ctx context.Context, pkg *analyser.PackageInfo, config DocConfig) (string, error) {
+	templates, err := resolvePromptTemplates(config.PromptTemplates)
 	if err != nil {
-		return fmt.Errorf("parsing package template: %w", err)
+		return "", err
 	}
-	dg.templates["package"] = tmpl
+	config.PromptTemplates = templates
 
-	return nil
-}
+	links, err := dg.sourceLinks(config, pkg)
+	if err != nil {
+		return "", err
+	}
 
-func (dg *DocGenerator) GeneratePackageDoc(pkg *analyser.PackageInfo, config DocConfig) (string, error) {
-	// Enhance descriptions with AI
-	if err := dg.enhanceDescriptions(pkg); err != nil {
-		return "", fmt.Errorf("enhancing descriptions: %w", err)
+	// Enhance descriptions with AI. A failing LLM leaves the docs as written
+	// rather than holding up the package
+	if !config.Offline {
+		if err := dg.enhanceDescriptions(ctx, pkg, config); err != nil {
+			logging.Warnf("Could not enhance descriptions for %s, using them as written: %v", pkg.Name, err)
+		}
 	}
 
 	// Generate usage examples
-	if config.GenerateExamples {
-		if err := dg.generateExamples(pkg); err != nil {
-			return "", fmt.Errorf("generating examples: %w", err)
+	if config.GenerateExamples && !config.Offline && !pkg.IsCommand {
+		if err := dg.generateExamples(ctx, pkg, config); err != nil {
+			logging.Warnf("Could not generate examples for %s: %v", pkg.Name, err)
 		}
 	}
 
+	orderSymbols(pkg, config.SymbolOrder)
+
 	// Apply template
+	tmpl := dg.templates["package"]
+	switch config.Style {
+	case "html":
+		tmpl = dg.templates["html"]
+	case "asciidoc":
+		tmpl = dg.templates["asciidoc"]
+	}
+
+	data := packagePage{
+		PackageInfo: pkg,
+		page:        newPage(pkg, config, links),
+		Packages:    config.Packages,
+		SplitTypes:  config.SplitsTypes(),
+		Testing:     config.TestingSection,
+		Style:       config.Style,
+		Inline:      config.InlineResultTypes,
+	}
+
 	var result strings.Builder
-	if err := dg.templates["package"].Execute(&result, pkg); err != nil {
+	if err := tmpl.Execute(&result, data); err != nil {
 		return "", fmt.Errorf("executing template: %w", err)
 	}
 
-	return result.String(), nil
+	return dg.runHooks(reflowProse(shiftHeadings(result.String(), config.Style, config.HeadingOffset), config.Style, config.WrapWidth), pkg, config)
 }
 
-func (dg *DocGenerator) enhanceDescriptions(pkg *analyser.PackageInfo) error {
-	ctx := context.Background()
+// BriefDescription is the length below which function and type descriptions
+// are rewritten with the LLM.
+const BriefDescription = 20
+
+// EnhanceDescriptions rewrites pkg's brief descriptions with the LLM, as
+// GeneratePackageDoc does, without rendering anything.
+func (dg *DocGenerator) EnhanceDescriptions(ctx context.Context, pkg *analyser.PackageInfo, config DocConfig) error {
+	templates, err := resolvePromptTemplates(config.PromptTemplates)
+	if err != nil {
+		return err
+	}
+	config.PromptTemplates = templates
+
+	return dg.enhanceDescriptions(ctx, pkg, config)
+}
+
+// enhanceDescriptions rewrites the package's brief descriptions with the LLM.
+// A description the LLM fails on is left as written, and the failures are
+// reported together once the rest are done.
+func (dg *DocGenerator) enhanceDescriptions(ctx context.Context, pkg *analyser.PackageInfo, config DocConfig) error {
+	var failed enhanceFailures
 
 	// Enhance package description if empty or too brief
-	if len(pkg.Description) < 50 {
-		enhanced, err := dg.enhancePackageDescription(ctx, pkg)
-		if err == nil && enhanced != "" {
+	if config.EnhancePackage && len(pkg.Description) < 50 {
+		enhanced, err := dg.enhancePackageDescription(ctx, pkg, config)
+		if err != nil {
+			failed.add(err)
+		} else if enhanced != "" {
 			pkg.Description = enhanced
 		}
 	}
 
+	if config.EnhancePackage && pkg.Summary == "" {
+		summary, err := dg.summarisePackage(ctx, pkg, config)
+		if err != nil {
+			failed.add(err)
+		} else if summary != "" {
+			pkg.Summary = summary
+		}
+	}
+
+	// Enhance function and type descriptions, several to a request when batching
+	if config.BatchSize > 1 {
+		dg.enhanceSymbolsBatched(ctx, pkg, config, &failed)
+		return failed.err()
+	}
+
 	// Enhance function descriptions
-	for i := range pkg.Functions {
-		if len(pkg.Functions[i].Description) < 20 {
-			enhanced, err := dg.enhanceFunctionDescription(ctx, &pkg.Functions[i])
-			if err == nil && enhanced != "" {
+	forEachConcurrently(len(pkg.Functions), config.LLMConcurrency, func(i int) {
+		if config.EnhanceFunctions && len(pkg.Functions[i].Description) < BriefDescription {
+			enhanced, err := dg.enhanceFunctionDescription(ctx, &pkg.Functions[i], config)
+			if err != nil {
+				failed.add(err)
+			} else if enhanced != "" {
 				pkg.Functions[i].Description = enhanced
 			}
 		}
-	}
+	})
 
 	// Enhance type descriptions
-	for i := range pkg.Types {
-		if len(pkg.Types[i].Description) < 20 {
-			enhanced, err := dg.enhanceTypeDescription(ctx, &pkg.Types[i])
-			if err == nil && enhanced != "" {
+	forEachConcurrently(len(pkg.Types), config.LLMConcurrency, func(i int) {
+		if config.EnhanceTypes && len(pkg.Types[i].Description) < BriefDescription {
+			enhanced, err := dg.enhanceTypeDescription(ctx, &pkg.Types[i], config)
+			if err != nil {
+				failed.add(err)
+			} else if enhanced != "" {
 				pkg.Types[i].Description = enhanced
 			}
 		}
+	})
+
+	return failed.err()
+}
+
+// enhanceFailures counts the descriptions the LLM failed on, keeping the
+// first error to report.
+type enhanceFailures struct {
+	mu    sync.Mutex
+	count int
+	first error
+}
+
+func (f *enhanceFailures) add(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.first == nil {
+		f.first = err
 	}
+	f.count++
+}
 
-	return nil
+func (f *enhanceFailures) err() error {
+	if f.count == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w (%d requests failed)", f.first, f.count)
 }
 
-func (dg *DocGenerator) enhancePackageDescription(ctx context.Context, pkg *analyser.PackageInfo) (string, error) {
-	template := prompts.NewPromptTemplate(`
+func (dg *DocGenerator) enhancePackageDescription(ctx context.Context, pkg *analyser.PackageInfo, config DocConfig) (string, error) {
+	template := prompts.NewPromptTemplate(config.promptTemplate("package", `
 Analyze this Go package and write a clear, concise description (2-3 sentences):
 
 Package: {{.name}}
 Path: {{.path}}
 
-Functions: {{range .functions}}{{.name}}, {{end}}
-Types: {{range .types}}{{.name}}, {{end}}
+Functions: {{range .functions}}{{.Name}}, {{end}}
+Types: {{range .types}}{{.Name}}, {{end}}
 
 Write a professional description that explains:
 1. What this package does
 2. Who would use it
 3. Key capabilities
 
-Keep it under 200 words and avoid marketing language.`,
-		[]string{"name", "path", "functions", "types"})
+Keep it under 200 words and avoid marketing language.
+Write the description in {{.language}}.`),
+		[]string{"name", "path", "functions", "types", "language"})
 
 	prompt, err := template.Format(map[string]any{
 		"name":      pkg.Name,
 		"path":      pkg.Path,
 		"functions": pkg.Functions,
 		"types":     pkg.Types,
+		"language":  config.language(),
 	})
 	if err != nil {
 		return "", err
 	}
 
-	response, err := dg.llm.GenerateContent(ctx, []llms.MessageContent{
-		llms.TextParts(llms.ChatMessageTypeHuman, prompt),
-	})
+	content, err := dg.complete(ctx, prompt, config)
 	if err != nil {
 		return "", err
 	}
 
-	return strings.TrimSpace(response.Choices[0].Content), nil
+	return clipWords(strings.TrimSpace(content), config.MaxDescriptionWords), nil
 }
 
-func (dg *DocGenerator) enhanceFunctionDescription(ctx context.Context, fn *analyser.FunctionInfo) (string, error) {
+func (dg *DocGenerator) summarisePackage(ctx context.Context, pkg *analyser.PackageInfo, config DocConfig) (string, error) {
 	template := prompts.NewPromptTemplate(`
+Summarise this Go package in a single sentence for readers skimming its documentation:
+
+Package: {{.name}}
+Description: {{.description}}
+
+Reply with the sentence only.
+Write the sentence in {{.language}}.`,
+		[]string{"name", "description", "language"})
+
+	prompt, err := template.Format(map[string]any{
+		"name":        pkg.Name,
+		"description": pkg.Description,
+		"language":    config.language(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	content, err := dg.complete(ctx, prompt, config)
+	if err != nil {
+		return "", err
+	}
+
+	return firstSentence(content), nil
+}
+
+func (dg *DocGenerator) enhanceFunctionDescription(ctx context.Context, fn *analyser.FunctionInfo, config DocConfig) (string, error) {
+	template := prompts.NewPromptTemplate(config.promptTemplate("function", `
 Write a clear description for this Go function:
 
 Function: {{.name}}
 Signature: {{.signature}}
-{{if .parameters}}Parameters: {{range .parameters}}{{.name}} {{.type}}, {{end}}{{end}}
-{{if .returns}}Returns: {{range .returns}}{{.type}}, {{end}}{{end}}
+{{if .parameters}}Parameters: {{range .parameters}}{{.Name}} {{.Type}}, {{end}}{{end}}
+{{if .returns}}Returns: {{range .returns}}{{.Type}}, {{end}}{{end}}
 
 Describe what it does, when to use it, and any important behavior.
-Keep it concise (1-2 sentences).`,
-		[]string{"name", "signature", "parameters", "returns"})
+Keep it concise (1-2 sentences).
+Write the description in {{.language}}.`),
+		[]string{"name", "signature", "parameters", "returns", "language"})
 
 	prompt, err := template.Format(map[string]any{
 		"name":       fn.Name,
 		"signature":  fn.Signature,
 		"parameters": fn.Parameters,
 		"returns":    fn.Returns,
+		"language":   config.language(),
 	})
 	if err != nil {
 		return "", err
 	}
 
-	response, err := dg.llm.GenerateContent(ctx, []llms.MessageContent{
-		llms.TextParts(llms.ChatMessageTypeHuman, prompt),
-	})
+	content, err := dg.complete(ctx, prompt, config)
 	if err != nil {
 		return "", err
 	}
 
-	return strings.TrimSpace(response.Choices[0].Content), nil
+	return clipWords(strings.TrimSpace(content), config.MaxDescriptionWords), nil
 }
 
-func (dg *DocGenerator) enhanceTypeDescription(ctx context.Context, typ *analyser.TypeInfo) (string, error) {
-	template := prompts.NewPromptTemplate(`
+func (dg *DocGenerator) enhanceTypeDescription(ctx context.Context, typ *analyser.TypeInfo, config DocConfig) (string, error) {
+	template := prompts.NewPromptTemplate(config.promptTemplate("type", `
 Write a clear description for this Go type:
 
 Type: {{.name}} ({{.kind}})
-{{if .fields}}Fields: {{range .fields}}{{.name}} {{.type}}, {{end}}{{end}}
+{{if .fields}}Fields: {{range .fields}}{{.Name}} {{.Type}}, {{end}}{{end}}
 {{if .methods}}Methods: {{range .methods}}{{.}}, {{end}}{{end}}
 
 Describe what it represents and how it's used.
-Keep it concise (1-2 sentences).`,
-		[]string{"name", "kind", "fields", "methods"})
+Keep it concise (1-2 sentences).
+Write the description in {{.language}}.`),
+		[]string{"name", "kind", "fields", "methods", "language"})
 
 	prompt, err := template.Format(map[string]any{
-		"name":    typ.Name,
-		"kind":    typ.Kind,
-		"fields":  typ.Fields,
-		"methods": typ.Methods,
+		"name":     typ.Name,
+		"kind":     typ.Kind,
+		"fields":   typ.Fields,
+		"methods":  typ.Methods,
+		"language": config.language(),
 	})
 	if err != nil {
 		return "", err
 	}
 
-	response, err := dg.llm.GenerateContent(ctx, []llms.MessageContent{
-		llms.TextParts(llms.ChatMessageTypeHuman, prompt),
-	})
+	content, err := dg.complete(ctx, prompt, config)
 	if err != nil {
 		return "", err
 	}
 
-	return strings.TrimSpace(response.Choices[0].Content), nil
+	return clipWords(strings.TrimSpace(content), config.MaxDescriptionWords), nil
 }
 
-func (dg *DocGenerator) generateExamples(pkg *analyser.PackageInfo) error {
-	ctx := context.Background()
-
+func (dg *DocGenerator) generateExamples(ctx context.Context, pkg *analyser.PackageInfo, config DocConfig) error {
 	// Generate package-level usage example
-	if len(pkg.Examples) == 0 {
-		example, err := dg.generatePackageExample(ctx, pkg)
+	if !hasPackageExample(pkg) {
+		example, err := dg.generatePackageExample(ctx, pkg, config)
 		if err == nil && example != "" {
-			pkg.Examples = append(pkg.Examples, analyser.ExampleInfo{
-				Name: "Basic Usage",
-				Code: example,
-				Doc:  "Basic usage example",
-			})
+			if err := dg.validateExample(ctx, example, pkg, config.ExampleValidation); err != nil {
+				logging.Warnf("Discarding usage example for %s: %v", pkg.Name, err)
+			} else {
+				pkg.Examples = append(pkg.Examples, analyser.ExampleInfo{
+					Name: "Basic Usage",
+					Code: example,
+					Doc:  "Basic usage example",
+				})
+			}
 		}
 	}
 
 	// Generate function examples
-	for i := range pkg.Functions {
-		if len(pkg.Functions[i].Examples) == 0 && pkg.Functions[i].IsExported {
-			example, err := dg.generateFunctionExample(ctx, &pkg.Functions[i], pkg)
-			if err == nil && example != "" {
-				pkg.Functions[i].Examples = append(pkg.Functions[i].Examples, example)
+	forEachConcurrently(len(pkg.Functions), config.LLMConcurrency, func(i int) {
+		fn := &pkg.Functions[i]
+		if !config.GenerateFunctionExamples || len(fn.Examples) > 0 || !fn.IsExported || len(symbolExamples(pkg, functionSymbol(*fn))) > 0 {
+			return
+		}
+
+		var examples []string
+		for n := 0; n < max(config.ExamplesPerFunction, 1); n++ {
+			if n > 0 && config.BatchTokenBudget > 0 && estimateTokens(strings.Join(examples, "\n")) > config.BatchTokenBudget {
+				break
+			}
+			example, err := dg.generateFunctionExample(ctx, fn, pkg, examples, config)
+			if err != nil || example == "" || slices.Contains(examples, example) {
+				continue
 			}
+			if err := dg.validateExample(ctx, example, pkg, config.ExampleValidation); err != nil {
+				logging.Warnf("Discarding example for %s: %v", fn.Name, err)
+				continue
+			}
+			examples = append(examples, example)
 		}
-	}
+		fn.Examples = examples
+	})
 
 	return nil
 }
 
-func (dg *DocGenerator) generatePackageExample(ctx context.Context, pkg *analyser.PackageInfo) (string, error) {
+func hasPackageExample(pkg *analyser.PackageInfo) bool {
+	for _, ex := range pkg.Examples {
+		if ex.Symbol == "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (dg *DocGenerator) generatePackageExample(ctx context.Context, pkg *analyser.PackageInfo, config DocConfig) (string, error) {
 	template := prompts.NewPromptTemplate(`
 Create a realistic Go code example showing how to use this package:
 
 Package: {{.name}}
 Description: {{.description}}
-Key Functions: {{range .functions}}{{if .is_exported}}{{.name}}, {{end}}{{end}}
-Key Types: {{range .types}}{{if .is_exported}}{{.name}}, {{end}}{{end}}
+Key Functions: {{range .functions}}{{if .IsExported}}{{.Name}}, {{end}}{{end}}
+Key Types: {{range .types}}{{if .IsExported}}{{.Name}}, {{end}}{{end}}
 
 Write a complete, runnable example that shows:
 1. Import statement
@@ -369,46 +697,51 @@ Return only the Go code, no explanations.`,
 		return "", err
 	}
 
-	response, err := dg.llm.GenerateContent(ctx, []llms.MessageContent{
-		llms.TextParts(llms.ChatMessageTypeHuman, prompt),
-	})
+	content, err := dg.complete(ctx, prompt, config)
 	if err != nil {
 		return "", err
 	}
 
-	return strings.TrimSpace(response.Choices[0].Content), nil
+	return extractCode(content), nil
 }
 
-func (dg *DocGenerator) generateFunctionExample(ctx context.Context, fn *analyser.FunctionInfo, pkg *analyser.PackageInfo) (string, error) {
-	template := prompts.NewPromptTemplate(`
+// generateFunctionExample asks for an example of fn showing a use case other
+// than those of the previous examples.
+func (dg *DocGenerator) generateFunctionExample(ctx context.Context, fn *analyser.FunctionInfo, pkg *analyser.PackageInfo, previous []string, config DocConfig) (string, error) {
+	template := prompts.NewPromptTemplate(config.promptTemplate("example", `
 Create a Go code example for this function:
 
 Function: {{.name}}
 Signature: {{.signature}}
 Package: {{.package}}
-{{if .parameters}}Parameters: {{range .parameters}}{{.name}} {{.type}}, {{end}}{{end}}
+{{if .parameters}}Parameters: {{range .parameters}}{{.Name}} {{.Type}}, {{end}}{{end}}
 
 Write a realistic example showing how to call this function.
 Include proper error handling if needed.
-Return only the Go code snippet.`,
-		[]string{"name", "signature", "package", "parameters"})
+{{if .previous}}
+Show a different use case from these existing examples:
+{{range .previous}}
+{{.}}
+{{end}}
+{{end}}
+Return only the Go code snippet.`),
+		[]string{"name", "signature", "package", "parameters", "previous"})
 
 	prompt, err := template.Format(map[string]any{
 		"name":       fn.Name,
 		"signature":  fn.Signature,
 		"package":    pkg.Name,
 		"parameters": fn.Parameters,
+		"previous":   previous,
 	})
 	if err != nil {
 		return "", err
 	}
 
-	response, err := dg.llm.GenerateContent(ctx, []llms.MessageContent{
-		llms.TextParts(llms.ChatMessageTypeHuman, prompt),
-	})
+	content, err := dg.complete(ctx, prompt, config)
 	if err != nil {
 		return "", err
 	}
 
-	return strings.TrimSpace(response.Choices[0].Content), nil
+	return extractCode(content), nil
 }
@@ -0,0 +1,662 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// fakeModel is an in-memory llms.Model that records the prompts it is sent
+// and answers them with respond, or with "generated" when respond is nil.
+type fakeModel struct {
+	mu      sync.Mutex
+	prompts []string
+	respond func(prompt string) (string, error)
+}
+
+func (m *fakeModel) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	var prompt strings.Builder
+	for _, message := range messages {
+		for _, part := range message.Parts {
+			if text, ok := part.(llms.TextContent); ok {
+				prompt.WriteString(text.Text)
+			}
+		}
+	}
+
+	m.mu.Lock()
+	m.prompts = append(m.prompts, prompt.String())
+	m.mu.Unlock()
+
+	content := "generated"
+	if m.respond != nil {
+		var err error
+		if content, err = m.respond(prompt.String()); err != nil {
+			return nil, err
+		}
+	}
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: content}}}, nil
+}
+
+func (m *fakeModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+// Prompts returns the prompts the model has been sent so far.
+func (m *fakeModel) Prompts() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.prompts...)
+}
+
+func newTestGenerator(t *testing.T, model llms.Model) *DocGenerator {
+	t.Helper()
+	if model == nil {
+		model = &fakeModel{}
+	}
+	dg, err := NewDocGeneratorWithModel(model)
+	if err != nil {
+		t.Fatalf("NewDocGeneratorWithModel: %v", err)
+	}
+	return dg
+}
+
+// offlineConfig returns the default config rendering docs from source
+// comments only, in style.
+func offlineConfig(style string) DocConfig {
+	config := DefaultConfig()
+	config.Offline = true
+	config.Style = style
+	return config
+}
+
+// analyseSource analyses a package made of the single file src.
+func analyseSource(t *testing.T, src string) *analyser.PackageInfo {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "pkg.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := analyser.NewAnalyser().AnalysePackage(dir)
+	if err != nil {
+		t.Fatalf("AnalysePackage: %v", err)
+	}
+	return pkg
+}
+
+// render analyses src and renders its docs with config.
+func render(t *testing.T, src string, config DocConfig) string {
+	t.Helper()
+	doc, err := newTestGenerator(t, nil).GeneratePackageDoc(analyseSource(t, src), config)
+	if err != nil {
+		t.Fatalf("GeneratePackageDoc: %v", err)
+	}
+	return doc
+}
+
+func TestRenderTypeAliases(t *testing.T) {
+	src := `package p
+
+// Alias is another name for string.
+type Alias = string
+
+// Defined has string as its underlying type.
+type Defined string
+
+// IDs is a list of IDs.
+type IDs []string
+
+// Counts counts by name.
+type Counts map[string]int
+
+// Option configures a Client.
+type Option func(*Client)
+
+// Client is a client.
+type Client struct{}
+`
+	for _, style := range []string{"markdown", "html", "asciidoc"} {
+		doc := render(t, src, offlineConfig(style))
+		for _, want := range []string{"type Alias = string", "type Defined string", "type IDs []string", "type Counts map[string]int", "type Option func(*Client)", "type Client struct"} {
+			if !strings.Contains(doc, want) {
+				t.Errorf("%s output missing %q:\n%s", style, want, doc)
+			}
+		}
+	}
+}
+
+func TestRenderGenericAliases(t *testing.T) {
+	src := `package p
+
+// Stack is a last-in first-out collection.
+type Stack[T any] struct{ items []T }
+
+// IntStack is a stack of ints.
+type IntStack = Stack[int]
+`
+	tests := map[string]string{
+		"markdown": "**Alias of:** [Stack](#stack)\\[int\\]",
+		"asciidoc": "*Alias of:* <<stack,Stack>>[int]",
+	}
+	for style, link := range tests {
+		doc := render(t, src, offlineConfig(style))
+		for _, want := range []string{"type IntStack = Stack[int]", link} {
+			if !strings.Contains(doc, want) {
+				t.Errorf("%s output missing %q:\n%s", style, want, doc)
+			}
+		}
+	}
+}
+
+func TestRenderConstantsAndVariables(t *testing.T) {
+	src := `package p
+
+import "errors"
+
+// Pi is roughly π.
+const Pi = 3.14
+
+// Names lists the known names.
+var Names = []string{"a"}
+
+// ErrMissing is returned for missing names.
+var ErrMissing = errors.New("missing")
+`
+	for _, style := range []string{"markdown", "html", "asciidoc"} {
+		doc := render(t, src, offlineConfig(style))
+		for _, want := range []string{"Constants", "Pi", "float64", "Variables", "Names", "[]string", "Errors", "ErrMissing"} {
+			if !strings.Contains(doc, want) {
+				t.Errorf("%s output missing %q:\n%s", style, want, doc)
+			}
+		}
+	}
+}
+
+func TestGeneratorWithFakeModel(t *testing.T) {
+	model := &fakeModel{respond: func(prompt string) (string, error) {
+		return "Frobnicates the widget thoroughly.", nil
+	}}
+	dg := newTestGenerator(t, model)
+
+	config := DefaultConfig()
+	config.GenerateExamples = false
+	doc, err := dg.GeneratePackageDoc(analyseSource(t, "package p\n\nfunc Frob(n int) error { return nil }\n"), config)
+	if err != nil {
+		t.Fatalf("GeneratePackageDoc: %v", err)
+	}
+
+	var asked bool
+	for _, prompt := range model.Prompts() {
+		if strings.Contains(prompt, "Function: Frob") && strings.Contains(prompt, "n int") {
+			asked = true
+		}
+	}
+	if !asked {
+		t.Errorf("no prompt described Frob, got %q", model.Prompts())
+	}
+	if !strings.Contains(doc, "Frobnicates the widget thoroughly.") {
+		t.Errorf("output missing the model's description:\n%s", doc)
+	}
+}
+
+func TestInstallationSnippet(t *testing.T) {
+	dg := newTestGenerator(t, nil)
+	config := offlineConfig("markdown")
+
+	public := &analyser.PackageInfo{Name: "store", ImportPath: "example.com/project/store"}
+	doc, err := dg.GeneratePackageDoc(public, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(doc, "go get example.com/project/store") {
+		t.Errorf("public package missing go get line:\n%s", doc)
+	}
+
+	internal := &analyser.PackageInfo{Name: "cache", ImportPath: "example.com/project/internal/cache", IsInternal: true}
+	if doc, err = dg.GeneratePackageDoc(internal, config); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(doc, "go get") {
+		t.Errorf("internal package has an install snippet:\n%s", doc)
+	}
+	if !strings.Contains(doc, "**Internal:**") {
+		t.Errorf("internal package isn't marked internal:\n%s", doc)
+	}
+
+	// Outside a module there is no import path to go get
+	for _, style := range []string{"markdown", "asciidoc"} {
+		loose := &analyser.PackageInfo{Name: "scratch", Path: "/tmp/scratch"}
+		if doc, err = dg.GeneratePackageDoc(loose, offlineConfig(style)); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(doc, "go get") || strings.Contains(doc, "Installation") {
+			t.Errorf("%s: package outside a module has an install snippet:\n%s", style, doc)
+		}
+		if !strings.Contains(doc, "scratch") {
+			t.Errorf("%s: package outside a module isn't named:\n%s", style, doc)
+		}
+	}
+}
+
+func TestMarkdownExamplesReplaceGenerated(t *testing.T) {
+	model := &fakeModel{}
+	pkg := analyseSource(t, "package p\n\n// Put stores a value in the store.\nfunc Put(v string) {}\n")
+	pkg.Examples = []analyser.ExampleInfo{{Name: "Usage", Code: `p.Put("value")`}}
+
+	config := DefaultConfig()
+	config.EnhancePackage = false
+	config.GenerateFunctionExamples = false
+	doc, err := newTestGenerator(t, model).GeneratePackageDoc(pkg, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, prompt := range model.Prompts() {
+		if strings.Contains(prompt, "how to use this package") {
+			t.Errorf("usage example generated despite a hand-written one:\n%s", prompt)
+		}
+	}
+	if !strings.Contains(doc, `p.Put("value")`) {
+		t.Errorf("hand-written example missing:\n%s", doc)
+	}
+}
+
+func TestCommandPage(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":  "module example.com/tools\n\ngo 1.22\n",
+		"main.go": "// Command greet prints a greeting.\npackage main\n\nimport \"flag\"\n\nvar name = flag.String(\"name\", \"world\", \"who to greet\")\n\nfunc main() {}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pkg, err := analyser.NewAnalyser().AnalysePackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := newTestGenerator(t, nil).GeneratePackageDoc(pkg, offlineConfig("markdown"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# tools", "## Command", "tools [flags]", "Command greet prints a greeting."} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("command page missing %q:\n%s", want, doc)
+		}
+	}
+	for _, unwanted := range []string{"go get", "## Installation", "No exported API"} {
+		if strings.Contains(doc, unwanted) {
+			t.Errorf("command page has %q:\n%s", unwanted, doc)
+		}
+	}
+}
+
+func TestFlagsTable(t *testing.T) {
+	pkg := &analyser.PackageInfo{
+		Name:      "main",
+		IsCommand: true,
+		Command:   "greet",
+		Flags: []analyser.FlagInfo{
+			{Name: "name", Type: "string", Default: `"world"`, Usage: "who to greet"},
+			{Name: "verbose", Shorthand: "v", Type: "bool", Default: "false", Usage: "print more"},
+		},
+	}
+	doc, err := newTestGenerator(t, nil).GeneratePackageDoc(pkg, offlineConfig("markdown"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"| Flag | Type | Default | Description |",
+		`| '--name' | string | '"world"' | who to greet |`,
+		"| '--verbose', '-v' | bool | 'false' | print more |",
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("flags table missing %q:\n%s", want, doc)
+		}
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	model := &fakeModel{}
+	dg := newTestGenerator(t, model)
+
+	config := DefaultConfig()
+	config.GenerateExamples = false
+	config.Language = "German"
+	config.Labels = map[string]string{"Parameters": "Parameter", "Functions": "Funktionen"}
+	doc, err := dg.GeneratePackageDoc(analyseSource(t, "package p\n\nfunc Frob(n int) {}\n"), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prompts := model.Prompts()
+	if len(prompts) == 0 {
+		t.Fatal("no prompts sent")
+	}
+	for _, prompt := range prompts {
+		if !strings.Contains(prompt, "in German") {
+			t.Errorf("prompt doesn't ask for German:\n%s", prompt)
+		}
+	}
+	for _, want := range []string{"### Funktionen", "**Parameter:**"} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("output missing translated label %q:\n%s", want, doc)
+		}
+	}
+
+	model = &fakeModel{}
+	config.Language = ""
+	if _, err := newTestGenerator(t, model).GeneratePackageDoc(analyseSource(t, "package p\n\nfunc Frob(n int) {}\n"), config); err != nil {
+		t.Fatal(err)
+	}
+	if prompts := model.Prompts(); len(prompts) == 0 || !strings.Contains(prompts[0], "in English") {
+		t.Errorf("default prompt doesn't ask for English: %q", prompts)
+	}
+}
+
+func TestMissingAPIKey(t *testing.T) {
+	t.Setenv("GROQ_API_KEY", "")
+
+	_, err := NewDocGenerator()
+	if !errors.Is(err, ErrMissingAPIKey) {
+		t.Fatalf("got %v, want ErrMissingAPIKey", err)
+	}
+	for _, want := range []string{"GROQ_API_KEY", "export GROQ_API_KEY=", "--offline"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q doesn't mention %q", err, want)
+		}
+	}
+
+	t.Setenv("GROQ_API_KEY", "test-key")
+	if _, err := NewDocGenerator(); err != nil {
+		t.Errorf("with a key set: %v", err)
+	}
+}
+
+func TestShowSource(t *testing.T) {
+	src := `package p
+
+// Hello returns a greeting.
+func Hello() string { return "hello" }
+`
+	config := offlineConfig("markdown")
+	if doc := render(t, src, config); strings.Contains(doc, "Source:") {
+		t.Errorf("source shown without show_source:\n%s", doc)
+	}
+
+	config.ShowSource = true
+	if doc := render(t, src, config); !strings.Contains(doc, "Source: 'pkg.go:4'") {
+		t.Errorf("output missing source position:\n%s", doc)
+	}
+}
+
+func TestTestingSection(t *testing.T) {
+	src := `package store
+
+import "testing"
+
+// Open opens the store.
+func Open() error { return nil }
+
+// MustOpen opens the store, failing tb on error.
+func MustOpen(tb testing.TB) {}
+`
+	config := offlineConfig("markdown")
+	doc := render(t, src, config)
+	if strings.Contains(doc, "### Testing") || !strings.Contains(doc, "#### MustOpen") {
+		t.Errorf("helper not in the API reference without testing_section:\n%s", doc)
+	}
+
+	config.TestingSection = true
+	doc = render(t, src, config)
+	reference, section, ok := strings.Cut(doc, "### Testing")
+	if !ok {
+		t.Fatalf("output missing Testing section:\n%s", doc)
+	}
+	if strings.Contains(reference, "MustOpen") || !strings.Contains(reference, "#### Open") {
+		t.Errorf("API reference should list Open but not MustOpen:\n%s", doc)
+	}
+	if !strings.Contains(section, "#### MustOpen") {
+		t.Errorf("Testing section missing MustOpen:\n%s", doc)
+	}
+}
+
+func TestRedactPrivateFields(t *testing.T) {
+	dir := t.TempDir()
+	src := "package p\n\n// Config configures the client.\ntype Config struct {\n\tName  string `json:\"name\"`\n\ttoken string `json:\"token\"`\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "pkg.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	a := analyser.NewAnalyser()
+	a.PrivateFields = true
+	pkg, err := a.AnalysePackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := offlineConfig("markdown")
+	config.FieldStyle = "table"
+	config.IncludePrivate = true
+	config.RedactPrivateFields = true
+	doc, err := newTestGenerator(t, nil).GeneratePackageDoc(pkg, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"| 'token' | string (unexported) |  |", "| 'Name' | string | `json:\"name\"` |"} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("output missing %q:\n%s", want, doc)
+		}
+	}
+	if strings.Contains(doc, `json:"token"`) {
+		t.Errorf("output shows the unexported field's tag:\n%s", doc)
+	}
+}
+
+func TestRenderReadme(t *testing.T) {
+	pkg := analyseSource(t, "// Package store keeps values in memory.\npackage store\n")
+	pkg.Readme = "## Limits\n\nValues larger than 1MB are rejected."
+	doc, err := newTestGenerator(t, nil).GeneratePackageDoc(pkg, offlineConfig("markdown"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(doc, "Package store keeps values in memory.\n\n## Limits\n\nValues larger than 1MB are rejected.") {
+		t.Errorf("README not merged after the package doc:\n%s", doc)
+	}
+}
+
+func TestEnhancementFailureKeepsSourceDocs(t *testing.T) {
+	model := &fakeModel{respond: func(prompt string) (string, error) {
+		return "", errors.New("model unavailable")
+	}}
+	src := "// Package p frobs.\npackage p\n\n// Frob frobnicates n widgets.\nfunc Frob(n int) error { return nil }\n\n// Widget is frobbed.\ntype Widget struct{}\n"
+
+	doc, err := newTestGenerator(t, model).GeneratePackageDoc(analyseSource(t, src), DefaultConfig())
+	if err != nil {
+		t.Fatalf("GeneratePackageDoc: %v", err)
+	}
+	if len(model.Prompts()) == 0 {
+		t.Fatal("the model was never asked")
+	}
+	for _, want := range []string{"# p", "Package p frobs.", "#### Frob", "Frob frobnicates n widgets.", "#### Widget", "Widget is frobbed."} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("output missing %q:\n%s", want, doc)
+		}
+	}
+}
+
+func TestEnhancementToggles(t *testing.T) {
+	src := "package p\n\n// Frob frobs.\nfunc Frob(n int) error { return nil }\n\n// Widget is frobbed.\ntype Widget struct{}\n"
+	kinds := []string{"package", "function", "type", "example"}
+	toggles := map[string]func(*DocConfig){
+		"package":  func(c *DocConfig) { c.EnhancePackage = true },
+		"function": func(c *DocConfig) { c.EnhanceFunctions = true },
+		"type":     func(c *DocConfig) { c.EnhanceTypes = true },
+		"example":  func(c *DocConfig) { c.GenerateFunctionExamples = true },
+	}
+
+	for _, kind := range kinds {
+		config := DefaultConfig()
+		config.EnhancePackage = false
+		config.EnhanceFunctions = false
+		config.EnhanceTypes = false
+		config.GenerateFunctionExamples = false
+		config.PromptTemplates = map[string]string{
+			"package":  "PACKAGE {{.name}}",
+			"function": "FUNCTION {{.name}} {{.signature}}",
+			"type":     "TYPE {{.name}}",
+			"example":  "EXAMPLE {{.name}} {{.signature}}",
+		}
+		toggles[kind](&config)
+
+		model := &fakeModel{}
+		if _, err := newTestGenerator(t, model).GeneratePackageDoc(analyseSource(t, src), config); err != nil {
+			t.Fatalf("%s: GeneratePackageDoc: %v", kind, err)
+		}
+
+		asked := make(map[string]bool)
+		for _, prompt := range model.Prompts() {
+			for _, k := range kinds {
+				if strings.HasPrefix(prompt, strings.ToUpper(k)+" ") {
+					asked[k] = true
+				}
+			}
+		}
+		for _, k := range kinds {
+			if asked[k] != (k == kind) {
+				t.Errorf("with only %s enabled: %s prompt sent = %t", kind, k, asked[k])
+			}
+		}
+	}
+}
+
+func TestRenderFunctionalOptions(t *testing.T) {
+	src := `package p
+
+import "time"
+
+// Client talks to the server.
+type Client struct{}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// New returns a client for addr.
+func New(addr string, opts ...Option) *Client { return nil }
+
+// WithTimeout sets the request timeout.
+func WithTimeout(d time.Duration) Option { return nil }
+`
+	doc := render(t, src, offlineConfig("markdown"))
+	want := "**Options:**\n\n- [WithTimeout](#withtimeout)"
+	if !strings.Contains(doc, want) {
+		t.Errorf("output missing %q:\n%s", want, doc)
+	}
+}
+
+func TestRenderUnionConstraints(t *testing.T) {
+	src := `package p
+
+// Abs returns the absolute value of v.
+func Abs[T ~int | ~float64](v T) T { return v }
+`
+	doc := render(t, src, offlineConfig("markdown"))
+	for _, want := range []string{"func Abs[T ~int | ~float64](v T) T", "**Constraints:**\n\n- 'T' ~int | ~float64"} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("output missing %q:\n%s", want, doc)
+		}
+	}
+}
+
+func TestExamplesPerFunction(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	model := &fakeModel{respond: func(prompt string) (string, error) {
+		if !strings.Contains(prompt, "Create a Go code example for this function") {
+			return "", nil
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		return fmt.Sprintf("```go\nn := p.Frob(%d)\n_ = n\n```", calls), nil
+	}}
+
+	config := DefaultConfig()
+	config.EnhancePackage = false
+	config.EnhanceFunctions = false
+	config.EnhanceTypes = false
+	config.ExamplesPerFunction = 3
+	doc, err := newTestGenerator(t, model).GeneratePackageDoc(analyseSource(t, "package p\n\n// Frob frobs n.\nfunc Frob(n int) int { return n }\n"), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 3 {
+		t.Errorf("asked for %d examples, want 3", calls)
+	}
+	for i := 1; i <= 3; i++ {
+		want := fmt.Sprintf("_Example %d_\n\n'''go\nn := p.Frob(%d)", i, i)
+		if !strings.Contains(doc, want) {
+			t.Errorf("output missing %q:\n%s", want, doc)
+		}
+	}
+	var last string
+	for _, prompt := range model.Prompts() {
+		if strings.Contains(prompt, "Create a Go code example for this function") {
+			last = prompt
+		}
+	}
+	if !strings.Contains(last, "p.Frob(1)") || !strings.Contains(last, "p.Frob(2)") {
+		t.Errorf("last example prompt doesn't list the earlier examples:\n%s", last)
+	}
+
+	// Once the examples so far use up the token budget no more are asked for
+	calls = 0
+	config.BatchTokenBudget = 1
+	if _, err := newTestGenerator(t, model).GeneratePackageDoc(analyseSource(t, "package p\n\n// Frob frobs n.\nfunc Frob(n int) int { return n }\n"), config); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("asked for %d examples over the token budget, want 1", calls)
+	}
+}
+
+func TestFieldDefaultsColumn(t *testing.T) {
+	src := `package p
+
+// Config configures a Client.
+type Config struct {
+	// Addr is the server address.
+	// Default: localhost:8080
+	Addr string
+	// Name identifies the client.
+	Name string
+}
+`
+	config := offlineConfig("markdown")
+	config.FieldStyle = "table"
+	doc := render(t, src, config)
+	for _, want := range []string{
+		"| Name | Type | Tag | Default | Description |",
+		"| 'Addr' | string |  | 'localhost:8080' | Addr is the server address. |",
+		"| 'Name' | string |  |  | Name identifies the client. |",
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("output missing %q:\n%s", want, doc)
+		}
+	}
+
+	config.FieldStyle = "list"
+	if doc := render(t, src, config); !strings.Contains(doc, "- 'Addr' string (default 'localhost:8080') - Addr is the server address.") {
+		t.Errorf("field list missing the default:\n%s", doc)
+	}
+}
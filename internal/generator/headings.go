@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const maxHeadingLevel = 6
+
+var htmlHeading = regexp.MustCompile(`<(/?)h([1-6])\b`)
+
+// shiftHeadings demotes every heading in doc, rendered in style, by offset
+// levels, stopping at the deepest level. Code blocks are left alone.
+func shiftHeadings(doc string, style string, offset int) string {
+	if offset < 1 {
+		return doc
+	}
+
+	switch style {
+	case "html":
+		return htmlHeading.ReplaceAllStringFunc(doc, func(tag string) string {
+			m := htmlHeading.FindStringSubmatch(tag)
+			level, _ := strconv.Atoi(m[2])
+			return "<" + m[1] + "h" + strconv.Itoa(min(level+offset, maxHeadingLevel))
+		})
+	case "asciidoc":
+		return shiftLineHeadings(doc, '=', []string{"----"}, offset)
+	default:
+		return shiftLineHeadings(doc, '#', []string{"```", "'''"}, offset)
+	}
+}
+
+// shiftLineHeadings demotes headings written as a run of marker characters
+// followed by a space, skipping lines inside blocks opened by one of fences.
+func shiftLineHeadings(doc string, marker byte, fences []string, offset int) string {
+	lines := strings.Split(doc, "\n")
+	inBlock := false
+	for i, line := range lines {
+		for _, fence := range fences {
+			if strings.HasPrefix(line, fence) {
+				inBlock = !inBlock
+				break
+			}
+		}
+		if inBlock {
+			continue
+		}
+
+		level := 0
+		for level < len(line) && line[level] == marker {
+			level++
+		}
+		if level == 0 || level > maxHeadingLevel || level == len(line) || line[level] != ' ' {
+			continue
+		}
+		lines[i] = strings.Repeat(string(marker), min(level+offset, maxHeadingLevel)) + line[level:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,54 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShiftHeadings(t *testing.T) {
+	tests := []struct {
+		style  string
+		doc    string
+		offset int
+		want   string
+	}{
+		{"markdown", "# p\n\n## Functions\n\n'''go\n# not a heading\n'''\n", 1, "## p\n\n### Functions\n\n'''go\n# not a heading\n'''\n"},
+		{"markdown", "##### Deep\n###### Deepest\n", 2, "###### Deep\n###### Deepest\n"},
+		{"markdown", "# p\n", 0, "# p\n"},
+		{"html", "<h1 id=\"p\">p</h1>\n<h6>x</h6>\n", 1, "<h2 id=\"p\">p</h2>\n<h6>x</h6>\n"},
+		{"asciidoc", "= p\n\n== Types\n\n----\n= code\n----\n", 1, "== p\n\n=== Types\n\n----\n= code\n----\n"},
+	}
+	for _, test := range tests {
+		if got := shiftHeadings(test.doc, test.style, test.offset); got != test.want {
+			t.Errorf("%s offset %d:\ngot  %q\nwant %q", test.style, test.offset, got, test.want)
+		}
+	}
+}
+
+func TestHeadingOffset(t *testing.T) {
+	src := "// Package p does things.\npackage p\n\n// Open opens.\nfunc Open() {}\n\n// File is a file.\ntype File struct{}\n\n// Close closes.\nfunc (f *File) Close() {}\n"
+	plain := render(t, src, offlineConfig("markdown"))
+
+	config := offlineConfig("markdown")
+	config.HeadingOffset = 1
+	shifted := render(t, src, config)
+
+	var want []string
+	for _, line := range strings.Split(plain, "\n") {
+		if strings.HasPrefix(line, "#") {
+			want = append(want, "#"+line)
+		}
+	}
+	var got []string
+	for _, line := range strings.Split(shifted, "\n") {
+		if strings.HasPrefix(line, "#") {
+			got = append(got, line)
+		}
+	}
+	if len(want) == 0 || strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("headings not all demoted by one:\ngot  %q\nwant %q", got, want)
+	}
+	if !strings.Contains(shifted, `<a id="file-close"></a>`) {
+		t.Errorf("anchors changed by the offset:\n%s", shifted)
+	}
+}
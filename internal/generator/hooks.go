@@ -0,0 +1,90 @@
+package generator
+
+import (
+	"fmt"
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"regexp"
+	"strings"
+)
+
+// PostRenderHook transforms a package's rendered documentation, for example
+// to inject badges or apply a house style. Hooks run in the order they were
+// added, each receiving the previous hook's output.
+type PostRenderHook func(doc string, pkg *analyser.PackageInfo) (string, error)
+
+// AddPostRenderHook registers hook to run on every package, type and internal
+// page after it is rendered, following CollapseBlankLines when
+// DocConfig.CollapseBlankLines is set.
+func (dg *DocGenerator) AddPostRenderHook(hook PostRenderHook) {
+	dg.hooks = append(dg.hooks, hook)
+}
+
+func (dg *DocGenerator) runHooks(doc string, pkg *analyser.PackageInfo, config DocConfig) (string, error) {
+	hooks := dg.hooks
+	if config.CollapseBlankLines {
+		hooks = append([]PostRenderHook{CollapseBlankLines}, hooks...)
+	}
+	for _, hook := range hooks {
+		var err error
+		if doc, err = hook(doc, pkg); err != nil {
+			return "", fmt.Errorf("running post-render hook: %w", err)
+		}
+	}
+	return doc, nil
+}
+
+var blankLineRuns = regexp.MustCompile(`\n{3,}`)
+
+// CollapseBlankLines is a PostRenderHook that squeezes runs of blank lines,
+// left behind by template actions, into a single blank line. Code blocks and
+// example output are left as they are.
+func CollapseBlankLines(doc string, pkg *analyser.PackageInfo) (string, error) {
+	var out, prose strings.Builder
+	closing := ""
+	for _, line := range strings.SplitAfter(doc, "\n") {
+		if closing == "" {
+			if closing = blockCloser(line); closing == "" {
+				prose.WriteString(line)
+				continue
+			}
+			out.WriteString(blankLineRuns.ReplaceAllString(prose.String(), "\n\n"))
+			prose.Reset()
+			out.WriteString(line)
+			continue
+		}
+
+		if !strings.HasPrefix(strings.TrimSpace(line), closing) && !(closing == "</pre>" && strings.Contains(line, closing)) {
+			out.WriteString(line)
+			continue
+		}
+		// The closing line's newline starts any run of blank lines after it
+		closing = ""
+		text, newline := strings.CutSuffix(line, "\n")
+		out.WriteString(text)
+		if newline {
+			prose.WriteString("\n")
+		}
+	}
+	out.WriteString(blankLineRuns.ReplaceAllString(prose.String(), "\n\n"))
+
+	return out.String(), nil
+}
+
+// blockCloser returns what ends the code block line opens: a Markdown fence,
+// an AsciiDoc listing or literal block, or an HTML <pre> element. It returns
+// "" when line doesn't open one.
+func blockCloser(line string) string {
+	trimmed := strings.TrimSpace(line)
+	for _, fence := range []string{"```", "'''", "~~~"} {
+		if strings.HasPrefix(trimmed, fence) {
+			return fence
+		}
+	}
+	if trimmed == "----" || trimmed == "...." {
+		return trimmed
+	}
+	if i := strings.Index(line, "<pre"); i >= 0 && !strings.Contains(line[i:], "</pre>") {
+		return "</pre>"
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+package generator
+
+import (
+	"errors"
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var headingLine = regexp.MustCompile(`(?m)^#+ .*$`)
+
+func upperHeadings(doc string, pkg *analyser.PackageInfo) (string, error) {
+	return headingLine.ReplaceAllStringFunc(doc, strings.ToUpper), nil
+}
+
+func TestPostRenderHook(t *testing.T) {
+	dg := newTestGenerator(t, nil)
+	dg.AddPostRenderHook(upperHeadings)
+
+	pkg := analyseSource(t, "package store\n\n// Store holds values.\ntype Store struct{}\n\n// Put stores v.\nfunc Put(v string) {}\n")
+	config := offlineConfig("markdown")
+	doc, err := dg.GeneratePackageDoc(pkg, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# STORE\n", "## API REFERENCE", "#### PUT"} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("hook didn't run on the package page, missing %q:\n%s", want, doc)
+		}
+	}
+
+	typeDoc, err := dg.GenerateTypeDoc(pkg, pkg.Types[0], config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(typeDoc, "# STORE") {
+		t.Errorf("hook didn't run on the type page:\n%s", typeDoc)
+	}
+}
+
+func TestPostRenderHookError(t *testing.T) {
+	dg := newTestGenerator(t, nil)
+	failed := errors.New("link check failed")
+	dg.AddPostRenderHook(func(doc string, pkg *analyser.PackageInfo) (string, error) { return "", failed })
+
+	_, err := dg.GeneratePackageDoc(analyseSource(t, "package store\n"), offlineConfig("markdown"))
+	if !errors.Is(err, failed) {
+		t.Errorf("got %v, want the hook's error", err)
+	}
+}
+
+func TestCollapseBlankLinesByDefault(t *testing.T) {
+	for _, style := range []string{"markdown", "html", "asciidoc"} {
+		doc := render(t, "package store\n\n// Put stores v.\nfunc Put(v string) {}\n", offlineConfig(style))
+		if strings.Contains(doc, "\n\n\n") {
+			t.Errorf("%s output has runs of blank lines:\n%s", style, doc)
+		}
+	}
+
+	got, err := CollapseBlankLines("a\n\n\n\nb\n\nc\n", nil)
+	if err != nil || got != "a\n\nb\n\nc\n" {
+		t.Errorf("CollapseBlankLines: got %q, %v", got, err)
+	}
+}
+
+func TestCollapseBlankLinesKeepsCodeBlocks(t *testing.T) {
+	tests := []struct {
+		name, doc, want string
+	}{
+		{"markdown", "a\n\n\n```go\nx := 1\n\n\ny := 2\n```\n\n\nb\n", "a\n\n```go\nx := 1\n\n\ny := 2\n```\n\nb\n"},
+		{"template fence", "'''\nfirst\n\n\nsecond\n'''\n\n\n\nb", "'''\nfirst\n\n\nsecond\n'''\n\nb"},
+		{"asciidoc", "a\n\n\n----\nfirst\n\n\nsecond\n----\n\n\nb\n", "a\n\n----\nfirst\n\n\nsecond\n----\n\nb\n"},
+		{"html", "<p>a</p>\n\n\n<pre><code>first\n\n\nsecond</code></pre>\n\n\n<p>b</p>\n", "<p>a</p>\n\n<pre><code>first\n\n\nsecond</code></pre>\n\n<p>b</p>\n"},
+		{"unclosed", "a\n\n\n```\nx\n\n\n", "a\n\n```\nx\n\n\n"},
+	}
+	for _, tt := range tests {
+		got, err := CollapseBlankLines(tt.doc, nil)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: got %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestCollapseBlankLinesDisabled(t *testing.T) {
+	config := offlineConfig("markdown")
+	config.CollapseBlankLines = false
+	doc := render(t, "package store\n\n// Put stores v.\nfunc Put(v string) {}\n", config)
+	if !strings.Contains(doc, "\n\n\n") {
+		t.Errorf("blank lines collapsed though CollapseBlankLines is off:\n%s", doc)
+	}
+}
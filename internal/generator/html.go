@@ -0,0 +1,344 @@
+package generator
+
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{if .IsCommand}}{{html .Command}}{{else}}{{html .Name}}{{end}} - Documentation</title>
+<style>
+body { margin: 0; font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #1f2328; }
+#layout { display: flex; min-height: 100vh; }
+#sidebar { width: 260px; flex-shrink: 0; background: #f6f8fa; border-right: 1px solid #d0d7de; padding: 1rem; box-sizing: border-box; overflow-y: auto; position: sticky; top: 0; height: 100vh; }
+#sidebar.collapsed { width: 2.5rem; padding: 1rem 0.25rem; }
+#sidebar.collapsed nav { display: none; }
+#sidebar summary { cursor: pointer; font-weight: 600; margin: 0.5rem 0; }
+#sidebar ul { list-style: none; margin: 0; padding-left: 0.75rem; }
+#sidebar li { margin: 0.2rem 0; }
+#sidebar a { color: #0969da; text-decoration: none; }
+#sidebar a:hover { text-decoration: underline; }
+#sidebar .current { font-weight: 600; }
+#toggle { border: none; background: none; cursor: pointer; font-size: 1.2rem; }
+main { flex: 1; padding: 1rem 2rem; max-width: 960px; }
+pre { background: #f6f8fa; padding: 0.75rem; border-radius: 6px; overflow-x: auto; }
+code { font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: 0.9em; }
+section { margin-bottom: 2rem; }
+.badge { display: inline-block; font-size: 0.75rem; padding: 0.1rem 0.4rem; border-radius: 1rem; background: #ddf4ff; color: #0969da; vertical-align: middle; }
+</style>
+</head>
+<body>
+<div id="layout">
+<aside id="sidebar">
+<button id="toggle" title="Toggle navigation">&#9776;</button>
+<nav>
+{{if .Packages}}
+<details open>
+<summary>Packages</summary>
+<ul>
+{{range .Packages}}
+<li><a href="{{html .}}{{$.Ext}}"{{if eq . $.Name}} class="current"{{end}}>{{html .}}</a></li>
+{{end}}
+</ul>
+</details>
+{{end}}
+{{if .Functions}}
+<details open>
+<summary>{{html ($.Label "Functions")}}</summary>
+<ul>
+{{range .Functions}}
+{{if and .IsExported (not .IsMethod) ($.InAPI .)}}
+<li><a href="#{{$.Anchor .Name}}">{{html .Name}}</a></li>
+{{end}}
+{{end}}
+</ul>
+</details>
+{{end}}
+{{with .TestHelpers}}
+<details open>
+<summary>{{html ($.Label "Testing")}}</summary>
+<ul>
+{{range .}}
+<li><a href="#{{$.FuncAnchor .}}">{{html .Name}}</a></li>
+{{end}}
+</ul>
+</details>
+{{end}}
+{{if .Types}}
+<details open>
+<summary>{{html ($.Label "Types")}}</summary>
+<ul>
+{{range .Types}}
+{{if .IsExported}}
+<li>
+<a href="#{{$.Anchor .Name}}">{{html .Name}}</a>
+{{if .Methods}}
+<ul>
+{{$type := .Name}}
+{{range .Methods}}
+<li><a href="#{{$.Anchor (printf "%s.%s" $type .)}}">{{html .}}</a></li>
+{{end}}
+</ul>
+{{end}}
+</li>
+{{end}}
+{{end}}
+</ul>
+</details>
+{{end}}
+</nav>
+</aside>
+<main>
+<h1 id="{{slugify .Name}}-package">{{if .IsCommand}}{{html .Command}}{{else}}{{html .Name}}{{end}}</h1>
+{{if .Summary}}
+<p><em>{{html .Summary}}</em></p>
+{{end}}
+{{if .Overview}}
+<pre>{{html .Overview}}</pre>
+{{else}}
+<p>{{html .Description}}</p>
+{{end}}
+{{with .Readme}}
+<pre>{{html .}}</pre>
+{{end}}
+
+{{with .PackageExamples}}
+<h2>{{html ($.Label "Usage")}}</h2>
+{{range .}}
+<pre><code>{{html .Code}}</code></pre>
+{{if .Output}}
+<p>{{html ($.Label "Output")}}:</p>
+<pre>{{html .Output}}</pre>
+{{end}}
+{{end}}
+{{end}}
+
+{{if .IsCommand}}
+<h2>{{html ($.Label "Command")}}</h2>
+<pre><code>{{html .Command}} [flags]</code></pre>
+{{if .Flags}}
+<h3>{{html ($.Label "Flags")}}</h3>
+<table>
+<tr><th>Flag</th><th>Type</th><th>Default</th><th>Description</th></tr>
+{{range .Flags}}
+<tr><td><code>--{{html .Name}}</code>{{if .Shorthand}}, <code>-{{html .Shorthand}}</code>{{end}}</td><td>{{html .Type}}</td><td><code>{{html .Default}}</code></td><td>{{html .Usage}}</td></tr>
+{{end}}
+</table>
+{{end}}
+{{else}}
+{{if not .HasAPI}}
+<p><em>{{html ($.Label "No exported API.")}}</em></p>
+{{end}}
+{{if .Routes}}
+<h2>{{html ($.Label "Routes")}}</h2>
+<table>
+<tr><th>Method</th><th>Path</th><th>Handler</th></tr>
+{{range .Routes}}
+<tr><td>{{or .Method "-"}}</td><td>{{if .Path}}<code>{{html .Path}}</code>{{else}}-{{end}}</td><td><code>{{html .Handler}}</code></td></tr>
+{{end}}
+</table>
+{{end}}
+
+{{with .ExportedConstants}}
+<h2>{{html ($.Label "Constants")}}</h2>
+<ul>
+{{range .}}
+<li><code>{{html .Name}}{{if .Type}} {{html .Type}}{{end}}</code>{{if .Value}} = <code>{{html .Value}}</code>{{end}}{{if .Description}} - {{html .Description}}{{end}}</li>
+{{end}}
+</ul>
+{{end}}
+
+{{with .ExportedVariables}}
+<h2>{{html ($.Label "Variables")}}</h2>
+<ul>
+{{range .}}
+<li><code>{{html .Name}}{{if .Type}} {{html .Type}}{{end}}</code>{{if .Value}} = <code>{{html .Value}}</code>{{end}}{{if .Description}} - {{html .Description}}{{end}}</li>
+{{end}}
+</ul>
+{{end}}
+
+{{with .Errors}}
+<h2>{{html ($.Label "Errors")}}</h2>
+<ul>
+{{range .}}
+<li><code>{{html .Name}}</code>{{if .Description}} - {{html .Description}}{{end}}</li>
+{{end}}
+</ul>
+{{end}}
+
+{{if .Functions}}
+<h2>{{html ($.Label "Functions")}}</h2>
+{{range .Functions}}
+{{if and .IsExported (not .IsMethod) ($.InAPI .)}}
+<section>
+<h3 id="{{$.FuncAnchor .}}">{{html .Name}}{{if .Since}} <span class="badge">since {{html .Since}}</span>{{end}}</h3>
+<pre><code>{{html ($.FormatSignature .)}}</code></pre>
+{{if and $.ShowSource .File}}
+{{$url := $.SourceURL .File .Line}}<p>{{html ($.Label "Source")}}: {{if $url}}<a href="{{html $url}}">{{html .File}}:{{.Line}}</a>{{else}}<code>{{html .File}}:{{.Line}}</code>{{end}}</p>
+{{end}}
+<p>{{html .Description}}</p>
+{{range $.InlineTypes .}}
+<pre><code>{{html .Definition}}</code></pre>
+{{end}}
+{{$numbered := gt (len .Examples) 1}}
+{{range $i, $code := .Examples}}
+{{if $numbered}}<p>{{html ($.ExampleNumber $i)}}</p>
+{{end}}<pre><code>{{html $code}}</code></pre>
+{{end}}
+{{range $.FunctionExamples .}}
+<pre><code>{{html .Code}}</code></pre>
+{{if .Output}}
+<p>{{html ($.Label "Output")}}:</p>
+<pre>{{html .Output}}</pre>
+{{end}}
+{{end}}
+</section>
+{{end}}
+{{end}}
+{{end}}
+
+{{if .Types}}
+<h2>{{html ($.Label "Types")}}</h2>
+{{range .Types}}
+{{if .IsExported}}
+<section>
+<h3 id="{{$.Anchor .Name}}">{{html .Name}}{{if .Since}} <span class="badge">since {{html .Since}}</span>{{end}}</h3>
+<pre><code>type {{html .Name}}{{html (typeParams .TypeParams)}}{{if .IsAlias}} = {{html .Underlying}}{{else if .Underlying}} {{html .Underlying}}{{else}} {{html .Kind}}{{end}}</code></pre>
+{{if and $.ShowSource .File}}
+{{$url := $.SourceURL .File .Line}}<p>{{html ($.Label "Source")}}: {{if $url}}<a href="{{html $url}}">{{html .File}}:{{.Line}}</a>{{else}}<code>{{html .File}}:{{.Line}}</code>{{end}}</p>
+{{end}}
+<p>{{html .Description}}</p>
+{{with .Implements}}
+<p>{{html ($.Label "Implements")}}: {{range $i, $name := .}}{{if $i}}, {{end}}<a href="#{{$.Anchor $name}}"><code>{{html $name}}</code></a>{{end}}</p>
+{{end}}
+{{with .Embeds}}
+<p>{{html ($.Label "Embeds")}}: {{range $i, $name := .}}{{if $i}}, {{end}}<code>{{html $name}}</code>{{end}}</p>
+{{end}}
+{{with .MethodSet}}
+<h4>{{html ($.Label "Methods")}}</h4>
+<ul>
+{{range .}}
+<li><code>{{html .}}</code></li>
+{{end}}
+</ul>
+{{end}}
+{{if .Constructors}}
+<h4>{{html ($.Label "Constructors")}}</h4>
+<ul>
+{{range .Constructors}}
+<li><a href="#{{$.Anchor .}}"><code>{{html .}}</code></a></li>
+{{end}}
+</ul>
+{{end}}
+{{if .Options}}
+<h4>{{html ($.Label "Options")}}</h4>
+<ul>
+{{range .Options}}
+<li><a href="#{{$.Anchor .}}"><code>{{html .}}</code></a></li>
+{{end}}
+</ul>
+{{end}}
+{{if .Fields}}
+<ul>
+{{range .Fields}}
+<li><code>{{html .Name}} {{html .Type}}</code>{{if $.Redacted .}} ({{html ($.Label "unexported")}}){{end}}{{with .Default}} ({{html ($.Label "default")}} <code>{{html .}}</code>){{end}}{{if .Description}} - {{html .Description}}{{end}}</li>
+{{end}}
+</ul>
+{{end}}
+{{if or .TypeParams .TypeSet}}
+<h4>{{html ($.Label "Constraints")}}</h4>
+<ul>
+{{range .TypeParams}}
+<li><code>{{html .Name}} {{html .Constraint}}</code></li>
+{{end}}
+{{range .TypeSet}}
+<li><code>{{html .}}</code></li>
+{{end}}
+</ul>
+{{end}}
+{{range .Examples}}
+<pre><code>{{html .}}</code></pre>
+{{end}}
+{{range $.ExamplesFor .Name}}
+<pre><code>{{html .Code}}</code></pre>
+{{if .Output}}
+<p>{{html ($.Label "Output")}}:</p>
+<pre>{{html .Output}}</pre>
+{{end}}
+{{end}}
+{{$type := .Name}}
+{{range $.Functions}}
+{{if and .IsMethod (eq .Receiver $type)}}
+<h4 id="{{$.FuncAnchor .}}">{{html .Name}}{{if .Since}} <span class="badge">since {{html .Since}}</span>{{end}}</h4>
+<pre><code>{{html ($.FormatSignature .)}}</code></pre>
+{{if and $.ShowSource .File}}
+{{$url := $.SourceURL .File .Line}}<p>{{html ($.Label "Source")}}: {{if $url}}<a href="{{html $url}}">{{html .File}}:{{.Line}}</a>{{else}}<code>{{html .File}}:{{.Line}}</code>{{end}}</p>
+{{end}}
+<p>{{html .Description}}</p>
+{{end}}
+{{end}}
+</section>
+{{end}}
+{{end}}
+{{end}}
+
+{{if or .TestHelpers .TestableExamples}}
+<h2>{{html ($.Label "Testing")}}</h2>
+{{range .TestHelpers}}
+<section>
+<h3 id="{{$.FuncAnchor .}}">{{html .Name}}</h3>
+<pre><code>{{html ($.FormatSignature .)}}</code></pre>
+{{if and $.ShowSource .File}}
+{{$url := $.SourceURL .File .Line}}<p>{{html ($.Label "Source")}}: {{if $url}}<a href="{{html $url}}">{{html .File}}:{{.Line}}</a>{{else}}<code>{{html .File}}:{{.Line}}</code>{{end}}</p>
+{{end}}
+<p>{{html .Description}}</p>
+</section>
+{{end}}
+{{with .TestableExamples}}
+<p>{{html ($.Label "Examples")}}:</p>
+<ul>
+{{range .}}
+<li><code>Example{{html .Name}}</code>{{if .Symbol}} - <a href="#{{$.Anchor .Symbol}}">{{html .Symbol}}</a>{{end}}</li>
+{{end}}
+</ul>
+{{end}}
+{{end}}
+{{end}}
+
+{{if .Imports}}
+{{$imports := .ImportGroups}}
+<h2>{{html ($.Label "Imports")}}</h2>
+{{with $imports.Standard}}
+<p>{{html ($.Label "Standard library")}}:</p>
+<ul>
+{{range .}}
+<li><code>{{html .}}</code></li>
+{{end}}
+</ul>
+{{end}}
+{{with $imports.External}}
+<p>{{html ($.Label "Third party")}}:</p>
+<ul>
+{{range .}}
+<li><a href="https://pkg.go.dev/{{html .}}"><code>{{html .}}</code></a></li>
+{{end}}
+</ul>
+{{end}}
+{{with $imports.Module}}
+<p>{{html ($.Label "This module")}}:</p>
+<ul>
+{{range .}}
+<li><code>{{html .}}</code></li>
+{{end}}
+</ul>
+{{end}}
+{{end}}
+</main>
+</div>
+<script>
+document.getElementById("toggle").addEventListener("click", function () {
+  document.getElementById("sidebar").classList.toggle("collapsed");
+});
+</script>
+</body>
+</html>
+`
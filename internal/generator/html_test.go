@@ -0,0 +1,50 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLSidebar(t *testing.T) {
+	config := offlineConfig("html")
+	config.Packages = []string{"p", "other"}
+	doc := render(t, `package p
+
+// Open opens it.
+func Open() {}
+
+// File is a file.
+type File struct{}
+
+// Close closes f.
+func (f *File) Close() error { return nil }
+
+func helper() {}
+`, config)
+
+	start, end := strings.Index(doc, `<aside id="sidebar">`), strings.Index(doc, "</aside>")
+	if start < 0 || end < start {
+		t.Fatalf("no sidebar in output:\n%s", doc)
+	}
+	sidebar := doc[start:end]
+
+	for _, want := range []string{`href="#open"`, `href="#file"`, `href="#file-close"`, `href="other.html"`} {
+		if !strings.Contains(sidebar, want) {
+			t.Errorf("sidebar missing %s:\n%s", want, sidebar)
+		}
+	}
+	if strings.Contains(sidebar, "helper") {
+		t.Errorf("sidebar lists unexported helper:\n%s", sidebar)
+	}
+}
+
+func TestHTMLSidebarOutputExt(t *testing.T) {
+	config := offlineConfig("html")
+	config.Packages = []string{"p", "other"}
+	config.OutputExt = "htm"
+	doc := render(t, "package p\n\n// Open opens it.\nfunc Open() {}\n", config)
+
+	if !strings.Contains(doc, `href="other.htm"`) || strings.Contains(doc, `href="other.html"`) {
+		t.Errorf("sidebar ignores output_ext:\n%s", doc)
+	}
+}
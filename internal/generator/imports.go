@@ -0,0 +1,38 @@
+package generator
+
+import "strings"
+
+// importGroups holds a package's imports split by where they come from.
+type importGroups struct {
+	Standard []string // standard library packages
+	External []string // packages of other modules, linked to pkg.go.dev
+	Module   []string // packages of the documented package's own module
+}
+
+// ImportGroups returns the package's imports grouped into standard library,
+// third-party and intra-module packages.
+func (p packagePage) ImportGroups() importGroups {
+	return groupImports(p.Imports, p.Module)
+}
+
+func groupImports(imports []string, module string) importGroups {
+	var groups importGroups
+	for _, imp := range imports {
+		switch {
+		case module != "" && (imp == module || strings.HasPrefix(imp, module+"/")):
+			groups.Module = append(groups.Module, imp)
+		case isStandardImport(imp):
+			groups.Standard = append(groups.Standard, imp)
+		default:
+			groups.External = append(groups.External, imp)
+		}
+	}
+	return groups
+}
+
+// isStandardImport reports whether path names a standard library package,
+// whose first element, unlike a module path's domain, has no dot.
+func isStandardImport(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
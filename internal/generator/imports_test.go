@@ -0,0 +1,48 @@
+package generator
+
+import (
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGroupImports(t *testing.T) {
+	imports := []string{"fmt", "net/http", "github.com/spf13/cobra", "example.com/app/internal/store", "example.com/application"}
+	got := groupImports(imports, "example.com/app")
+	want := importGroups{
+		Standard: []string{"fmt", "net/http"},
+		External: []string{"github.com/spf13/cobra", "example.com/application"},
+		Module:   []string{"example.com/app/internal/store"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groupImports = %+v, want %+v", got, want)
+	}
+
+	// A module path without a dot isn't mistaken for the standard library
+	got = groupImports([]string{"strings", "tool/internal/x"}, "tool")
+	if want := (importGroups{Standard: []string{"strings"}, Module: []string{"tool/internal/x"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("groupImports with module tool = %+v, want %+v", got, want)
+	}
+}
+
+func TestRenderImports(t *testing.T) {
+	pkg := &analyser.PackageInfo{
+		Name:    "app",
+		Module:  "example.com/app",
+		Imports: []string{"fmt", "github.com/spf13/cobra", "example.com/app/internal/store"},
+	}
+	doc, err := newTestGenerator(t, nil).GeneratePackageDoc(pkg, offlineConfig("markdown"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"**Standard library:**\n\n- 'fmt'",
+		"**Third party:**\n\n- [github.com/spf13/cobra](https://pkg.go.dev/github.com/spf13/cobra)",
+		"**This module:**\n\n- 'example.com/app/internal/store'",
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("output missing %q:\n%s", want, doc)
+		}
+	}
+}
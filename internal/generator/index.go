@@ -0,0 +1,129 @@
+package generator
+
+import (
+	"fmt"
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"path/filepath"
+	"strings"
+)
+
+// IndexEntry is a single package listed in the generated index.
+type IndexEntry struct {
+	Name     string
+	Link     string // output file relative to the output directory, slash separated
+	Summary  string
+	Internal bool
+}
+
+type indexPage struct {
+	Title       string
+	Description string
+	Packages    []IndexEntry
+	// Section heads the package list, which is left out when the page is
+	// already titled Packages
+	Section bool
+}
+
+const indexTemplate = `# {{.Title}}
+{{if .Description}}
+{{escapeMarkdown .Description}}
+{{end}}
+{{if .Section}}
+## Packages
+{{end}}
+{{range .Packages}}- [{{escapeMarkdown .Name}}]({{.Link}}){{if .Internal}} _(internal)_{{end}}{{if .Summary}} - {{escapeMarkdown .Summary}}{{end}}
+{{end}}`
+
+const htmlIndexTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{html .Title}}</title>
+<style>
+body { margin: 0 auto; padding: 1rem 2rem; max-width: 960px; font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #1f2328; }
+a { color: #0969da; text-decoration: none; }
+a:hover { text-decoration: underline; }
+</style>
+</head>
+<body>
+<h1>{{html .Title}}</h1>
+{{if .Description}}
+<p>{{html .Description}}</p>
+{{end}}
+{{if .Section}}
+<h2>Packages</h2>
+{{end}}
+<ul>
+{{range .Packages}}<li><a href="{{html .Link}}">{{html .Name}}</a>{{if .Internal}} <em>(internal)</em>{{end}}{{if .Summary}} - {{html .Summary}}{{end}}</li>
+{{end}}</ul>
+</body>
+</html>
+`
+
+const asciidocIndexTemplate = `= {{.Title}}
+{{if .Description}}
+{{.Description}}
+{{end}}
+{{if .Section}}
+== Packages
+{{end}}
+{{range .Packages}}* link:{{.Link}}[{{.Name}}]{{if .Internal}} _(internal)_{{end}}{{if .Summary}} - {{.Summary}}{{end}}
+{{end}}`
+
+// IndexPath returns the file, relative to the output directory, that the
+// index is written to.
+func IndexPath(config DocConfig) string {
+	return "index" + config.ext()
+}
+
+// NewIndexEntry builds the index entry for pkg, whose documentation was
+// written to outputPath relative to the output directory.
+func NewIndexEntry(pkg *analyser.PackageInfo, outputPath string) IndexEntry {
+	summary := pkg.Summary
+	if summary == "" {
+		summary = strings.Join(strings.Fields(pkg.Description), " ")
+	}
+
+	name := pkg.Name
+	if pkg.IsCommand {
+		name = pkg.Command
+	}
+
+	return IndexEntry{
+		Name:     name,
+		Link:     filepath.ToSlash(outputPath),
+		Summary:  summary,
+		Internal: pkg.IsInternal,
+	}
+}
+
+// GenerateIndex renders an index page linking to every documented package,
+// in the configured style.
+func (dg *DocGenerator) GenerateIndex(entries []IndexEntry, config DocConfig) (string, error) {
+	title := config.ProjectName
+	if title == "" {
+		title = "Packages"
+	}
+
+	tmpl := dg.templates["index"]
+	switch config.Style {
+	case "html":
+		tmpl = dg.templates["html-index"]
+	case "asciidoc":
+		tmpl = dg.templates["asciidoc-index"]
+	}
+
+	var result strings.Builder
+	err := tmpl.Execute(&result, indexPage{
+		Title:       title,
+		Description: config.ProjectDesc,
+		Packages:    entries,
+		Section:     config.ProjectName != "",
+	})
+	if err != nil {
+		return "", fmt.Errorf("executing index template: %w", err)
+	}
+
+	return shiftHeadings(result.String(), config.Style, config.HeadingOffset), nil
+}
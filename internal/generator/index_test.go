@@ -0,0 +1,105 @@
+package generator
+
+import (
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"strings"
+	"testing"
+)
+
+func TestGenerateIndex(t *testing.T) {
+	dg := newTestGenerator(t, nil)
+	entries := []IndexEntry{
+		{Name: "store", Link: "store.md", Summary: "Package store stores things."},
+		{Name: "cache", Link: "internal/cache.md", Internal: true},
+	}
+
+	config := DefaultConfig()
+	index, err := dg.GenerateIndex(entries, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(index, "# Packages\n") || strings.Count(index, "Packages") != 1 {
+		t.Errorf("untitled index should be headed Packages once:\n%s", index)
+	}
+	for _, want := range []string{"- [store](store.md) - Package store stores things.", "- [cache](internal/cache.md) _(internal)_"} {
+		if !strings.Contains(index, want) {
+			t.Errorf("index missing %q:\n%s", want, index)
+		}
+	}
+
+	config.ProjectName = "Docura"
+	if index, err = dg.GenerateIndex(entries, config); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(index, "# Docura\n") || !strings.Contains(index, "## Packages") {
+		t.Errorf("titled index should list packages under their own heading:\n%s", index)
+	}
+}
+
+func TestIndexUsesPackageSummary(t *testing.T) {
+	pkg := analyseSource(t, `// Package store stores things. It is safe for concurrent use.
+//
+// Values are kept in memory until Flush writes them out, and reads always see
+// the latest write.
+package store
+`)
+	if pkg.Summary != "Package store stores things." {
+		t.Errorf("got summary %q", pkg.Summary)
+	}
+
+	entry := NewIndexEntry(pkg, "store.md")
+	if entry.Summary != pkg.Summary {
+		t.Errorf("index entry summary %q, want %q", entry.Summary, pkg.Summary)
+	}
+
+	doc := render(t, "// Package store stores things.\n//\n// Values are kept in memory.\npackage store\n\n// Put puts.\nfunc Put() {}\n", offlineConfig("markdown"))
+	for _, want := range []string{"_Package store stores things._", "Values are kept in memory."} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("package page missing %q:\n%s", want, doc)
+		}
+	}
+}
+
+func TestIndexEntryInternal(t *testing.T) {
+	entry := NewIndexEntry(&analyser.PackageInfo{Name: "cache", IsInternal: true}, "internal/cache.md")
+	if !entry.Internal || entry.Link != "internal/cache.md" {
+		t.Errorf("got %+v, want an internal entry linking to internal/cache.md", entry)
+	}
+}
+
+func TestGenerateIndexStyles(t *testing.T) {
+	dg := newTestGenerator(t, nil)
+	entries := []IndexEntry{{Name: "store", Link: "store.html", Summary: "Package store stores things."}}
+
+	tests := []struct {
+		style string
+		path  string
+		want  []string
+	}{
+		{"markdown", "index.md", []string{"# Packages\n", "- [store](store.html)"}},
+		{"godoc", "index.txt", []string{"# Packages\n", "- [store](store.html)"}},
+		{"html", "index.html", []string{"<h1>Packages</h1>", `<li><a href="store.html">store</a> - Package store stores things.</li>`}},
+		{"asciidoc", "index.adoc", []string{"= Packages\n", "* link:store.html[store] - Package store stores things."}},
+	}
+	for _, test := range tests {
+		config := offlineConfig(test.style)
+		if got := IndexPath(config); got != test.path {
+			t.Errorf("%s index path: got %s, want %s", test.style, got, test.path)
+		}
+		index, err := dg.GenerateIndex(entries, config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range test.want {
+			if !strings.Contains(index, want) {
+				t.Errorf("%s index missing %q:\n%s", test.style, want, index)
+			}
+		}
+	}
+
+	config := offlineConfig("html")
+	config.OutputExt = ".htm"
+	if got := IndexPath(config); got != "index.htm" {
+		t.Errorf("index path with output_ext: got %s, want index.htm", got)
+	}
+}
@@ -0,0 +1,67 @@
+package generator
+
+import (
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"go/format"
+	"slices"
+	"strings"
+)
+
+// inlineType is a small result type shown where a function returns it.
+type inlineType struct {
+	Name       string
+	Definition string // the type declaration, fields only
+}
+
+// InlineTypes returns the definitions of the package's struct types with at
+// most Inline fields that fn returns, for showing under fn. Only fn's own
+// results are expanded, not the types of their fields, and types fn
+// constructs are left out as they're documented in full anyway.
+func (p packagePage) InlineTypes(fn analyser.FunctionInfo) []inlineType {
+	if p.Inline <= 0 {
+		return nil
+	}
+
+	types := make(map[string]*analyser.TypeInfo)
+	for i := range p.Types {
+		types[p.Types[i].Name] = &p.Types[i]
+	}
+
+	var inlined []inlineType
+	seen := make(map[string]bool)
+	for _, ret := range fn.Returns {
+		for _, loc := range identifier.FindAllStringIndex(ret.Type, -1) {
+			typ, ok := types[ret.Type[loc[0]:loc[1]]]
+			qualified := loc[0] > 0 && ret.Type[loc[0]-1] == '.'
+			if !ok || qualified || seen[typ.Name] {
+				continue
+			}
+			seen[typ.Name] = true
+			if typ.Kind != "struct" || len(typ.Fields) == 0 || len(typ.Fields) > p.Inline || slices.Contains(typ.Constructors, fn.Name) {
+				continue
+			}
+			inlined = append(inlined, inlineType{Name: typ.Name, Definition: structDefinition(typ)})
+		}
+	}
+	return inlined
+}
+
+// structDefinition renders typ as a gofmt'd struct declaration without field
+// tags.
+func structDefinition(typ *analyser.TypeInfo) string {
+	var b strings.Builder
+	b.WriteString("type " + typ.Name + " struct {\n")
+	for _, field := range typ.Fields {
+		b.WriteString("\t")
+		if field.Name != "" {
+			b.WriteString(field.Name + " ")
+		}
+		b.WriteString(field.Type + "\n")
+	}
+	b.WriteString("}")
+
+	if formatted, err := format.Source([]byte(b.String())); err == nil {
+		return string(formatted)
+	}
+	return b.String()
+}
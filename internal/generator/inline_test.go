@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInlineResultTypes(t *testing.T) {
+	src := `package p
+
+// Point is a position on the plane.
+type Point struct {
+	X, Y int
+}
+
+// Stats summarises a run.
+type Stats struct {
+	Count, Errors, Retries, Skipped int
+}
+
+// Origin returns the origin.
+func Origin() Point { return Point{} }
+
+// Run runs and reports what happened.
+func Run() (*Stats, error) { return nil, nil }
+
+// NewPoint returns the point at x, y.
+func NewPoint(x, y int) Point { return Point{x, y} }
+`
+	inlined := "'''go\ntype Point struct {\n\tX int\n\tY int\n}\n'''"
+
+	config := offlineConfig("markdown")
+	if doc := render(t, src, config); strings.Contains(doc, inlined) {
+		t.Errorf("result type inlined without inline_result_types:\n%s", doc)
+	}
+
+	config.InlineResultTypes = 3
+	doc := render(t, src, config)
+	if !strings.Contains(section(doc, "Origin"), inlined) {
+		t.Errorf("Point not inlined under Origin:\n%s", doc)
+	}
+	if strings.Contains(section(doc, "NewPoint"), inlined) {
+		t.Errorf("Point inlined under its own constructor:\n%s", doc)
+	}
+	if strings.Contains(doc, "type Stats struct {") {
+		t.Errorf("Stats inlined though it has more fields than the limit:\n%s", doc)
+	}
+}
+
+// section returns the part of doc documenting the named symbol, from its
+// heading to the next one.
+func section(doc, name string) string {
+	_, rest, _ := strings.Cut(doc, "#### "+name+"\n")
+	body, _, _ := strings.Cut(rest, "#### ")
+	return body
+}
@@ -0,0 +1,136 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/brendan-sadlier/docura/internal/logging"
+	"github.com/tmc/langchaingo/llms"
+)
+
+const retryBaseDelay = time.Second
+
+// complete sends prompt to the model, applying the configured per-request
+// timeout and retrying failed requests with exponential backoff. If the
+// primary model still fails, each fallback model is tried in turn.
+func (dg *DocGenerator) complete(ctx context.Context, prompt string, config DocConfig) (string, error) {
+	content, err := dg.completeWithRetries(ctx, dg.llm, prompt, config)
+	if err == nil {
+		return content, nil
+	}
+
+	for _, name := range config.FallbackModels {
+		if ctx.Err() != nil {
+			break
+		}
+
+		model, modelErr := dg.fallbackModel(name)
+		if modelErr != nil {
+			logging.Warnf("Skipping fallback model: %v", modelErr)
+			continue
+		}
+
+		logging.Warnf("LLM request failed (%v), switching to fallback model %s", err, name)
+		content, err = dg.completeWithRetries(ctx, model, prompt, config)
+		if err == nil {
+			return content, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %w", ErrLLM, err)
+}
+
+func (dg *DocGenerator) completeWithRetries(ctx context.Context, model llms.Model, prompt string, config DocConfig) (string, error) {
+	var lastErr error
+	for attempt := 0; attempt <= config.LLMRetries; attempt++ {
+		if attempt > 0 {
+			delay := retryBaseDelay << (attempt - 1)
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		content, err := dg.completeOnce(ctx, model, prompt, config)
+		if err == nil {
+			return content, nil
+		}
+		logging.Debugf("LLM request failed (attempt %d of %d): %v", attempt+1, config.LLMRetries+1, err)
+		lastErr = err
+	}
+
+	return "", lastErr
+}
+
+func (dg *DocGenerator) completeOnce(ctx context.Context, model llms.Model, prompt string, config DocConfig) (string, error) {
+	if limiter := dg.rateLimiter(config.RequestsPerMinute); limiter != nil {
+		if err := limiter.wait(ctx); err != nil {
+			return "", err
+		}
+	}
+
+	if config.LLMTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(config.LLMTimeout)*time.Second)
+		defer cancel()
+	}
+
+	options := []llms.CallOption{llms.WithTemperature(config.Temperature)}
+	if config.Seed != 0 {
+		options = append(options, llms.WithSeed(config.Seed))
+	}
+	if config.Stream {
+		options = append(options, llms.WithStreamingFunc(dg.streamChunk))
+		defer dg.streamChunk(ctx, []byte("\n"))
+	}
+
+	response, err := model.GenerateContent(ctx, []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeHuman, prompt),
+	}, options...)
+	if err != nil {
+		return "", err
+	}
+
+	if len(response.Choices) == 0 {
+		return "", fmt.Errorf("empty response from model")
+	}
+
+	return response.Choices[0].Content, nil
+}
+
+// streamChunk writes a piece of streamed model output. Chunks from concurrent
+// requests are written whole but may interleave.
+func (dg *DocGenerator) streamChunk(ctx context.Context, chunk []byte) error {
+	dg.streamMu.Lock()
+	defer dg.streamMu.Unlock()
+
+	_, err := dg.streamOut.Write(chunk)
+	return err
+}
+
+// forEachConcurrently calls fn for every index below n using at most limit
+// goroutines. A limit below 2 runs fn sequentially, in order.
+func forEachConcurrently(n int, limit int, fn func(i int)) {
+	if limit < 2 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, limit)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,152 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// streamingModel streams chunks through the request's streaming function,
+// recording what had been written to out before it completes.
+type streamingModel struct {
+	chunks         []string
+	out            *bytes.Buffer
+	beforeComplete string
+}
+
+func (m *streamingModel) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	var opts llms.CallOptions
+	for _, option := range options {
+		option(&opts)
+	}
+	if opts.StreamingFunc != nil {
+		for _, chunk := range m.chunks {
+			if err := opts.StreamingFunc(ctx, []byte(chunk)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	m.beforeComplete = m.out.String()
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: strings.Join(m.chunks, "")}}}, nil
+}
+
+func (m *streamingModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+func TestStream(t *testing.T) {
+	var out bytes.Buffer
+	model := &streamingModel{chunks: []string{"Adds ", "two ", "numbers."}, out: &out}
+	dg := newTestGenerator(t, model)
+	dg.SetStreamOutput(&out)
+
+	config := DefaultConfig()
+	config.Stream = true
+	content, err := dg.complete(context.Background(), "describe Add", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content != "Adds two numbers." {
+		t.Errorf("got content %q, want the whole response", content)
+	}
+	if model.beforeComplete != "Adds two numbers." {
+		t.Errorf("streamed before completion: %q, want every chunk", model.beforeComplete)
+	}
+	if out.String() != "Adds two numbers.\n" {
+		t.Errorf("got stream output %q", out.String())
+	}
+
+	out.Reset()
+	config.Stream = false
+	if _, err := dg.complete(context.Background(), "describe Add", config); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() > 0 {
+		t.Errorf("streamed without Stream set: %q", out.String())
+	}
+}
+
+func TestFallbackModels(t *testing.T) {
+	primary := &fakeModel{respond: func(prompt string) (string, error) { return "", errors.New("rate limited") }}
+	broken := "unavailable"
+	fallback := &fakeModel{respond: func(prompt string) (string, error) { return "Frobnicates from the fallback.", nil }}
+
+	dg := newTestGenerator(t, primary)
+	var created []string
+	dg.SetModelFactory(func(name string) (llms.Model, error) {
+		created = append(created, name)
+		if name == broken {
+			return nil, errors.New("no such model")
+		}
+		return fallback, nil
+	})
+
+	config := DefaultConfig()
+	config.GenerateExamples = false
+	config.FallbackModels = []string{broken, "backup"}
+	doc, err := dg.GeneratePackageDoc(analyseSource(t, "package p\n\nfunc Frob() {}\n"), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(doc, "Frobnicates from the fallback.") {
+		t.Errorf("fallback model's description not used:\n%s", doc)
+	}
+	if len(primary.Prompts()) == 0 || len(fallback.Prompts()) != len(primary.Prompts()) {
+		t.Errorf("primary got %d prompts and fallback %d, want every failed prompt retried on the fallback", len(primary.Prompts()), len(fallback.Prompts()))
+	}
+	if n := len(slices.DeleteFunc(created, func(name string) bool { return name != "backup" })); n != 1 {
+		t.Errorf("created models %q, want the working fallback created once", created)
+	}
+
+	_, err = newTestGenerator(t, primary).complete(context.Background(), "describe", config)
+	if !errors.Is(err, ErrLLM) {
+		t.Errorf("without a model factory: got %v, want ErrLLM", err)
+	}
+}
+
+// optionsModel records the call options of each request.
+type optionsModel struct {
+	fakeModel
+	options []llms.CallOptions
+}
+
+func (m *optionsModel) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	var opts llms.CallOptions
+	for _, option := range options {
+		option(&opts)
+	}
+	m.mu.Lock()
+	m.options = append(m.options, opts)
+	m.mu.Unlock()
+	return m.fakeModel.GenerateContent(ctx, messages, options...)
+}
+
+func TestTemperatureAndSeed(t *testing.T) {
+	model := &optionsModel{}
+	dg := newTestGenerator(t, model)
+
+	config := DefaultConfig()
+	if _, err := dg.complete(context.Background(), "describe", config); err != nil {
+		t.Fatal(err)
+	}
+	config.Temperature = 0.7
+	config.Seed = 42
+	if _, err := dg.complete(context.Background(), "describe", config); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(model.options) != 2 {
+		t.Fatalf("got %d requests, want 2", len(model.options))
+	}
+	if got := model.options[0]; got.Temperature != DefaultConfig().Temperature || got.Temperature > 0.3 || got.Seed != 0 {
+		t.Errorf("default options: temperature %v seed %d, want a low temperature and no seed", got.Temperature, got.Seed)
+	}
+	if got := model.options[1]; got.Temperature != 0.7 || got.Seed != 42 {
+		t.Errorf("got temperature %v seed %d, want 0.7 and 42", got.Temperature, got.Seed)
+	}
+}
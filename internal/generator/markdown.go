@@ -0,0 +1,522 @@
+package generator
+
+const packageTemplate = `# {{if .IsCommand}}{{escapeMarkdown .Command}}{{else}}{{escapeMarkdown .Name}}{{end}}
+{{if .Summary}}
+_{{escapeMarkdown .Summary}}_
+{{end}}
+{{if .Overview}}{{escapeMarkdown .Overview}}{{else}}{{escapeMarkdown .Description}}{{end}}
+{{with .Readme}}
+
+{{.}}
+{{end}}
+
+{{if .IsCommand}}
+## {{$.Label "Command"}}
+
+'''{{$.Fence "shell"}}
+{{.Command}} [flags]
+'''
+
+{{if .Flags}}
+### {{$.Label "Flags"}}
+
+| Flag | Type | Default | Description |
+|------|------|---------|-------------|
+{{range .Flags}}| '--{{.Name}}'{{if .Shorthand}}, '-{{.Shorthand}}'{{end}} | {{.Type}} | '{{.Default}}' | {{escapePipes (escapeMarkdown .Usage)}} |
+{{end}}
+{{end}}
+{{else}}
+{{if or .IsInternal .ImportPath}}
+## {{$.Label "Installation"}}
+
+{{if .IsInternal}}
+> **Internal:** this package can only be imported from within its own module.
+{{else}}
+'''{{$.Fence "shell"}}
+go get {{.ImportPath}}
+'''
+{{end}}
+{{end}}
+
+## {{$.Label "Usage"}}
+
+{{range $.PackageExamples}}
+'''{{$.Fence "code"}}
+{{.Code}}
+'''
+{{if .Output}}
+{{$.Label "Output"}}:
+
+'''{{$.Fence "output"}}
+{{.Output}}
+'''
+{{end}}
+{{end}}
+
+## {{$.Label "API Reference"}}
+
+{{if not .HasAPI}}
+_{{$.Label "No exported API."}}_
+{{end}}
+
+{{if .Routes}}
+### {{$.Label "Routes"}}
+
+| Method | Path | Handler |
+|--------|------|---------|
+{{range .Routes}}| {{or .Method "-"}} | {{if .Path}}'{{.Path}}'{{else}}-{{end}} | '{{.Handler}}' |
+{{end}}
+{{end}}
+
+{{with .ExportedConstants}}
+### {{$.Label "Constants"}}
+
+{{range .}}
+- '{{escapeMarkdown .Name}}'{{if .Type}} ({{$.LinkTypes .Type}}){{end}}{{if .Value}} = '{{.Value}}'{{end}}{{if .Description}} - {{escapeMarkdown .Description}}{{end}}
+{{end}}
+{{end}}
+
+{{with .ExportedVariables}}
+### {{$.Label "Variables"}}
+
+{{range .}}
+- '{{escapeMarkdown .Name}}'{{if .Type}} ({{$.LinkTypes .Type}}){{end}}{{if .Value}} = '{{.Value}}'{{end}}{{if .Description}} - {{escapeMarkdown .Description}}{{end}}
+{{end}}
+{{end}}
+
+{{with .Errors}}
+### {{$.Label "Errors"}}
+
+{{range .}}
+- '{{escapeMarkdown .Name}}'{{if .Description}} - {{escapeMarkdown .Description}}{{end}}
+{{end}}
+{{end}}
+
+{{if .Functions}}
+### {{$.Label "Functions"}}
+
+{{range .Functions}}
+{{if and .IsExported (not (and $.SplitTypes .IsMethod)) ($.InAPI .)}}
+<a id="{{$.FuncAnchor .}}"></a>
+#### {{if .IsMethod}}{{escapeMarkdown .Receiver}}.{{end}}{{escapeMarkdown .Name}}
+{{if .Since}}
+` + "`since {{.Since}}`" + `
+{{end}}
+'''{{$.Fence "code"}}
+{{$.FormatSignature .}}
+'''
+{{if and $.ShowSource .File}}
+{{$url := $.SourceURL .File .Line}}{{$.Label "Source"}}: {{if $url}}[{{escapeMarkdown .File}}:{{.Line}}]({{$url}}){{else}}'{{.File}}:{{.Line}}'{{end}}
+{{end}}
+
+{{escapeMarkdown .Description}}
+
+{{if .Parameters}}
+**{{$.Label "Parameters"}}:**
+{{range .Parameters}}
+- '{{escapeMarkdown .Name}}' ({{$.LinkTypes .Type}})
+{{end}}
+{{end}}
+
+{{if .Returns}}
+**{{$.Label "Returns"}}:**
+{{range .Returns}}
+- {{if .Name}}'{{escapeMarkdown .Name}}' {{end}}{{$.LinkTypes .Type}}{{if .Description}} - {{escapeMarkdown .Description}}{{end}}
+{{end}}
+{{end}}
+{{range $.InlineTypes .}}
+'''{{$.Fence "code"}}
+{{.Definition}}
+'''
+{{end}}
+
+{{if .TypeParams}}
+**{{$.Label "Constraints"}}:**
+{{range .TypeParams}}
+- '{{escapeMarkdown .Name}}' {{escapeMarkdown .Constraint}}
+{{end}}
+{{end}}
+
+{{if or .Examples ($.FunctionExamples .)}}
+{{$numbered := gt (len .Examples) 1}}
+**{{if $numbered}}{{$.Label "Examples"}}{{else}}{{$.Label "Example"}}{{end}}:**
+{{range $i, $code := .Examples}}
+{{if $numbered}}_{{$.ExampleNumber $i}}_
+
+{{end}}'''{{$.Fence "code"}}
+{{$code}}
+'''
+{{end}}
+{{range $.FunctionExamples .}}
+'''{{$.Fence "code"}}
+{{.Code}}
+'''
+{{if .Output}}
+{{$.Label "Output"}}:
+
+'''{{$.Fence "output"}}
+{{.Output}}
+'''
+{{end}}
+{{end}}
+{{end}}
+
+{{end}}
+{{end}}
+{{end}}
+
+{{if .Types}}
+### {{$.Label "Types"}}
+
+{{range .Types}}
+{{if and .IsExported $.SplitTypes}}
+- [{{escapeMarkdown .Name}}]({{$.Name}}/{{.Name}}{{$.Ext}})
+{{else if .IsExported}}
+<a id="{{$.Anchor .Name}}"></a>
+#### {{escapeMarkdown .Name}}
+{{if .Since}}
+` + "`since {{.Since}}`" + `
+{{end}}
+'''{{$.Fence "code"}}
+type {{.Name}}{{typeParams .TypeParams}}{{if .IsAlias}} = {{.Underlying}}{{else if .Underlying}} {{.Underlying}}{{else}} {{.Kind}}{{end}}
+'''
+{{if and $.ShowSource .File}}
+{{$url := $.SourceURL .File .Line}}{{$.Label "Source"}}: {{if $url}}[{{escapeMarkdown .File}}:{{.Line}}]({{$url}}){{else}}'{{.File}}:{{.Line}}'{{end}}
+{{end}}
+
+{{escapeMarkdown .Description}}
+
+{{if and .IsAlias ($.RefersToLocal .Underlying)}}
+**{{$.Label "Alias of"}}:** {{$.LinkTypes .Underlying}}
+{{end}}
+
+{{with .Implements}}
+**{{$.Label "Implements"}}:** {{range $i, $name := .}}{{if $i}}, {{end}}{{$.LinkTypes $name}}{{end}}
+{{end}}
+
+{{with .Embeds}}
+**{{$.Label "Embeds"}}:** {{range $i, $name := .}}{{if $i}}, {{end}}{{$.LinkTypes $name}}{{end}}
+{{end}}
+
+{{with .MethodSet}}
+**{{$.Label "Methods"}}:**
+{{range .}}
+- '{{escapeMarkdown .}}'
+{{end}}
+{{end}}
+
+{{if .Constructors}}
+**{{$.Label "Constructors"}}:**
+{{range .Constructors}}
+- [{{escapeMarkdown .}}](#{{$.Anchor .}})
+{{end}}
+{{end}}
+
+{{if .Options}}
+**{{$.Label "Options"}}:**
+{{range .Options}}
+- [{{escapeMarkdown .}}](#{{$.Anchor .}})
+{{end}}
+{{end}}
+
+{{if .Fields}}
+**{{$.Label "Fields"}}:**
+{{if eq $.FieldStyle "table"}}
+{{$defaults := $.HasDefaults .Fields}}
+| Name | Type | Tag |{{if $defaults}} Default |{{end}} Description |
+|------|------|-----|{{if $defaults}}---------|{{end}}-------------|
+{{range .Fields}}| {{if .Name}}'{{escapeMarkdown .Name}}'{{end}} | {{escapePipes ($.LinkTypes .Type)}}{{if $.Redacted .}} ({{$.Label "unexported"}}){{end}} | {{if not ($.Redacted .)}}{{escapePipes .Tag}}{{end}} |{{if $defaults}} {{with .Default}}'{{escapePipes .}}'{{end}} |{{end}} {{escapePipes (escapeMarkdown .Description)}} |
+{{end}}
+{{else}}
+{{range .Fields}}
+- '{{escapeMarkdown .Name}}' {{$.LinkTypes .Type}}{{if $.Redacted .}} ({{$.Label "unexported"}}){{end}}{{with .Default}} ({{$.Label "default"}} '{{.}}'){{end}}{{if .Description}} - {{escapeMarkdown .Description}}{{end}}
+{{end}}
+{{end}}
+{{end}}
+
+{{if or .TypeParams .TypeSet}}
+**{{$.Label "Constraints"}}:**
+{{range .TypeParams}}
+- '{{escapeMarkdown .Name}}' {{escapeMarkdown .Constraint}}
+{{end}}
+{{range .TypeSet}}
+- '{{.}}'
+{{end}}
+{{end}}
+
+{{if or .Examples ($.ExamplesFor .Name)}}
+**{{$.Label "Example"}}:**
+{{range .Examples}}
+'''{{$.Fence "code"}}
+{{.}}
+'''
+{{end}}
+{{range $.ExamplesFor .Name}}
+'''{{$.Fence "code"}}
+{{.Code}}
+'''
+{{if .Output}}
+{{$.Label "Output"}}:
+
+'''{{$.Fence "output"}}
+{{.Output}}
+'''
+{{end}}
+{{end}}
+{{end}}
+
+{{if .Methods}}
+**{{$.Label "Methods"}}:**
+{{$type := .Name}}
+{{range .Methods}}
+- [{{escapeMarkdown .}}](#{{$.Anchor (printf "%s.%s" $type .)}})
+{{end}}
+{{end}}
+
+{{end}}
+{{end}}
+{{end}}
+
+{{if or .TestHelpers .TestableExamples}}
+### {{$.Label "Testing"}}
+
+{{range .TestHelpers}}
+<a id="{{$.FuncAnchor .}}"></a>
+#### {{escapeMarkdown .Name}}
+
+'''{{$.Fence "code"}}
+{{$.FormatSignature .}}
+'''
+{{if and $.ShowSource .File}}
+{{$url := $.SourceURL .File .Line}}{{$.Label "Source"}}: {{if $url}}[{{escapeMarkdown .File}}:{{.Line}}]({{$url}}){{else}}'{{.File}}:{{.Line}}'{{end}}
+{{end}}
+
+{{escapeMarkdown .Description}}
+
+{{end}}
+
+{{with .TestableExamples}}
+**{{$.Label "Examples"}}:**
+{{range .}}
+- 'Example{{.Name}}'{{if .Symbol}} - [{{escapeMarkdown .Symbol}}](#{{$.Anchor .Symbol}}){{end}}
+{{end}}
+{{end}}
+{{end}}
+{{end}}
+
+{{if .Imports}}
+{{$imports := .ImportGroups}}
+## {{$.Label "Imports"}}
+{{with $imports.Standard}}
+**{{$.Label "Standard library"}}:**
+{{range .}}
+- '{{.}}'
+{{end}}
+{{end}}
+{{with $imports.External}}
+**{{$.Label "Third party"}}:**
+{{range .}}
+- [{{.}}](https://pkg.go.dev/{{.}})
+{{end}}
+{{end}}
+{{with $imports.Module}}
+**{{$.Label "This module"}}:**
+{{range .}}
+- '{{.}}'
+{{end}}
+{{end}}
+{{end}}
+`
+
+const typeTemplate = `# {{escapeMarkdown .Name}}
+{{if .Since}}
+` + "`since {{.Since}}`" + `
+{{end}}
+Package [{{.Package.Name}}](../{{.Package.Name}}{{.Ext}})
+
+'''{{$.Fence "code"}}
+type {{.Name}}{{typeParams .TypeParams}}{{if .IsAlias}} = {{.Underlying}}{{else if .Underlying}} {{.Underlying}}{{else}} {{.Kind}}{{end}}
+'''
+{{if and $.ShowSource .File}}
+{{$url := $.SourceURL .File .Line}}{{$.Label "Source"}}: {{if $url}}[{{escapeMarkdown .File}}:{{.Line}}]({{$url}}){{else}}'{{.File}}:{{.Line}}'{{end}}
+{{end}}
+
+{{escapeMarkdown .Description}}
+
+{{with .Implements}}
+**{{$.Label "Implements"}}:** {{range $i, $name := .}}{{if $i}}, {{end}}[{{$name}}]({{$name}}{{$.Ext}}){{end}}
+{{end}}
+
+{{with .Embeds}}
+**{{$.Label "Embeds"}}:** {{range $i, $name := .}}{{if $i}}, {{end}}'{{escapeMarkdown $name}}'{{end}}
+{{end}}
+
+{{with .MethodSet}}
+## {{$.Label "Methods"}}
+{{range .}}
+- '{{escapeMarkdown .}}'
+{{end}}
+{{end}}
+
+{{if .Constructors}}
+## {{$.Label "Constructors"}}
+{{range .Constructors}}
+- [{{escapeMarkdown .}}](../{{$.Package.Name}}{{$.Ext}}#{{$.Anchor .}})
+{{end}}
+{{end}}
+
+{{if .Options}}
+## {{$.Label "Options"}}
+{{range .Options}}
+- [{{escapeMarkdown .}}](../{{$.Package.Name}}{{$.Ext}}#{{$.Anchor .}})
+{{end}}
+{{end}}
+
+{{if .Fields}}
+## {{$.Label "Fields"}}
+{{if eq $.FieldStyle "table"}}
+{{$defaults := $.HasDefaults .Fields}}
+| Name | Type | Tag |{{if $defaults}} Default |{{end}} Description |
+|------|------|-----|{{if $defaults}}---------|{{end}}-------------|
+{{range .Fields}}| {{if .Name}}'{{escapeMarkdown .Name}}'{{end}} | {{escapePipes (escapeMarkdown .Type)}}{{if $.Redacted .}} ({{$.Label "unexported"}}){{end}} | {{if not ($.Redacted .)}}{{escapePipes .Tag}}{{end}} |{{if $defaults}} {{with .Default}}'{{escapePipes .}}'{{end}} |{{end}} {{escapePipes (escapeMarkdown .Description)}} |
+{{end}}
+{{else}}
+{{range .Fields}}
+- '{{escapeMarkdown .Name}}' {{escapeMarkdown .Type}}{{if $.Redacted .}} ({{$.Label "unexported"}}){{end}}{{with .Default}} ({{$.Label "default"}} '{{.}}'){{end}}{{if .Description}} - {{escapeMarkdown .Description}}{{end}}
+{{end}}
+{{end}}
+{{end}}
+
+{{if or .TypeParams .TypeSet}}
+## {{$.Label "Constraints"}}
+{{range .TypeParams}}
+- '{{escapeMarkdown .Name}}' {{escapeMarkdown .Constraint}}
+{{end}}
+{{range .TypeSet}}
+- '{{.}}'
+{{end}}
+{{end}}
+
+{{if or .Examples ($.ExamplesFor .Name)}}
+## {{$.Label "Examples"}}
+{{range .Examples}}
+'''{{$.Fence "code"}}
+{{.}}
+'''
+{{end}}
+{{range $.ExamplesFor .Name}}
+'''{{$.Fence "code"}}
+{{.Code}}
+'''
+{{if .Output}}
+{{$.Label "Output"}}:
+
+'''{{$.Fence "output"}}
+{{.Output}}
+'''
+{{end}}
+{{end}}
+{{end}}
+
+{{if .MethodDocs}}
+## {{$.Label "Methods"}}
+
+{{range .MethodDocs}}
+### {{escapeMarkdown .Name}}
+{{if .Since}}
+` + "`since {{.Since}}`" + `
+{{end}}
+'''{{$.Fence "code"}}
+{{$.FormatSignature .}}
+'''
+{{if and $.ShowSource .File}}
+{{$url := $.SourceURL .File .Line}}{{$.Label "Source"}}: {{if $url}}[{{escapeMarkdown .File}}:{{.Line}}]({{$url}}){{else}}'{{.File}}:{{.Line}}'{{end}}
+{{end}}
+
+{{escapeMarkdown .Description}}
+
+{{if or .Examples ($.FunctionExamples .)}}
+{{$numbered := gt (len .Examples) 1}}
+**{{if $numbered}}{{$.Label "Examples"}}{{else}}{{$.Label "Example"}}{{end}}:**
+{{range $i, $code := .Examples}}
+{{if $numbered}}_{{$.ExampleNumber $i}}_
+
+{{end}}'''{{$.Fence "code"}}
+{{$code}}
+'''
+{{end}}
+{{range $.FunctionExamples .}}
+'''{{$.Fence "code"}}
+{{.Code}}
+'''
+{{if .Output}}
+{{$.Label "Output"}}:
+
+'''{{$.Fence "output"}}
+{{.Output}}
+'''
+{{end}}
+{{end}}
+{{end}}
+
+{{end}}
+{{end}}
+`
+
+const internalTemplate = `# {{escapeMarkdown .Name}} ({{$.Label "internal"}})
+
+{{$.Label "Unexported functions, methods and types, for the package's maintainers."}}
+
+{{if .Functions}}
+## {{$.Label "Functions"}}
+
+{{range .Functions}}
+<a id="{{$.FuncAnchor .}}"></a>
+### {{if .IsMethod}}{{escapeMarkdown .Receiver}}.{{end}}{{escapeMarkdown .Name}}
+
+'''{{$.Fence "code"}}
+{{$.FormatSignature .}}
+'''
+{{if and $.ShowSource .File}}
+{{$url := $.SourceURL .File .Line}}{{$.Label "Source"}}: {{if $url}}[{{escapeMarkdown .File}}:{{.Line}}]({{$url}}){{else}}'{{.File}}:{{.Line}}'{{end}}
+{{end}}
+
+{{escapeMarkdown .Description}}
+
+{{end}}
+{{end}}
+
+{{if .Types}}
+## {{$.Label "Types"}}
+
+{{range .Types}}
+<a id="{{$.Anchor .Name}}"></a>
+### {{escapeMarkdown .Name}}
+
+'''{{$.Fence "code"}}
+type {{.Name}}{{typeParams .TypeParams}}{{if .IsAlias}} = {{.Underlying}}{{else if .Underlying}} {{.Underlying}}{{else}} {{.Kind}}{{end}}
+'''
+{{if and $.ShowSource .File}}
+{{$url := $.SourceURL .File .Line}}{{$.Label "Source"}}: {{if $url}}[{{escapeMarkdown .File}}:{{.Line}}]({{$url}}){{else}}'{{.File}}:{{.Line}}'{{end}}
+{{end}}
+
+{{escapeMarkdown .Description}}
+
+{{if .Fields}}
+**{{$.Label "Fields"}}:**
+{{range .Fields}}
+- '{{escapeMarkdown .Name}}' {{escapeMarkdown .Type}}{{with .Default}} ({{$.Label "default"}} '{{.}}'){{end}}{{if .Description}} - {{escapeMarkdown .Description}}{{end}}
+{{end}}
+{{end}}
+
+{{if .Methods}}
+**{{$.Label "Methods"}}:**
+{{$type := .Name}}
+{{range .Methods}}
+- [{{escapeMarkdown .}}](#{{$.Anchor (printf "%s.%s" $type .)}})
+{{end}}
+{{end}}
+
+{{end}}
+{{end}}
+`
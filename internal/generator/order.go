@@ -0,0 +1,86 @@
+package generator
+
+import (
+	"cmp"
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"slices"
+)
+
+// Orders for DocConfig.SymbolOrder.
+const (
+	OrderAlphabetical  = "alphabetical"
+	OrderSource        = "source"
+	OrderExportedFirst = "exported-first"
+)
+
+// orderSymbols sorts the package's top-level functions, and each type's
+// methods, by order. Functions come before methods, which stay grouped by
+// their receiver in the order the types are listed.
+func orderSymbols(pkg *analyser.PackageInfo, order string) {
+	compare := symbolComparer(order)
+
+	var funcs []analyser.FunctionInfo
+	methods := make(map[string][]analyser.FunctionInfo)
+	for _, fn := range pkg.Functions {
+		if fn.IsMethod {
+			methods[fn.Receiver] = append(methods[fn.Receiver], fn)
+		} else {
+			funcs = append(funcs, fn)
+		}
+	}
+	slices.SortStableFunc(funcs, compare)
+
+	sorted := funcs
+	for i := range pkg.Types {
+		typ := &pkg.Types[i]
+		typMethods := methods[typ.Name]
+		delete(methods, typ.Name)
+		slices.SortStableFunc(typMethods, compare)
+		sorted = append(sorted, typMethods...)
+
+		// Names without a FunctionInfo, if any, keep their place at the end
+		rank := make(map[string]int, len(typMethods))
+		for j, fn := range typMethods {
+			rank[fn.Name] = j + 1
+		}
+		typ.Methods = slices.Clone(typ.Methods)
+		slices.SortStableFunc(typ.Methods, func(a, b string) int {
+			return cmp.Compare(cmp.Or(rank[a], len(rank)+1), cmp.Or(rank[b], len(rank)+1))
+		})
+	}
+
+	// Methods of types that aren't listed keep their place at the end
+	for _, fn := range pkg.Functions {
+		if rest, ok := methods[fn.Receiver]; ok && fn.IsMethod {
+			sorted = append(sorted, rest...)
+			delete(methods, fn.Receiver)
+		}
+	}
+
+	pkg.Functions = sorted
+}
+
+func symbolComparer(order string) func(a, b analyser.FunctionInfo) int {
+	byName := func(a, b analyser.FunctionInfo) int {
+		return cmp.Compare(a.Name, b.Name)
+	}
+
+	switch order {
+	case OrderAlphabetical:
+		return byName
+	case OrderSource:
+		return func(a, b analyser.FunctionInfo) int {
+			return cmp.Or(cmp.Compare(a.File, b.File), cmp.Compare(a.Line, b.Line), byName(a, b))
+		}
+	default:
+		return func(a, b analyser.FunctionInfo) int {
+			if a.IsExported != b.IsExported {
+				if a.IsExported {
+					return -1
+				}
+				return 1
+			}
+			return byName(a, b)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package generator
+
+import (
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"slices"
+	"testing"
+)
+
+func TestOrderSymbols(t *testing.T) {
+	newPackage := func() *analyser.PackageInfo {
+		return &analyser.PackageInfo{
+			Functions: []analyser.FunctionInfo{
+				{Name: "Reset", IsMethod: true, Receiver: "Store", IsExported: true, File: "store.go", Line: 30},
+				{Name: "open", IsExported: false, File: "a.go", Line: 5},
+				{Name: "New", IsExported: true, File: "store.go", Line: 10},
+				{Name: "grow", IsMethod: true, Receiver: "Store", IsExported: false, File: "store.go", Line: 20},
+				{Name: "Get", IsMethod: true, Receiver: "Store", IsExported: true, File: "store.go", Line: 40},
+				{Name: "Close", IsExported: true, File: "store.go", Line: 50},
+			},
+			Types: []analyser.TypeInfo{{Name: "Store", Methods: []string{"Reset", "grow", "Get"}}},
+		}
+	}
+
+	tests := []struct {
+		order   string
+		funcs   []string
+		methods []string
+	}{
+		{OrderAlphabetical, []string{"Close", "New", "open", "Get", "Reset", "grow"}, []string{"Get", "Reset", "grow"}},
+		{OrderSource, []string{"open", "New", "Close", "grow", "Reset", "Get"}, []string{"grow", "Reset", "Get"}},
+		{OrderExportedFirst, []string{"Close", "New", "open", "Get", "Reset", "grow"}, []string{"Get", "Reset", "grow"}},
+		{"", []string{"Close", "New", "open", "Get", "Reset", "grow"}, []string{"Get", "Reset", "grow"}},
+	}
+	for _, test := range tests {
+		pkg := newPackage()
+		orderSymbols(pkg, test.order)
+
+		var funcs []string
+		for _, fn := range pkg.Functions {
+			funcs = append(funcs, fn.Name)
+		}
+		if !slices.Equal(funcs, test.funcs) {
+			t.Errorf("%q: functions in order %v, want %v", test.order, funcs, test.funcs)
+		}
+		if methods := pkg.Types[0].Methods; !slices.Equal(methods, test.methods) {
+			t.Errorf("%q: methods in order %v, want %v", test.order, methods, test.methods)
+		}
+	}
+}
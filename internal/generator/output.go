@@ -0,0 +1,85 @@
+package generator
+
+import (
+	"fmt"
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+const (
+	defaultOutputNameTemplate = "{{.Name}}{{.Ext}}"
+	mirrorOutputNameTemplate  = "{{.Dir}}/{{.Name}}{{.Ext}}"
+)
+
+// OutputName is the data available to DocConfig.OutputNameTemplate.
+type OutputName struct {
+	Name       string // package name
+	ImportPath string // package import path, resolved from go.mod, or its name outside a module
+	Dir        string // package directory relative to the project root, slash separated
+	Ext        string // file extension for the configured style, including the dot
+}
+
+// OutputPath returns the file, relative to the output directory, that the
+// documentation for pkg should be written to. relDir is the package's
+// directory relative to the project root.
+func OutputPath(config DocConfig, pkg *analyser.PackageInfo, relDir string) (string, error) {
+	text := config.OutputNameTemplate
+	if text == "" {
+		text = defaultOutputNameTemplate
+		if config.Mirror {
+			text = mirrorOutputNameTemplate
+		}
+	}
+
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parsing output name template: %w", err)
+	}
+
+	importPath := pkg.ImportPath
+	if importPath == "" {
+		importPath = pkg.Name
+	}
+
+	var name strings.Builder
+	err = tmpl.Execute(&name, OutputName{
+		Name:       pkg.Name,
+		ImportPath: importPath,
+		Dir:        filepath.ToSlash(relDir),
+		Ext:        config.ext(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("executing output name template: %w", err)
+	}
+
+	path := filepath.Clean(filepath.FromSlash(name.String()))
+	if !filepath.IsLocal(path) {
+		return "", fmt.Errorf("output name %q escapes the output directory", name.String())
+	}
+
+	return path, nil
+}
+
+// ext returns the extension documentation files are written with: OutputExt
+// when set, otherwise the one matching the style.
+func (c DocConfig) ext() string {
+	if c.OutputExt != "" {
+		return "." + strings.TrimPrefix(c.OutputExt, ".")
+	}
+	return outputExt(c.Style)
+}
+
+func outputExt(style string) string {
+	switch style {
+	case "html":
+		return ".html"
+	case "asciidoc":
+		return ".adoc"
+	case "godoc":
+		return ".txt"
+	default:
+		return ".md"
+	}
+}
@@ -0,0 +1,45 @@
+package generator
+
+import (
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"path/filepath"
+	"testing"
+)
+
+func TestOutputPathTemplate(t *testing.T) {
+	config := DefaultConfig()
+	config.OutputNameTemplate = "{{.ImportPath}}{{.Ext}}"
+
+	v1 := &analyser.PackageInfo{Name: "client", ImportPath: "example.com/api/client"}
+	v2 := &analyser.PackageInfo{Name: "client", ImportPath: "example.com/api/v2/client"}
+
+	first, err := OutputPath(config, v1, "client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := OutputPath(config, v2, "v2/client")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := filepath.FromSlash("example.com/api/client.md"); first != want {
+		t.Errorf("got %s, want %s", first, want)
+	}
+	if want := filepath.FromSlash("example.com/api/v2/client.md"); second != want {
+		t.Errorf("got %s, want %s", second, want)
+	}
+
+	// Outside a module the package name stands in for the import path
+	loose, err := OutputPath(config, &analyser.PackageInfo{Name: "scratch"}, "scratch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loose != "scratch.md" {
+		t.Errorf("got %s for a package outside a module, want scratch.md", loose)
+	}
+
+	config.OutputNameTemplate = "../{{.Name}}{{.Ext}}"
+	if _, err := OutputPath(config, v1, "client"); err == nil {
+		t.Error("output name escaping the output directory was accepted")
+	}
+}
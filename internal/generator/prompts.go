@@ -0,0 +1,72 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// requiredPlaceholders lists, for each prompt that can be overridden through
+// DocConfig.PromptTemplates, the variables a custom template must use.
+var requiredPlaceholders = map[string][]string{
+	"package":  {"name"},
+	"function": {"name", "signature"},
+	"type":     {"name"},
+	"example":  {"name", "signature"},
+}
+
+// resolvePromptTemplates reads any prompt templates given as file paths and
+// checks that every template uses its required placeholders. Values
+// containing "{{" are taken as inline templates.
+func resolvePromptTemplates(templates map[string]string) (map[string]string, error) {
+	if len(templates) == 0 {
+		return nil, nil
+	}
+
+	resolved := make(map[string]string, len(templates))
+	for kind, value := range templates {
+		required, ok := requiredPlaceholders[kind]
+		if !ok {
+			return nil, fmt.Errorf("unknown prompt template %q, expected one of %s", kind, strings.Join(promptKinds(), ", "))
+		}
+
+		text := value
+		if !strings.Contains(value, "{{") {
+			data, err := os.ReadFile(value)
+			if err != nil {
+				return nil, fmt.Errorf("reading %s prompt template: %w", kind, err)
+			}
+			text = string(data)
+		}
+
+		for _, name := range required {
+			placeholder := regexp.MustCompile(`\{\{[^}]*\.` + name + `\b`)
+			if !placeholder.MatchString(text) {
+				return nil, fmt.Errorf("%s prompt template must use {{.%s}}", kind, name)
+			}
+		}
+		resolved[kind] = text
+	}
+
+	return resolved, nil
+}
+
+func promptKinds() []string {
+	var kinds []string
+	for kind := range requiredPlaceholders {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// promptTemplate returns the user's template for kind, or fallback when none
+// was configured.
+func (c DocConfig) promptTemplate(kind string, fallback string) string {
+	if text, ok := c.PromptTemplates[kind]; ok {
+		return text
+	}
+	return fallback
+}
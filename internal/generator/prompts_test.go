@@ -0,0 +1,52 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCustomPromptTemplate(t *testing.T) {
+	model := &fakeModel{}
+	config := DefaultConfig()
+	config.GenerateExamples = false
+	config.PromptTemplates = map[string]string{
+		"function": "Describe {{.name}} like a pirate. Signature: {{.signature}}",
+	}
+
+	if _, err := newTestGenerator(t, model).GeneratePackageDoc(analyseSource(t, "package p\n\nfunc Frob(n int) {}\n"), config); err != nil {
+		t.Fatal(err)
+	}
+	var used bool
+	for _, prompt := range model.Prompts() {
+		if strings.Contains(prompt, "Describe Frob like a pirate. Signature: func Frob(n int)") {
+			used = true
+		}
+	}
+	if !used {
+		t.Errorf("custom function prompt not sent, got %q", model.Prompts())
+	}
+}
+
+func TestResolvePromptTemplates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "type.tmpl")
+	if err := os.WriteFile(path, []byte("Explain type {{.name}}."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	templates, err := resolvePromptTemplates(map[string]string{"type": path})
+	if err != nil || templates["type"] != "Explain type {{.name}}." {
+		t.Errorf("template from file: got %q, %v", templates, err)
+	}
+
+	for _, bad := range []map[string]string{
+		{"function": "Describe {{.name}}."},
+		{"method": "Describe {{.name}}."},
+		{"package": filepath.Join(t.TempDir(), "missing.tmpl")},
+	} {
+		if _, err := resolvePromptTemplates(bad); err == nil {
+			t.Errorf("resolvePromptTemplates(%q) succeeded", bad)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter spaces requests evenly to stay under a requests-per-minute
+// limit. It is shared by every worker, which block until their slot comes up.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+	now      func() time.Time
+}
+
+func newRateLimiter(requestsPerMinute int) *rateLimiter {
+	return &rateLimiter{
+		interval: time.Minute / time.Duration(requestsPerMinute),
+		now:      time.Now,
+	}
+}
+
+// reserve claims the next free slot and returns how long to wait for it.
+func (rl *rateLimiter) reserve() time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	if rl.next.Before(now) {
+		rl.next = now
+	}
+	delay := rl.next.Sub(now)
+	rl.next = rl.next.Add(rl.interval)
+
+	return delay
+}
+
+// wait blocks until the caller may send a request or ctx is done.
+func (rl *rateLimiter) wait(ctx context.Context) error {
+	delay := rl.reserve()
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// rateLimiter returns the limiter for the configured rate, or nil when
+// requests are unlimited.
+func (dg *DocGenerator) rateLimiter(requestsPerMinute int) *rateLimiter {
+	if requestsPerMinute <= 0 {
+		return nil
+	}
+
+	dg.limiterMu.Lock()
+	defer dg.limiterMu.Unlock()
+
+	if dg.limiter == nil || dg.limiter.interval != time.Minute/time.Duration(requestsPerMinute) {
+		dg.limiter = newRateLimiter(requestsPerMinute)
+	}
+
+	return dg.limiter
+}
@@ -0,0 +1,52 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterSpacing(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rl := newRateLimiter(60)
+	rl.now = func() time.Time { return clock }
+
+	// Requests at the same instant are spaced a second apart
+	for i, want := range []time.Duration{0, time.Second, 2 * time.Second} {
+		if got := rl.reserve(); got != want {
+			t.Errorf("request %d: got delay %v, want %v", i, got, want)
+		}
+	}
+
+	// Once the clock passes the reserved slots, requests go straight out
+	clock = clock.Add(10 * time.Second)
+	if got := rl.reserve(); got != 0 {
+		t.Errorf("after idling: got delay %v, want 0", got)
+	}
+	clock = clock.Add(500 * time.Millisecond)
+	if got := rl.reserve(); got != 500*time.Millisecond {
+		t.Errorf("half a second later: got delay %v, want 500ms", got)
+	}
+}
+
+func TestRateLimiterWaitCancelled(t *testing.T) {
+	rl := newRateLimiter(1)
+	rl.reserve()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := rl.wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
+
+func TestRateLimiterShared(t *testing.T) {
+	dg := newTestGenerator(t, nil)
+	if dg.rateLimiter(0) != nil {
+		t.Error("got a limiter for an unlimited rate")
+	}
+	if dg.rateLimiter(30) != dg.rateLimiter(30) {
+		t.Error("workers got different limiters for the same rate")
+	}
+}
@@ -0,0 +1,130 @@
+package generator
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// reflowProse wraps the paragraphs of doc, rendered as Markdown, at width
+// columns. Headings, lists, tables, HTML, quotes and code blocks are left as
+// they are, and code spans and links are never split. A width of 0 leaves doc
+// unchanged, as does any style other than Markdown.
+func reflowProse(doc string, style string, width int) string {
+	if width <= 0 || style == "html" || style == "asciidoc" {
+		return doc
+	}
+
+	var out []string
+	var para []string
+	flush := func() {
+		if len(para) > 0 {
+			out = append(out, wrapWords(proseWords(strings.Join(para, " ")), width)...)
+			para = para[:0]
+		}
+	}
+
+	inBlock := false
+	for _, line := range strings.Split(doc, "\n") {
+		if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "'''") {
+			flush()
+			inBlock = !inBlock
+			out = append(out, line)
+			continue
+		}
+		if inBlock || !isProse(line) {
+			flush()
+			out = append(out, line)
+			continue
+		}
+		para = append(para, strings.TrimSpace(line))
+	}
+	flush()
+
+	return strings.Join(out, "\n")
+}
+
+// isProse reports whether line is part of a plain paragraph.
+func isProse(line string) bool {
+	if strings.TrimSpace(line) == "" || line[0] == ' ' || line[0] == '\t' {
+		return false
+	}
+	switch line[0] {
+	case '#', '-', '*', '+', '|', '<', '>', '=':
+		return false
+	}
+
+	// Numbered list items
+	digits := strings.TrimLeftFunc(line, unicode.IsDigit)
+	return len(digits) == len(line) || !strings.HasPrefix(digits, ". ")
+}
+
+// proseWords splits text on whitespace, keeping each code span and link, e.g.
+// [the docs](https://example.com), as one word.
+func proseWords(text string) []string {
+	var words []string
+	var word strings.Builder
+	code, escaped := false, false
+	brackets, parens := 0, 0
+
+	for i, r := range text {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && !code:
+			escaped = true
+		case r == '`':
+			code = !code
+		case code:
+		case r == '[':
+			brackets++
+		case r == ']' && brackets > 0:
+			brackets--
+		case r == '(' && i > 0 && text[i-1] == ']':
+			parens++
+		case r == ')' && parens > 0:
+			parens--
+		}
+
+		if unicode.IsSpace(r) && !code && brackets == 0 && parens == 0 {
+			if word.Len() > 0 {
+				words = append(words, word.String())
+				word.Reset()
+			}
+			continue
+		}
+		word.WriteRune(r)
+	}
+	if word.Len() > 0 {
+		words = append(words, word.String())
+	}
+
+	return words
+}
+
+// wrapWords joins words into lines of at most width columns. A word longer
+// than width gets a line of its own, and a word that would read as a list
+// item or heading at the start of a line stays on the line before.
+func wrapWords(words []string, width int) []string {
+	var lines []string
+	var line strings.Builder
+	columns := 0
+	for _, word := range words {
+		n := utf8.RuneCountInString(word)
+		if columns > 0 && columns+1+n > width && isProse(word+" ") {
+			lines = append(lines, line.String())
+			line.Reset()
+			columns = 0
+		}
+		if columns > 0 {
+			line.WriteByte(' ')
+			columns++
+		}
+		line.WriteString(word)
+		columns += n
+	}
+	if line.Len() > 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
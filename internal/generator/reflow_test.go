@@ -0,0 +1,43 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestReflowProse(t *testing.T) {
+	code := "```go\nfmt.Println(\"this line is much longer than the configured width and must stay whole\")\n```"
+	doc := "# Title\n\n" +
+		"A long paragraph that runs well past the configured width, with `a code span that must not break` and a [link to the docs](https://example.com/docs) in it.\n\n" +
+		code + "\n\n" +
+		"- a list item that is also longer than the configured width stays on one line\n"
+
+	got := reflowProse(doc, "markdown", 40)
+	for _, line := range strings.Split(got, "\n") {
+		if strings.HasPrefix(line, "fmt.") || strings.HasPrefix(line, "- ") || strings.Contains(line, "[link") {
+			continue
+		}
+		if utf8.RuneCountInString(line) > 40 {
+			t.Errorf("line longer than 40 columns: %q", line)
+		}
+	}
+	for _, want := range []string{
+		"A long paragraph that runs well past the\nconfigured width, with\n",
+		"`a code span that must not break`",
+		"[link to the docs](https://example.com/docs)",
+		code,
+		"- a list item that is also longer than the configured width stays on one line",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("reflowed doc missing %q:\n%s", want, got)
+		}
+	}
+
+	if got := reflowProse(doc, "markdown", 0); got != doc {
+		t.Errorf("width 0 changed the doc:\n%s", got)
+	}
+	if got := reflowProse(doc, "html", 40); got != doc {
+		t.Errorf("html doc was reflowed:\n%s", got)
+	}
+}
@@ -0,0 +1,114 @@
+package generator
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// DefaultConfig returns the configuration used when no config file overrides it.
+func DefaultConfig() DocConfig {
+	return DocConfig{
+		OutputDir:                "./docs",
+		IncludePrivate:           false,
+		GenerateExamples:         true,
+		EnhancePackage:           true,
+		EnhanceFunctions:         true,
+		EnhanceTypes:             true,
+		GenerateFunctionExamples: true,
+		Style:                    "markdown",
+		ExampleValidation:        ValidateParse,
+		FieldStyle:               "list",
+		CollapseBlankLines:       true,
+		SymbolOrder:              OrderExportedFirst,
+		LLMConcurrency:           1,
+		Temperature:              0.2,
+		Language:                 "English",
+	}
+}
+
+// ConfigSchema returns a JSON Schema describing DocConfig, generated from the
+// struct's fields so it stays in sync as options are added. Defaults come from
+// DefaultConfig and allowed values from each field's enum tag.
+func ConfigSchema() ([]byte, error) {
+	schema := schemaFor(reflect.TypeOf(DocConfig{}), reflect.ValueOf(DefaultConfig()))
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "DocConfig"
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+func schemaFor(t reflect.Type, defaults reflect.Value) map[string]any {
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := make(map[string]any)
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, ok := jsonFieldName(field)
+			if !ok {
+				continue
+			}
+
+			var fieldDefault reflect.Value
+			if defaults.IsValid() {
+				fieldDefault = defaults.Field(i)
+			}
+
+			prop := schemaFor(field.Type, fieldDefault)
+			if fieldDefault.IsValid() && !fieldDefault.IsZero() {
+				prop["default"] = fieldDefault.Interface()
+			}
+			if enum := field.Tag.Get("enum"); enum != "" {
+				prop["enum"] = strings.Split(enum, ",")
+			}
+			properties[name] = prop
+		}
+
+		return map[string]any{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{
+			"type":  "array",
+			"items": schemaFor(t.Elem(), reflect.Value{}),
+		}
+	case reflect.Map:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": schemaFor(t.Elem(), reflect.Value{}),
+		}
+	case reflect.Pointer:
+		return schemaFor(t.Elem(), reflect.Value{})
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	default:
+		return map[string]any{}
+	}
+}
+
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+
+	return name, true
+}
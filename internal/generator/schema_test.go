@@ -0,0 +1,112 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// validate checks value against the subset of JSON Schema ConfigSchema
+// produces: types, enums, properties and additionalProperties.
+func validate(schema map[string]any, value any, path string) error {
+	if enum, ok := schema["enum"].([]any); ok && !slices.Contains(enum, value) {
+		return fmt.Errorf("%s: %v is not one of %v", path, value, enum)
+	}
+
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: want an object, got %T", path, value)
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		for key, v := range object {
+			prop, ok := properties[key].(map[string]any)
+			if !ok {
+				prop, ok = schema["additionalProperties"].(map[string]any)
+			}
+			if !ok {
+				return fmt.Errorf("%s: unknown property %s", path, key)
+			}
+			if err := validate(prop, v, path+"."+key); err != nil {
+				return err
+			}
+		}
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%s: want an array, got %T", path, value)
+		}
+		for i, v := range array {
+			if err := validate(schema["items"].(map[string]any), v, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%s: want a string, got %T", path, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: want a boolean, got %T", path, value)
+		}
+	case "integer":
+		if n, ok := value.(float64); !ok || n != float64(int64(n)) {
+			return fmt.Errorf("%s: want an integer, got %v", path, value)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("%s: want a number, got %T", path, value)
+		}
+	}
+	return nil
+}
+
+func TestConfigSchema(t *testing.T) {
+	data, err := ConfigSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("schema isn't valid JSON: %v", err)
+	}
+
+	style := schema["properties"].(map[string]any)["style"].(map[string]any)
+	if style["default"] != "markdown" || len(style["enum"].([]any)) != 4 {
+		t.Errorf("style property: got %v", style)
+	}
+
+	valid := `{
+		"project_name": "docura",
+		"style": "html",
+		"llm_retries": 2,
+		"temperature": 0.5,
+		"fallback_models": ["a", "b"],
+		"labels": {"Returns": "Renvoie"}
+	}`
+	invalid := map[string]string{
+		"unknown property": `{"colour": "blue"}`,
+		"bad enum":         `{"style": "pdf"}`,
+		"wrong type":       `{"llm_retries": "two"}`,
+		"wrong item type":  `{"fallback_models": [1]}`,
+	}
+
+	var config map[string]any
+	if err := json.Unmarshal([]byte(valid), &config); err != nil {
+		t.Fatal(err)
+	}
+	if err := validate(schema, config, "config"); err != nil {
+		t.Errorf("valid config rejected: %v", err)
+	}
+	for name, text := range invalid {
+		config = nil
+		if err := json.Unmarshal([]byte(text), &config); err != nil {
+			t.Fatal(err)
+		}
+		if err := validate(schema, config, "config"); err == nil {
+			t.Errorf("%s: config %s accepted", name, text)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package generator
+
+import (
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+// wrapSignature puts each parameter of sig on its own line, as gofmt lays out
+// a parameter list ending in a newline, when sig is longer than width. A
+// width of 0 leaves sig as it is.
+func wrapSignature(sig string, width int) string {
+	if width <= 0 || len(sig) <= width || strings.Contains(sig, "\n") {
+		return sig
+	}
+
+	const prefix = "package p\n\n"
+	src := prefix + sig
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, 0)
+	if err != nil || len(file.Decls) != 1 {
+		return sig
+	}
+	fn, ok := file.Decls[0].(*ast.FuncDecl)
+	if !ok || len(fn.Type.Params.List) == 0 {
+		return sig
+	}
+
+	offset := func(pos token.Pos) int {
+		return fset.Position(pos).Offset
+	}
+
+	var b strings.Builder
+	b.WriteString(src[:offset(fn.Type.Params.Opening)+1])
+	b.WriteString("\n")
+	for _, field := range fn.Type.Params.List {
+		b.WriteString("\t" + src[offset(field.Pos()):offset(field.End())] + ",\n")
+	}
+	b.WriteString(src[offset(fn.Type.Params.Closing):])
+
+	wrapped, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return sig
+	}
+	return strings.TrimSpace(strings.TrimPrefix(string(wrapped), prefix))
+}
+
+// FormatSignature returns fn's signature, wrapped when it is longer than the
+// configured width.
+func (p page) FormatSignature(fn analyser.FunctionInfo) string {
+	return wrapSignature(fn.Signature, p.Wrap)
+}
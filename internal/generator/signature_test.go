@@ -0,0 +1,44 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWrapSignature(t *testing.T) {
+	long := "func Dial(ctx context.Context, network, address string, opts ...Option) (*Conn, error)"
+	tests := []struct {
+		sig   string
+		width int
+		want  string
+	}{
+		{long, 0, long},
+		{long, 200, long},
+		{long, 40, "func Dial(\n\tctx context.Context,\n\tnetwork, address string,\n\topts ...Option,\n) (*Conn, error)"},
+		{"func (c *Conn) Close() error", 10, "func (c *Conn) Close() error"},
+		{"func Map[T, U any](s []T, f func(T) U) []U", 20, "func Map[T, U any](\n\ts []T,\n\tf func(T) U,\n) []U"},
+	}
+	for _, test := range tests {
+		if got := wrapSignature(test.sig, test.width); got != test.want {
+			t.Errorf("wrapSignature(%q, %d) = %q, want %q", test.sig, test.width, got, test.want)
+		}
+	}
+}
+
+func TestRenderWrappedSignature(t *testing.T) {
+	src := `package p
+
+// Dial connects to address.
+func Dial(network, address string, timeout int, retries int) error { return nil }
+`
+	config := offlineConfig("markdown")
+	if doc := render(t, src, config); !strings.Contains(doc, "func Dial(network, address string, timeout int, retries int) error\n") {
+		t.Errorf("signature wrapped by default:\n%s", doc)
+	}
+
+	config.SignatureWidth = 40
+	want := "func Dial(\n\tnetwork, address string,\n\ttimeout int,\n\tretries int,\n) error\n"
+	if doc := render(t, src, config); !strings.Contains(doc, want) {
+		t.Errorf("output missing wrapped signature %q:\n%s", want, doc)
+	}
+}
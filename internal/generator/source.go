@@ -0,0 +1,129 @@
+package generator
+
+import (
+	"fmt"
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"github.com/brendan-sadlier/docura/internal/logging"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// defaultSourceRef is the ref linked to when the repository's commit can't be
+// found.
+const defaultSourceRef = "main"
+
+// SourceLocation is the data available to DocConfig.SourceURLTemplate.
+type SourceLocation struct {
+	Ref  string // commit or branch, from SourceRef or the checked out commit
+	File string // file relative to the repository root, slash separated
+	Name string // file name within its package directory
+	Line int
+}
+
+// sourceLinks renders where each symbol is declared, for pages that show it.
+type sourceLinks struct {
+	show bool
+	url  *template.Template
+	ref  string
+	dir  string // package directory relative to the repository root, slash separated
+}
+
+func (dg *DocGenerator) sourceLinks(config DocConfig, pkg *analyser.PackageInfo) (sourceLinks, error) {
+	links := sourceLinks{show: config.ShowSource || config.SourceURLTemplate != ""}
+	if config.SourceURLTemplate == "" {
+		return links, nil
+	}
+
+	tmpl, err := template.New("source").Parse(config.SourceURLTemplate)
+	if err != nil {
+		return links, fmt.Errorf("parsing source URL template: %w", err)
+	}
+	links.url = tmpl
+
+	dir, err := filepath.Abs(pkg.Path)
+	if err != nil {
+		return links, fmt.Errorf("resolving package directory: %w", err)
+	}
+	root := repoRoot(dir)
+	if rel, err := filepath.Rel(root, dir); err == nil {
+		links.dir = filepath.ToSlash(rel)
+	}
+
+	links.ref = config.SourceRef
+	if links.ref == "" {
+		links.ref = dg.checkedOutRef(root)
+	}
+
+	return links, nil
+}
+
+// repoRoot returns the nearest directory above dir holding a .git, falling
+// back to the nearest holding a go.mod, and then dir itself.
+func repoRoot(dir string) string {
+	for _, marker := range []string{".git", "go.mod"} {
+		for root := dir; ; root = filepath.Dir(root) {
+			if _, err := os.Stat(filepath.Join(root, marker)); err == nil {
+				return root
+			}
+			if filepath.Dir(root) == root {
+				break
+			}
+		}
+	}
+	return dir
+}
+
+// checkedOutRef returns the commit checked out in the repository at root,
+// looking it up once per repository.
+func (dg *DocGenerator) checkedOutRef(root string) string {
+	dg.refsMu.Lock()
+	defer dg.refsMu.Unlock()
+
+	if ref, ok := dg.refs[root]; ok {
+		return ref
+	}
+
+	ref := defaultSourceRef
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = root
+	if out, err := cmd.Output(); err == nil {
+		ref = strings.TrimSpace(string(out))
+	} else {
+		logging.Debugf("Could not find the checked out commit in %s, linking to %s: %v", root, ref, err)
+	}
+
+	if dg.refs == nil {
+		dg.refs = make(map[string]string)
+	}
+	dg.refs[root] = ref
+	return ref
+}
+
+// ShowSource reports whether symbols are shown with where they are declared.
+func (s sourceLinks) ShowSource() bool {
+	return s.show
+}
+
+// SourceURL returns the link to line of file, or "" when no source URL
+// template is configured.
+func (s sourceLinks) SourceURL(file string, line int) (string, error) {
+	if s.url == nil {
+		return "", nil
+	}
+
+	var url strings.Builder
+	err := s.url.Execute(&url, SourceLocation{
+		Ref:  s.ref,
+		File: path.Join(s.dir, file),
+		Name: file,
+		Line: line,
+	})
+	if err != nil {
+		return "", fmt.Errorf("executing source URL template: %w", err)
+	}
+	return url.String(), nil
+}
@@ -0,0 +1,57 @@
+package generator
+
+import (
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSourceURLTemplate(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "internal", "shapes")
+	if err := os.MkdirAll(filepath.Join(root, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	src := "package shapes\n\n// Square is a square.\ntype Square struct{ Side float64 }\n"
+	if err := os.WriteFile(filepath.Join(dir, "square.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := analyser.NewAnalyser().AnalysePackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := offlineConfig("markdown")
+	config.SourceURLTemplate = "https://github.com/org/repo/blob/{{.Ref}}/{{.File}}#L{{.Line}}"
+	config.SourceRef = "v1.2.0"
+	doc, err := newTestGenerator(t, nil).GeneratePackageDoc(pkg, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Source: [square.go:4](https://github.com/org/repo/blob/v1.2.0/internal/shapes/square.go#L4)"
+	if !strings.Contains(doc, want) {
+		t.Errorf("output missing %q:\n%s", want, doc)
+	}
+}
+
+func TestRepoRoot(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "a", "b")
+	for _, d := range []string{filepath.Join(root, ".git"), filepath.Join(root, "a"), dir} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A go.mod nearer the package doesn't hide the repository root
+	if err := os.WriteFile(filepath.Join(root, "a", "go.mod"), []byte("module a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := repoRoot(dir); got != root {
+		t.Errorf("repoRoot(%s) = %s, want %s", dir, got, root)
+	}
+}
@@ -0,0 +1,558 @@
+package generator
+
+import (
+	"fmt"
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// page holds what every page template needs, whether it documents a whole
+// package or one of its types.
+type page struct {
+	pkg        *analyser.PackageInfo // the package whose examples are shown
+	Ext        string                // output file extension
+	FieldStyle string                // "list" or "table"
+	Redact     bool                  // hide the tags of unexported fields
+	Wrap       int                   // wrap signatures longer than this, 0 for never
+	Labels     map[string]string
+	Fences     map[string]string
+	anchors    map[string]string
+	sourceLinks
+}
+
+// newPage returns the shared page data for pkg under config.
+func newPage(pkg *analyser.PackageInfo, config DocConfig, links sourceLinks) page {
+	return page{
+		pkg:         pkg,
+		Ext:         config.ext(),
+		FieldStyle:  config.FieldStyle,
+		Redact:      config.RedactPrivateFields,
+		Wrap:        config.SignatureWidth,
+		Labels:      config.Labels,
+		Fences:      config.FenceLanguages,
+		anchors:     packageAnchors(pkg, config.Labels),
+		sourceLinks: links,
+	}
+}
+
+// packagePage is the data passed to the package templates.
+type packagePage struct {
+	*analyser.PackageInfo
+	page
+	Packages   []string // every package documented in the run, for cross links
+	SplitTypes bool     // types are rendered on their own pages
+	Testing    bool     // test helpers and examples get a section of their own
+	Style      string   // output style, for links in the right syntax
+	Inline     int      // inline returned structs with at most this many fields
+}
+
+// typePage is the data passed to the type template when types are split out.
+type typePage struct {
+	*analyser.TypeInfo
+	page
+	Package    *analyser.PackageInfo
+	MethodDocs []analyser.FunctionInfo
+}
+
+// Label returns the translation of a section heading such as "Parameters".
+func (p page) Label(key string) string {
+	return label(p.Labels, key)
+}
+
+// ExampleNumber labels the i'th of a function's examples, counting from 1.
+func (p page) ExampleNumber(i int) string {
+	return fmt.Sprintf("%s %d", label(p.Labels, "Example"), i+1)
+}
+
+// Fence returns the language tag for a kind of code block: "shell", "code"
+// or "output".
+func (p page) Fence(kind string) string {
+	return fence(p.Fences, kind)
+}
+
+// Redacted reports whether field is shown by name and type only, marked as
+// unexported.
+func (p page) Redacted(field analyser.FieldInfo) bool {
+	return p.Redact && !field.IsExported
+}
+
+// HasDefaults reports whether any of fields has a default, for a Default
+// column in field tables.
+func (p page) HasDefaults(fields []analyser.FieldInfo) bool {
+	return hasDefaults(fields)
+}
+
+func hasDefaults(fields []analyser.FieldInfo) bool {
+	for _, field := range fields {
+		if field.Default != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultFences are the code block languages used unless overridden.
+var defaultFences = map[string]string{
+	"shell":  "bash",
+	"code":   "go",
+	"output": "",
+}
+
+func fence(fences map[string]string, kind string) string {
+	if lang, ok := fences[kind]; ok {
+		return lang
+	}
+	return defaultFences[kind]
+}
+
+func label(labels map[string]string, key string) string {
+	if translated, ok := labels[key]; ok && translated != "" {
+		return translated
+	}
+	return key
+}
+
+// Anchor returns the id of the named type, function or Type.Method section.
+func (p page) Anchor(symbol string) string {
+	return anchor(p.anchors, symbol)
+}
+
+// FuncAnchor returns the id of fn's section, qualified by its receiver for
+// methods.
+func (p packagePage) FuncAnchor(fn analyser.FunctionInfo) string {
+	return anchor(p.anchors, functionSymbol(fn))
+}
+
+func anchor(anchors map[string]string, symbol string) string {
+	if id, ok := anchors[symbol]; ok {
+		return id
+	}
+	return slugify(symbol)
+}
+
+// sectionLabels are the headings of a package page, whose ids symbol anchors
+// must not reuse.
+var sectionLabels = []string{"Installation", "Usage", "Command", "Flags", "API Reference", "Routes", "Constants", "Variables", "Errors", "Functions", "Types", "Testing", "Imports"}
+
+// packageAnchors assigns each type, function and method in pkg an id unique
+// within its page. Types keep their plain slug, so they are assigned first,
+// and methods are qualified by their receiver, e.g. "file-close".
+func packageAnchors(pkg *analyser.PackageInfo, labels map[string]string) map[string]string {
+	used := make(map[string]bool)
+	for _, key := range sectionLabels {
+		used[slugify(label(labels, key))] = true
+	}
+	used[slugify(pkg.Name)+"-package"] = true
+
+	anchors := make(map[string]string)
+	assign := func(symbol string) {
+		base := slugify(symbol)
+		id := base
+		for n := 2; used[id]; n++ {
+			id = fmt.Sprintf("%s-%d", base, n)
+		}
+		used[id] = true
+		anchors[symbol] = id
+	}
+
+	for _, t := range pkg.Types {
+		assign(t.Name)
+	}
+	for _, fn := range pkg.Functions {
+		if !fn.IsMethod {
+			assign(fn.Name)
+		}
+	}
+	for _, fn := range pkg.Functions {
+		if fn.IsMethod {
+			assign(functionSymbol(fn))
+		}
+	}
+
+	return anchors
+}
+
+var (
+	nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+	identifier   = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+)
+
+// LinkTypes wraps references to exported types documented in this package in
+// links to their sections, or their own pages when types are split out.
+// Qualified names from other packages are left plain.
+func (p packagePage) LinkTypes(typ string) string {
+	local := p.localTypes()
+
+	// Outside AsciiDoc, brackets and stars in the type would otherwise be
+	// read as link or emphasis syntax
+	plain := escapeMarkdown
+	if p.Style == "asciidoc" {
+		plain = func(s string) string { return s }
+	}
+
+	var out strings.Builder
+	last := 0
+	for _, loc := range identifier.FindAllStringIndex(typ, -1) {
+		name := typ[loc[0]:loc[1]]
+		qualified := loc[0] > 0 && typ[loc[0]-1] == '.'
+		if !local[name] || qualified {
+			continue
+		}
+
+		out.WriteString(plain(typ[last:loc[0]]))
+		switch {
+		case p.Style == "asciidoc":
+			out.WriteString("<<" + p.Anchor(name) + "," + name + ">>")
+		case p.SplitTypes:
+			out.WriteString("[" + name + "](" + p.Name + "/" + name + p.Ext + ")")
+		default:
+			out.WriteString("[" + name + "](#" + p.Anchor(name) + ")")
+		}
+		last = loc[1]
+	}
+	out.WriteString(plain(typ[last:]))
+
+	return out.String()
+}
+
+// RefersToLocal reports whether typ mentions an exported type documented in
+// this package, such as Stack in Stack[int].
+func (p packagePage) RefersToLocal(typ string) bool {
+	local := p.localTypes()
+	for _, loc := range identifier.FindAllStringIndex(typ, -1) {
+		qualified := loc[0] > 0 && typ[loc[0]-1] == '.'
+		if local[typ[loc[0]:loc[1]]] && !qualified {
+			return true
+		}
+	}
+	return false
+}
+
+func (p packagePage) localTypes() map[string]bool {
+	local := make(map[string]bool)
+	for _, t := range p.Types {
+		if t.IsExported {
+			local[t.Name] = true
+		}
+	}
+	return local
+}
+
+// Errors returns the exported sentinel errors declared by the package.
+func (p packagePage) Errors() []analyser.VariableInfo {
+	var errs []analyser.VariableInfo
+	for _, v := range p.Variables {
+		if v.IsError && v.IsExported {
+			errs = append(errs, v)
+		}
+	}
+	return errs
+}
+
+// ExportedConstants returns the exported constants declared by the package.
+func (p packagePage) ExportedConstants() []analyser.ConstantInfo {
+	var consts []analyser.ConstantInfo
+	for _, c := range p.Constants {
+		if c.IsExported {
+			consts = append(consts, c)
+		}
+	}
+	return consts
+}
+
+// ExportedVariables returns the exported variables declared by the package,
+// other than the sentinel errors listed by Errors.
+func (p packagePage) ExportedVariables() []analyser.VariableInfo {
+	var vars []analyser.VariableInfo
+	for _, v := range p.Variables {
+		if v.IsExported && !v.IsError {
+			vars = append(vars, v)
+		}
+	}
+	return vars
+}
+
+// HasAPI reports whether the page documents anything from the package's API.
+func (p packagePage) HasAPI() bool {
+	return HasExportedAPI(p.PackageInfo)
+}
+
+// HasExportedAPI reports whether pkg has exported functions, types, constants
+// or variables, or routes, to document. Commands always do, as their flags are documented.
+func HasExportedAPI(pkg *analyser.PackageInfo) bool {
+	if pkg.IsCommand || len(pkg.Routes) > 0 {
+		return true
+	}
+	for _, fn := range pkg.Functions {
+		if fn.IsExported {
+			return true
+		}
+	}
+	for _, t := range pkg.Types {
+		if t.IsExported {
+			return true
+		}
+	}
+	for _, c := range pkg.Constants {
+		if c.IsExported {
+			return true
+		}
+	}
+	for _, v := range pkg.Variables {
+		if v.IsExported {
+			return true
+		}
+	}
+	return false
+}
+
+// InAPI reports whether fn is documented with the rest of the API rather than
+// in the Testing section.
+func (p packagePage) InAPI(fn analyser.FunctionInfo) bool {
+	return !p.Testing || !fn.IsTestHelper || fn.IsMethod
+}
+
+// TestHelpers returns the exported test helpers shown in the Testing section.
+func (p packagePage) TestHelpers() []analyser.FunctionInfo {
+	if !p.Testing {
+		return nil
+	}
+
+	var helpers []analyser.FunctionInfo
+	for _, fn := range p.Functions {
+		if fn.IsExported && !p.InAPI(fn) {
+			helpers = append(helpers, fn)
+		}
+	}
+	return helpers
+}
+
+// TestableExamples returns the package's Example functions for the Testing
+// section.
+func (p packagePage) TestableExamples() []analyser.ExampleInfo {
+	if !p.Testing {
+		return nil
+	}
+
+	var examples []analyser.ExampleInfo
+	for _, ex := range p.Examples {
+		if ex.Testable {
+			examples = append(examples, ex)
+		}
+	}
+	return examples
+}
+
+// PackageExamples returns the examples of the package as a whole.
+func (p packagePage) PackageExamples() []analyser.ExampleInfo {
+	return symbolExamples(p.PackageInfo, "")
+}
+
+// ExamplesFor returns the testable examples of the named function or type.
+func (p page) ExamplesFor(symbol string) []analyser.ExampleInfo {
+	return symbolExamples(p.pkg, symbol)
+}
+
+// FunctionExamples returns the testable examples of fn.
+func (p page) FunctionExamples(fn analyser.FunctionInfo) []analyser.ExampleInfo {
+	return symbolExamples(p.pkg, functionSymbol(fn))
+}
+
+func symbolExamples(pkg *analyser.PackageInfo, symbol string) []analyser.ExampleInfo {
+	var examples []analyser.ExampleInfo
+	for _, ex := range pkg.Examples {
+		if ex.Symbol == symbol {
+			examples = append(examples, ex)
+		}
+	}
+	return examples
+}
+
+func functionSymbol(fn analyser.FunctionInfo) string {
+	if fn.IsMethod {
+		return fn.Receiver + "." + fn.Name
+	}
+	return fn.Name
+}
+
+// markdownSpecial holds the characters escapeMarkdown backslash-escapes.
+const markdownSpecial = "\\*_[]<>"
+
+// escapeMarkdown escapes characters in s that Markdown would otherwise read as
+// emphasis, links or HTML. Inline code spans, fenced blocks and indented code
+// lines are left as they are, and a stray backtick is escaped so it can't
+// swallow the text after it.
+func escapeMarkdown(s string) string {
+	lines := strings.Split(s, "\n")
+	inFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "    ") {
+			continue
+		}
+		lines[i] = escapeMarkdownLine(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func escapeMarkdownLine(line string) string {
+	var out strings.Builder
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if c == '`' {
+			run := i
+			for run < len(line) && line[run] == '`' {
+				run++
+			}
+			delim := line[i:run]
+			if end := strings.Index(line[run:], delim); end >= 0 {
+				end += run + len(delim)
+				out.WriteString(line[i:end])
+				i = end - 1
+				continue
+			}
+			out.WriteString(strings.ReplaceAll(delim, "`", "\\`"))
+			i = run - 1
+			continue
+		}
+
+		if strings.IndexByte(markdownSpecial, c) >= 0 {
+			out.WriteByte('\\')
+		}
+		out.WriteByte(c)
+	}
+	return out.String()
+}
+
+// escapePipes makes s safe to use in a Markdown table cell.
+func escapePipes(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", `\|`), "\n", " ")
+}
+
+// typeParams renders a generic type's parameter list, e.g. "[K comparable, V any]".
+func typeParams(params []analyser.TypeParam) string {
+	if len(params) == 0 {
+		return ""
+	}
+
+	list := make([]string, len(params))
+	for i, p := range params {
+		list[i] = p.Name + " " + p.Constraint
+	}
+	return "[" + strings.Join(list, ", ") + "]"
+}
+
+func slugify(s string) string {
+	return strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+func (dg *DocGenerator) loadTemplates() error {
+	funcs := template.FuncMap{
+		"slugify":        slugify,
+		"escapePipes":    escapePipes,
+		"escapeMarkdown": escapeMarkdown,
+		"typeParams":     typeParams,
+	}
+
+	sources := []struct {
+		name string
+		text string
+	}{
+		{"package", packageTemplate},
+		{"type", typeTemplate},
+		{"internal", internalTemplate},
+		{"html", htmlTemplate},
+		{"asciidoc", asciidocTemplate},
+		{"index", indexTemplate},
+		{"html-index", htmlIndexTemplate},
+		{"asciidoc-index", asciidocIndexTemplate},
+	}
+
+	for _, src := range sources {
+		tmpl, err := template.New(src.name).Funcs(funcs).Parse(src.text)
+		if err != nil {
+			return fmt.Errorf("parsing %s template: %w", src.name, err)
+		}
+		dg.templates[src.name] = tmpl
+	}
+
+	return nil
+}
+
+// TypeDocPath returns the file, relative to the package's own doc file, that
+// typ is written to when types are split out.
+func TypeDocPath(pkg *analyser.PackageInfo, typ analyser.TypeInfo, config DocConfig) string {
+	return filepath.Join(pkg.Name, typ.Name+config.ext())
+}
+
+// GenerateTypeDoc renders a standalone page for typ and its methods. It should
+// be called after GeneratePackageDoc so descriptions are already enhanced.
+func (dg *DocGenerator) GenerateTypeDoc(pkg *analyser.PackageInfo, typ analyser.TypeInfo, config DocConfig) (string, error) {
+	links, err := dg.sourceLinks(config, pkg)
+	if err != nil {
+		return "", err
+	}
+
+	var methods []analyser.FunctionInfo
+	for _, fn := range pkg.Functions {
+		if fn.IsMethod && fn.Receiver == typ.Name && (fn.IsExported || config.IncludePrivate) {
+			methods = append(methods, fn)
+		}
+	}
+
+	var result strings.Builder
+	err = dg.templates["type"].Execute(&result, typePage{
+		TypeInfo:   &typ,
+		page:       newPage(pkg, config, links),
+		Package:    pkg,
+		MethodDocs: methods,
+	})
+	if err != nil {
+		return "", fmt.Errorf("executing type template: %w", err)
+	}
+
+	return dg.runHooks(reflowProse(shiftHeadings(result.String(), config.Style, config.HeadingOffset), config.Style, config.WrapWidth), pkg, config)
+}
+
+// InternalDocPath returns the file, relative to the package's own doc file,
+// that its unexported symbols are written to.
+func InternalDocPath(pkg *analyser.PackageInfo, config DocConfig) string {
+	return pkg.Name + ".internal" + config.ext()
+}
+
+// GenerateInternalDoc renders a page of the package's unexported functions,
+// methods and types, which must have been analysed with Unexported set.
+func (dg *DocGenerator) GenerateInternalDoc(pkg *analyser.PackageInfo, config DocConfig) (string, error) {
+	links, err := dg.sourceLinks(config, pkg)
+	if err != nil {
+		return "", err
+	}
+
+	internal := &analyser.PackageInfo{
+		Name:       pkg.Name,
+		Path:       pkg.Path,
+		ImportPath: pkg.ImportPath,
+		Functions:  pkg.UnexportedFunctions,
+		Types:      pkg.UnexportedTypes,
+	}
+	orderSymbols(internal, config.SymbolOrder)
+
+	var result strings.Builder
+	err = dg.templates["internal"].Execute(&result, packagePage{
+		PackageInfo: internal,
+		page:        newPage(internal, config, links),
+		Style:       config.Style,
+	})
+	if err != nil {
+		return "", fmt.Errorf("executing internal template: %w", err)
+	}
+
+	return dg.runHooks(reflowProse(shiftHeadings(result.String(), config.Style, config.HeadingOffset), config.Style, config.WrapWidth), pkg, config)
+}
@@ -0,0 +1,326 @@
+package generator
+
+import (
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestLinkLocalTypes(t *testing.T) {
+	doc := render(t, `package p
+
+import "io"
+
+// Options configures Open.
+type Options struct{}
+
+// Open opens a file with opts.
+func Open(opts Options, r io.Reader) error { return nil }
+`, offlineConfig("markdown"))
+
+	if !strings.Contains(doc, "'opts' ([Options](#options))") {
+		t.Errorf("parameter of a local type isn't linked to its section:\n%s", doc)
+	}
+	if !strings.Contains(doc, `<a id="options"></a>`) {
+		t.Errorf("type section has no anchor:\n%s", doc)
+	}
+	if strings.Contains(doc, "[io.Reader]") || strings.Contains(doc, "[Reader]") {
+		t.Errorf("non-local type linked:\n%s", doc)
+	}
+}
+
+func TestSinceBadge(t *testing.T) {
+	src := "package p\n\n// Open opens it.\n//\n// Since: v1.4.0\nfunc Open() {}\n\n// Close closes it.\nfunc Close() {}\n"
+	want := map[string]string{
+		"markdown": "`since v1.4.0`",
+		"html":     `<span class="badge">since v1.4.0</span>`,
+		"asciidoc": "since v1.4.0",
+	}
+	for style, badge := range want {
+		doc := render(t, src, offlineConfig(style))
+		if strings.Count(doc, badge) != 1 {
+			t.Errorf("%s: want one %s badge, for Open only:\n%s", style, badge, doc)
+		}
+	}
+}
+
+func TestErrorsSection(t *testing.T) {
+	doc := render(t, `package p
+
+import "errors"
+
+// ErrNotFound is returned for missing keys.
+var ErrNotFound = errors.New("not found")
+
+// Limit caps the number of entries.
+var Limit = 10
+`, offlineConfig("markdown"))
+
+	errorsAt := strings.Index(doc, "### Errors")
+	if errorsAt < 0 {
+		t.Fatalf("no Errors section:\n%s", doc)
+	}
+	section := doc[errorsAt:]
+	if next := strings.Index(section[1:], "###"); next >= 0 {
+		section = section[:next+1]
+	}
+	if !strings.Contains(section, "'ErrNotFound' - ErrNotFound is returned for missing keys.") || strings.Contains(section, "Limit") {
+		t.Errorf("Errors section should list the sentinel only:\n%s", section)
+	}
+}
+
+func TestConstraintsSection(t *testing.T) {
+	doc := render(t, `package p
+
+// Number is any integer or float.
+type Number interface {
+	~int | ~float64
+}
+
+// Map maps keys to values.
+type Map[K comparable, V any] struct{}
+`, offlineConfig("markdown"))
+
+	for _, want := range []string{"**Constraints:**", "'K' comparable", "'V' any", "~int | ~float64", "type Map[K comparable, V any] struct"} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("output missing %q:\n%s", want, doc)
+		}
+	}
+}
+
+func TestFieldTable(t *testing.T) {
+	src := "package p\n\n// User is a user.\ntype User struct {\n\t// Name is the user's name.\n\tName string `json:\"name\" validate:\"oneof=a|b\"`\n\tAge  int\n}\n"
+	config := offlineConfig("markdown")
+	config.FieldStyle = "table"
+	doc := render(t, src, config)
+
+	for _, want := range []string{
+		"| Name | Type | Tag | Description |\n|------|------|-----|-------------|\n",
+		"| 'Name' | string | `json:\"name\" validate:\"oneof=a\\|b\"` | Name is the user's name. |",
+		"| 'Age' | int |  |  |",
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("output missing %q:\n%s", want, doc)
+		}
+	}
+
+	if doc := render(t, src, offlineConfig("markdown")); strings.Contains(doc, "| Name |") || !strings.Contains(doc, "- 'Name' string") {
+		t.Errorf("default field style isn't a list:\n%s", doc)
+	}
+}
+
+func TestSummaryLine(t *testing.T) {
+	doc := render(t, "// Package store stores things. It keeps them on disk\n// until they're deleted.\npackage store\n", offlineConfig("markdown"))
+	if !strings.HasPrefix(doc, "# store\n\n_Package store stores things._\n") {
+		t.Errorf("summary not under the heading:\n%s", doc)
+	}
+
+	model := &fakeModel{respond: func(prompt string) (string, error) {
+		if strings.Contains(prompt, "single sentence") {
+			return "Stores things on disk. Deletes them later.", nil
+		}
+		return "generated", nil
+	}}
+	config := DefaultConfig()
+	config.GenerateExamples = false
+	doc, err := newTestGenerator(t, model).GeneratePackageDoc(analyseSource(t, "package store\n\nfunc Put() {}\n"), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(doc, "\n_Stores things on disk._\n") {
+		t.Errorf("generated summary missing or more than one sentence:\n%s", doc)
+	}
+}
+
+func TestExampleOutput(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"greet.go":      "package greet\n\n// Hello returns a greeting.\nfunc Hello(name string) string { return \"hello \" + name }\n",
+		"greet_test.go": "package greet_test\n\nimport (\n\t\"fmt\"\n\n\t\"greet\"\n)\n\nfunc ExampleHello() {\n\tfmt.Println(greet.Hello(\"gopher\"))\n\t// Output: hello gopher\n}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pkg, err := analyser.NewAnalyser().AnalysePackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := newTestGenerator(t, nil).GeneratePackageDoc(pkg, offlineConfig("markdown"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "'''go\nfmt.Println(greet.Hello(\"gopher\"))\n'''\n\nOutput:\n\n'''\nhello gopher\n'''"
+	if !strings.Contains(doc, want) {
+		t.Errorf("output missing example code and output blocks %q:\n%s", want, doc)
+	}
+}
+
+func TestEscapeMarkdown(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"uses snake_case names", `uses snake\_case names`},
+		{"a *bold* claim", `a \*bold\* claim`},
+		{"see [docs] <here>", `see \[docs\] \<here\>`},
+		{"keeps `a_b*c` code", "keeps `a_b*c` code"},
+		{"a stray ` tick", "a stray \\` tick"},
+		{"text_1\n```\ncode_2 *x*\n```\n\tindented_3", "text\\_1\n```\ncode_2 *x*\n```\n\tindented_3"},
+	}
+	for _, test := range tests {
+		if got := escapeMarkdown(test.in); got != test.want {
+			t.Errorf("escapeMarkdown(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestEscapedDescriptions(t *testing.T) {
+	src := `package p
+
+// Node is a generic tree node.
+type Node[T any] struct{}
+
+// Match reports whether the max_depth option matches a|b patterns.
+func Match(nodes []Node[string], m map[string]*Node[int]) bool { return false }
+`
+	doc := render(t, src, offlineConfig("markdown"))
+	for _, want := range []string{
+		`Match reports whether the max\_depth option matches a|b patterns.`,
+		`- 'nodes' (\[\][Node](#node)\[string\])`,
+		`- 'm' (map\[string\]\*[Node](#node)\[int\])`,
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("output missing %q:\n%s", want, doc)
+		}
+	}
+
+	config := offlineConfig("markdown")
+	config.FieldStyle = "table"
+	doc = render(t, "package p\n\n// T is a type.\ntype T struct {\n\t// Mode is read|write, see file_mode.\n\tMode string\n}\n", config)
+	if want := `| 'Mode' | string |  | Mode is read\|write, see file\_mode. |`; !strings.Contains(doc, want) {
+		t.Errorf("table row missing %q:\n%s", want, doc)
+	}
+}
+
+func TestDistinctAnchors(t *testing.T) {
+	src := `package p
+
+// Close closes everything.
+func Close() {}
+
+// File is a file.
+type File struct{}
+
+// Close closes the file.
+func (f *File) Close() error { return nil }
+
+// Conn is a connection.
+type Conn struct{}
+
+// Close closes the connection.
+func (c *Conn) Close() error { return nil }
+
+// Types is named like a section.
+type Types struct{}
+`
+	doc := render(t, src, offlineConfig("markdown"))
+
+	ids := regexp.MustCompile(`<a id="([^"]+)"></a>`).FindAllStringSubmatch(doc, -1)
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if seen[id[1]] {
+			t.Errorf("anchor %q used twice", id[1])
+		}
+		seen[id[1]] = true
+	}
+	for _, want := range []string{"close", "file-close", "conn-close", "types-2"} {
+		if !seen[want] {
+			t.Errorf("missing anchor %q, got %q", want, ids)
+		}
+	}
+	for _, want := range []string{"[Close](#file-close)", "[Close](#conn-close)"} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("method list missing link %s:\n%s", want, doc)
+		}
+	}
+}
+
+func TestEmbedsLine(t *testing.T) {
+	doc := render(t, `package p
+
+import "io"
+
+// Reader reads.
+type Reader interface{ Read(p []byte) (int, error) }
+
+// Writer writes.
+type Writer interface{ Write(p []byte) (int, error) }
+
+// ReadWriter reads and writes.
+type ReadWriter interface {
+	Reader
+	Writer
+	io.Closer
+}
+`, offlineConfig("markdown"))
+
+	if want := "**Embeds:** [Reader](#reader), [Writer](#writer), io.Closer"; !strings.Contains(doc, want) {
+		t.Errorf("output missing %q:\n%s", want, doc)
+	}
+}
+
+func TestFenceLanguages(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"greet.go":      "package greet\n\n// Greeter says hello.\ntype Greeter struct{}\n\n// Hello returns a greeting.\nfunc Hello(name string) string { return \"hello \" + name }\n",
+		"greet_test.go": "package greet_test\n\nimport (\n\t\"fmt\"\n\n\t\"greet\"\n)\n\nfunc ExampleHello() {\n\tfmt.Println(greet.Hello(\"gopher\"))\n\t// Output: hello gopher\n}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pkg, err := analyser.NewAnalyser().AnalysePackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg.ImportPath = "example.com/greet"
+	dg := newTestGenerator(t, nil)
+
+	doc, err := dg.GeneratePackageDoc(pkg, offlineConfig("markdown"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"'''bash\ngo get example.com/greet\n'''",
+		"'''go\nfmt.Println(greet.Hello(\"gopher\"))\n'''",
+		"'''go\nfunc Hello(name string) string\n'''",
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("output missing %q:\n%s", want, doc)
+		}
+	}
+
+	config := offlineConfig("markdown")
+	config.FenceLanguages = map[string]string{"shell": "sh", "code": "golang"}
+	doc, err = dg.GeneratePackageDoc(pkg, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"'''sh\ngo get", "'''golang\nfmt.Println", "'''golang\nfunc Hello"} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("output with overrides missing %q:\n%s", want, doc)
+		}
+	}
+
+	typeDoc, err := dg.GenerateTypeDoc(pkg, pkg.Types[0], config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(typeDoc, "'''golang\ntype Greeter struct") {
+		t.Errorf("type page ignores the code fence override:\n%s", typeDoc)
+	}
+}
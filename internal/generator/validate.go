@@ -0,0 +1,158 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"go/parser"
+	"go/token"
+	"maps"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Example validation levels for DocConfig.ExampleValidation
+const (
+	ValidateNone  = "none"
+	ValidateParse = "parse"
+	ValidateBuild = "build"
+)
+
+func (dg *DocGenerator) validateExample(ctx context.Context, code string, pkg *analyser.PackageInfo, level string) error {
+	if level == ValidateNone {
+		return nil
+	}
+
+	src, err := exampleSource(code)
+	if err != nil {
+		return fmt.Errorf("parsing example: %w", err)
+	}
+
+	if level != ValidateBuild {
+		return nil
+	}
+
+	// Examples call the documented package without importing it, as they
+	// are written to be read next to its docs
+	src, err = addImports(src, pkg)
+	if err != nil {
+		return fmt.Errorf("parsing example: %w", err)
+	}
+
+	return buildExample(ctx, src, pkg.Path)
+}
+
+// exampleSource turns generated example code into a complete Go file. Examples
+// are often bare statements or declarations without a package clause, so
+// those are wrapped before parsing.
+func exampleSource(code string) (string, error) {
+	candidates := []string{
+		code,
+		"package main\n\n" + code,
+		"package main\n\nfunc main() {\n" + code + "\n}\n",
+	}
+
+	var firstErr error
+	for _, src := range candidates {
+		_, err := parser.ParseFile(token.NewFileSet(), "example.go", src, parser.AllErrors)
+		if err == nil {
+			return src, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return "", firstErr
+}
+
+// commonImports are the standard library packages examples most often use
+// without importing them, by name.
+var commonImports = map[string]string{
+	"bufio":   "bufio",
+	"bytes":   "bytes",
+	"context": "context",
+	"errors":  "errors",
+	"fmt":     "fmt",
+	"http":    "net/http",
+	"io":      "io",
+	"log":     "log",
+	"os":      "os",
+	"sort":    "sort",
+	"strconv": "strconv",
+	"strings": "strings",
+	"sync":    "sync",
+	"time":    "time",
+}
+
+// addImports adds imports for the packages src refers to but doesn't import:
+// the documented package and common standard library packages.
+func addImports(src string, pkg *analyser.PackageInfo) (string, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "example.go", src, 0)
+	if err != nil {
+		return "", err
+	}
+	imported := make(map[string]bool)
+	for _, imp := range file.Imports {
+		importPath, _ := strconv.Unquote(imp.Path.Value)
+		name := path.Base(importPath)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		imported[name] = true
+	}
+
+	known := maps.Clone(commonImports)
+	if pkg.ImportPath != "" && !pkg.IsCommand {
+		known[pkg.Name] = pkg.ImportPath
+	}
+
+	var missing []string
+	for _, ident := range file.Unresolved {
+		importPath, ok := known[ident.Name]
+		if ok && !imported[ident.Name] && !slices.Contains(missing, importPath) {
+			missing = append(missing, importPath)
+		}
+	}
+	if len(missing) == 0 {
+		return src, nil
+	}
+
+	var decl strings.Builder
+	decl.WriteString("\n\nimport (\n")
+	for _, importPath := range missing {
+		decl.WriteString("\t" + strconv.Quote(importPath) + "\n")
+	}
+	decl.WriteString(")\n")
+
+	end := int(file.Name.End()) - 1
+	return src[:end] + decl.String() + src[end:], nil
+}
+
+// buildExample compiles src from within packageDir so imports resolve against
+// the documented module.
+func buildExample(ctx context.Context, src string, packageDir string) error {
+	tmpDir, err := os.MkdirTemp("", "docura-example-")
+	if err != nil {
+		return fmt.Errorf("creating temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	file := filepath.Join(tmpDir, "example.go")
+	if err := os.WriteFile(file, []byte(src), 0644); err != nil {
+		return fmt.Errorf("writing example: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "go", "build", "-o", os.DevNull, file)
+	cmd.Dir = packageDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("building example: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"context"
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateExampleParse(t *testing.T) {
+	dg := newTestGenerator(t, nil)
+	pkg := &analyser.PackageInfo{Name: "p"}
+
+	valid := []string{
+		"package main\n\nfunc main() {}\n",
+		"func helper() int { return 1 }",
+		"x := p.Frob(1)\n_ = x",
+	}
+	for _, code := range valid {
+		if err := dg.validateExample(context.Background(), code, pkg, ValidateParse); err != nil {
+			t.Errorf("validateExample(%q): %v", code, err)
+		}
+	}
+
+	invalid := []string{
+		"x := p.Frob(1",
+		"func {",
+	}
+	for _, code := range invalid {
+		if err := dg.validateExample(context.Background(), code, pkg, ValidateParse); err == nil {
+			t.Errorf("validateExample(%q) succeeded, want a parse error", code)
+		}
+		if err := dg.validateExample(context.Background(), code, pkg, ValidateNone); err != nil {
+			t.Errorf("validateExample(%q) with validation off: %v", code, err)
+		}
+	}
+}
+
+func TestValidateExampleBuild(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found")
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/p\n\ngo 1.22\n",
+		"p.go":   "package p\n\n// Frob doubles n.\nfunc Frob(n int) int { return n * 2 }\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pkg, err := analyser.NewAnalyser().AnalysePackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dg := newTestGenerator(t, nil)
+
+	valid := []string{
+		"fmt.Println(p.Frob(2))",
+		"package main\n\nimport \"example.com/p\"\n\nfunc main() { _ = p.Frob(1) }\n",
+	}
+	for _, code := range valid {
+		if err := dg.validateExample(context.Background(), code, pkg, ValidateBuild); err != nil {
+			t.Errorf("validateExample(%q): %v", code, err)
+		}
+	}
+
+	if err := dg.validateExample(context.Background(), "p.Missing()", pkg, ValidateBuild); err == nil {
+		t.Error("example calling an undefined function built")
+	}
+}
@@ -0,0 +1,76 @@
+// Package logging provides the leveled console output used by the CLI.
+// Errors and warnings go to stderr; info and debug messages go to stdout.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+type Level int
+
+const (
+	LevelError Level = iota
+	LevelWarn
+	LevelInfo
+	LevelDebug
+)
+
+var (
+	mu     sync.Mutex
+	level            = LevelInfo
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+)
+
+func SetLevel(l Level) {
+	mu.Lock()
+	defer mu.Unlock()
+	level = l
+}
+
+// SetOutput redirects info/debug messages to out and error/warn messages to errOut.
+func SetOutput(out, errOut io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	stdout, stderr = out, errOut
+}
+
+func Errorf(format string, args ...any) {
+	logf(LevelError, "error: ", format, args...)
+}
+
+func Warnf(format string, args ...any) {
+	logf(LevelWarn, "warning: ", format, args...)
+}
+
+func Infof(format string, args ...any) {
+	logf(LevelInfo, "", format, args...)
+}
+
+func Debugf(format string, args ...any) {
+	logf(LevelDebug, "debug: ", format, args...)
+}
+
+func logf(l Level, prefix string, format string, args ...any) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if l > level {
+		return
+	}
+
+	w := stdout
+	if l <= LevelWarn {
+		w = stderr
+	}
+
+	msg := fmt.Sprintf(format, args...)
+	if !strings.HasSuffix(msg, "\n") {
+		msg += "\n"
+	}
+	fmt.Fprint(w, prefix+msg)
+}
@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestLevels(t *testing.T) {
+	var out, errOut bytes.Buffer
+	SetOutput(&out, &errOut)
+	t.Cleanup(func() {
+		SetOutput(os.Stdout, os.Stderr)
+		SetLevel(LevelInfo)
+	})
+
+	SetLevel(LevelError)
+	Infof("Analyzing package: %s", "store")
+	Warnf("ignored")
+	Errorf("failed: %v", "boom")
+	if out.Len() > 0 {
+		t.Errorf("quiet output wrote info lines: %q", out.String())
+	}
+	if errOut.String() != "error: failed: boom\n" {
+		t.Errorf("got errors %q, want only the error line", errOut.String())
+	}
+
+	out.Reset()
+	errOut.Reset()
+	SetLevel(LevelInfo)
+	Infof("Generated documentation: %s", "store.md")
+	Debugf("hidden")
+	Warnf("edited")
+	if out.String() != "Generated documentation: store.md\n" {
+		t.Errorf("got %q on stdout", out.String())
+	}
+	if errOut.String() != "warning: edited\n" {
+		t.Errorf("got %q on stderr", errOut.String())
+	}
+
+	out.Reset()
+	SetLevel(LevelDebug)
+	Debugf("details")
+	if out.String() != "debug: details\n" {
+		t.Errorf("got %q on stdout at debug level", out.String())
+	}
+}
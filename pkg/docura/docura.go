@@ -0,0 +1,156 @@
+// Package docura exposes docura's analysis and documentation generation as a
+// library, so it can be embedded in other tools without going through the CLI.
+package docura
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/brendan-sadlier/docura/internal/analyser"
+	"github.com/brendan-sadlier/docura/internal/generator"
+	"path/filepath"
+	"slices"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Config controls how documentation is rendered. It is the same structure the
+// CLI loads from its JSON config file.
+type Config = generator.DocConfig
+
+// DefaultConfig returns the configuration the CLI uses when no config file is given.
+func DefaultConfig() Config {
+	return generator.DefaultConfig()
+}
+
+// PostRenderHook transforms each package's rendered documentation.
+type PostRenderHook = generator.PostRenderHook
+
+// CollapseBlankLines is the PostRenderHook, run before any in Options.Hooks
+// when Config.CollapseBlankLines is set, that squeezes runs of blank lines
+// outside code blocks into one.
+var CollapseBlankLines PostRenderHook = generator.CollapseBlankLines
+
+// Errors returned by Generate, for use with errors.Is.
+var (
+	// ErrNoPackage is returned when Options.Package has no Go files.
+	ErrNoPackage = analyser.ErrNoPackage
+	// ErrParse is returned when none of a package's files could be parsed.
+	ErrParse = analyser.ErrParse
+	// ErrTypeCheck is returned when Config.TypeCheck is set and a package
+	// doesn't type check.
+	ErrTypeCheck = analyser.ErrTypeCheck
+	// ErrLLM wraps a failed LLM request.
+	ErrLLM = generator.ErrLLM
+	// ErrMissingAPIKey is returned when no Model is given and GROQ_API_KEY
+	// isn't set.
+	ErrMissingAPIKey = generator.ErrMissingAPIKey
+)
+
+// Options describes what to document.
+type Options struct {
+	// Dir is the project directory to analyse.
+	Dir string
+	// Package restricts generation to a single package directory relative to
+	// Dir. When empty, every package under Dir is documented.
+	Package string
+	// Config controls rendering. OutputDir is ignored as nothing is written.
+	Config Config
+	// Model overrides the LLM used to enhance descriptions and generate
+	// examples. When nil, the default Groq-hosted model is used.
+	Model llms.Model
+	// Hooks run, in order, on each package's documentation after rendering.
+	Hooks []PostRenderHook
+}
+
+// Generate analyses the packages described by opts and renders their
+// documentation. The result maps each package's directory, relative to
+// opts.Dir, to its rendered content.
+func Generate(ctx context.Context, opts Options) (map[string]string, error) {
+	docGenerator, err := newDocGenerator(opts.Model, opts.Config.Offline)
+	if err != nil {
+		return nil, fmt.Errorf("creating document generator: %w", err)
+	}
+	for _, hook := range opts.Hooks {
+		docGenerator.AddPostRenderHook(hook)
+	}
+
+	ignore, err := analyser.LoadIgnoreRules(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := []string{filepath.Join(opts.Dir, opts.Package)}
+	if opts.Package == "" {
+		dirs, err = analyser.FindPackageDirs(opts.Dir, -1, ignore)
+		if err != nil {
+			return nil, fmt.Errorf("finding packages: %w", err)
+		}
+	}
+	if opts.Config.ExamplesDir != "" {
+		examplesRoot := filepath.Join(opts.Dir, opts.Config.ExamplesDir)
+		dirs = slices.DeleteFunc(dirs, func(dir string) bool {
+			rel, err := filepath.Rel(examplesRoot, dir)
+			return err == nil && filepath.IsLocal(rel)
+		})
+	}
+
+	config := opts.Config
+	analyserInstance := analyser.NewAnalyser()
+	analyserInstance.MarkdownExamples = config.MarkdownExamples
+	analyserInstance.MergeReadme = config.MergeReadme
+	analyserInstance.HTTPRoutes = config.HTTPRoutes
+	analyserInstance.Implements = config.Implements
+	analyserInstance.TypeCheck = config.TypeCheck
+	analyserInstance.PrivateFields = config.IncludePrivate
+	analyserInstance.Ignore = ignore
+
+	var pkgs []*analyser.PackageInfo
+	for _, dir := range dirs {
+		pkg, err := analyserInstance.AnalysePackage(dir)
+		if opts.Package == "" && errors.Is(err, analyser.ErrTestOnlyPackage) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("analyzing package %s: %w", dir, err)
+		}
+		pkgs = append(pkgs, pkg)
+	}
+
+	if config.ExamplesDir != "" {
+		examples, err := analyserInstance.ProgramExamples(filepath.Join(opts.Dir, config.ExamplesDir))
+		if err != nil {
+			return nil, err
+		}
+		analyser.AttachProgramExamples(pkgs, examples)
+	}
+	if config.SkipEmpty {
+		pkgs = slices.DeleteFunc(pkgs, func(pkg *analyser.PackageInfo) bool { return !generator.HasExportedAPI(pkg) })
+	}
+	for _, pkg := range pkgs {
+		config.Packages = append(config.Packages, pkg.Name)
+	}
+
+	docs := make(map[string]string, len(pkgs))
+	for _, pkg := range pkgs {
+		doc, err := docGenerator.GeneratePackageDocContext(ctx, pkg, config)
+		if err != nil {
+			return nil, fmt.Errorf("generating documentation for %s: %w", pkg.Path, err)
+		}
+
+		rel, err := filepath.Rel(opts.Dir, pkg.Path)
+		if err != nil {
+			rel = pkg.Path
+		}
+		docs[filepath.ToSlash(rel)] = doc
+	}
+
+	return docs, nil
+}
+
+func newDocGenerator(model llms.Model, offline bool) (*generator.DocGenerator, error) {
+	if model != nil || offline {
+		return generator.NewDocGeneratorWithModel(model)
+	}
+	return generator.NewDocGenerator()
+}
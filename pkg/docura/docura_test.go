@@ -0,0 +1,92 @@
+package docura_test
+
+import (
+	"context"
+	"errors"
+	"github.com/brendan-sadlier/docura/pkg/docura"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeProject writes files, keyed by slash-separated path, to a temporary
+// directory and returns it.
+func writeProject(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestGenerate(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"go.mod":         "module example.com/project\n\ngo 1.22\n",
+		"store/store.go": "// Package store stores things.\npackage store\n\n// Put stores v.\nfunc Put(v string) {}\n",
+		"cli/cli.go":     "// Package cli parses arguments.\npackage cli\n\n// Parse parses args.\nfunc Parse(args []string) error { return nil }\n",
+	})
+
+	config := docura.DefaultConfig()
+	config.Offline = true
+	docs, err := docura.Generate(context.Background(), docura.Options{Dir: dir, Config: config})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	if len(docs) != 2 {
+		t.Fatalf("got docs for %d packages, want 2: %v", len(docs), docs)
+	}
+	if !strings.Contains(docs["store"], "Put") || !strings.Contains(docs["store"], "example.com/project/store") {
+		t.Errorf("store docs missing Put or its import path:\n%s", docs["store"])
+	}
+	if !strings.Contains(docs["cli"], "Parse") {
+		t.Errorf("cli docs missing Parse:\n%s", docs["cli"])
+	}
+}
+
+func TestGeneratePackage(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"a/a.go": "package a\n\n// A does a.\nfunc A() {}\n",
+		"b/b.go": "package b\n\n// B does b.\nfunc B() {}\n",
+	})
+
+	config := docura.DefaultConfig()
+	config.Offline = true
+	docs, err := docura.Generate(context.Background(), docura.Options{Dir: dir, Package: "b", Config: config})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if _, ok := docs["b"]; !ok || len(docs) != 1 {
+		t.Errorf("got docs for %v, want only b", docs)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"broken/broken.go": "package broken\n\nfunc {\n",
+	})
+
+	config := docura.DefaultConfig()
+	config.Offline = true
+	tests := []struct {
+		pkg  string
+		want error
+	}{
+		{"missing", docura.ErrNoPackage},
+		{"broken", docura.ErrParse},
+	}
+	for _, test := range tests {
+		_, err := docura.Generate(context.Background(), docura.Options{Dir: dir, Package: test.pkg, Config: config})
+		if !errors.Is(err, test.want) {
+			t.Errorf("%s: got %v, want %v", test.pkg, err, test.want)
+		}
+	}
+}